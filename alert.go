@@ -0,0 +1,190 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AlertConfig configures an AlertHook.
+type AlertConfig struct {
+	// URL of a Slack, Discord or Microsoft Teams incoming webhook.
+	URL string
+	// MinLevel is the least severe level posted, e.g. LevelError.
+	MinLevel Level
+	// Fields lists fields included in the notification, others are left out
+	// to keep it compact.
+	Fields []string
+	// Interval is the minimum time between two notifications, defaults to a
+	// minute. Entries in between are counted and reported with the next one.
+	Interval time.Duration
+	Backoff  Backoff
+	Client   *http.Client
+}
+
+// AlertHook posts a short notification to a chat webhook for severe
+// entries. It is rate limited, so an incident logging thousands of errors
+// does not flood the channel. Use it with WithHook or WithAlertWebhook, it
+// is stopped when the logger is closed.
+type AlertHook struct {
+	cfg    AlertConfig
+	source string
+	key    string
+	now    func() time.Time
+
+	// mu guards the rate limit and closed, notifications fired after Close
+	// are dropped
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+	closed     bool
+
+	posts   chan []byte
+	dropped uint64
+	done    chan struct{}
+}
+
+// NewAlertHook creates the hook and starts its background sender.
+func NewAlertHook(cfg AlertConfig) *AlertHook {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.Backoff == (Backoff{}) {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	h := &AlertHook{
+		cfg:    cfg,
+		source: filepath.Base(os.Args[0]),
+		key:    alertKey(cfg.URL),
+		now:    clock,
+		posts:  make(chan []byte, 16),
+		done:   make(chan struct{}),
+	}
+	if host, err := hostname(); err == nil {
+		h.source += "@" + host
+	}
+	go h.run()
+
+	return h
+}
+
+// WithAlertWebhook posts entries at min or more severe levels to a Slack,
+// Discord or Teams webhook, with the listed fields.
+func WithAlertWebhook(url string, min Level, fields ...string) LogOption {
+	return WithHook(NewAlertHook(AlertConfig{URL: url, MinLevel: min, Fields: fields}))
+}
+
+// alertKey returns the payload key holding the text, Discord uses content
+// while Slack and Teams use text.
+func alertKey(webhook string) string {
+	if u, err := url.Parse(webhook); err == nil {
+		host := strings.ToLower(u.Hostname())
+		if host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com") {
+			return "content"
+		}
+	}
+
+	return "text"
+}
+
+// Fire posts the entry unless another notification was posted within the
+// interval.
+func (h *AlertHook) Fire(e Entry) error {
+	if e.Level.Severity() > h.cfg.MinLevel {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := h.now()
+	if !h.last.IsZero() && now.Sub(h.last) < h.cfg.Interval {
+		h.suppressed++
+		h.mu.Unlock()
+		return nil
+	}
+	suppressed := h.suppressed
+	h.last, h.suppressed = now, 0
+	h.mu.Unlock()
+
+	body, err := json.Marshal(map[string]string{h.key: h.text(e, suppressed)})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		atomic.AddUint64(&h.dropped, 1)
+		return nil
+	}
+	select {
+	case h.posts <- body:
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+
+	return nil
+}
+
+// text renders e.g. "api@host1 ERROR: payment failed (order=42)".
+func (h *AlertHook) text(e Entry, suppressed int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %s", h.source, strings.ToUpper(e.Level.String()), e.Message)
+
+	var fields []string
+	for _, k := range h.cfg.Fields {
+		if v, ok := e.Fields[k]; ok {
+			fields = append(fields, k+"="+formatValue(v))
+		}
+	}
+	if len(fields) > 0 {
+		b.WriteString(" (" + strings.Join(fields, " ") + ")")
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(&b, "\n%d more alerts were suppressed since the previous one", suppressed)
+	}
+
+	return b.String()
+}
+
+// Dropped returns the number of notifications dropped because the sender
+// was behind or the hook was closed.
+func (h *AlertHook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Close posts the queued notifications and stops the sender.
+func (h *AlertHook) Close() error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.posts)
+	}
+	h.mu.Unlock()
+	<-h.done
+
+	return nil
+}
+
+func (h *AlertHook) run() {
+	defer close(h.done)
+
+	for body := range h.posts {
+		err := h.cfg.Backoff.retry(func() error {
+			return post(h.cfg.Client, h.cfg.URL, "application/json", nil, body)
+		})
+		if err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to post log alert: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertHook(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		texts = append(texts, payload["text"])
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewAlertHook(AlertConfig{URL: srv.URL, MinLevel: LevelError, Fields: []string{"order"}})
+	h.now = func() time.Time { return now }
+
+	l := New(&bytes.Buffer{}, WithHook(h))
+	l.With(LogFields{"order": 42, "user": "bob"}).Error("payment failed")
+	l.Warning("not severe")
+	l.Error("storm")
+	l.Error("storm")
+	now = now.Add(time.Minute)
+	l.Error("recovered")
+	l.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, texts, 2) {
+		assert.True(t, strings.HasSuffix(texts[0], " ERROR: payment failed (order=42)"), texts[0])
+		assert.Contains(t, texts[1], " ERROR: recovered\n2 more alerts were suppressed")
+	}
+}
+
+func TestAlertHookAfterClose(t *testing.T) {
+	h := NewAlertHook(AlertConfig{URL: "http://127.0.0.1:1", MinLevel: LevelError})
+	assert.NoError(t, h.Close())
+
+	assert.NotPanics(t, func() {
+		assert.NoError(t, h.Fire(Entry{Level: LevelError, Message: "after close"}))
+	})
+	assert.Equal(t, uint64(1), h.Dropped())
+}
+
+func TestAlertKey(t *testing.T) {
+	assert.Equal(t, "content", alertKey("https://discord.com/api/webhooks/1/x"))
+	assert.Equal(t, "text", alertKey("https://hooks.slack.com/services/T/B/x"))
+	assert.Equal(t, "text", alertKey("https://example.webhook.office.com/webhookb2/x"))
+}
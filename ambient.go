@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ambient holds the fields pushed with PushFields by goroutine ID.
+var ambient struct {
+	mu     sync.Mutex
+	fields map[uint64][]LogFields
+	// active counts the goroutines with fields, entries skip the lookup
+	// of the goroutine ID while it is 0.
+	active int32
+}
+
+// PushFields adds fields to the entries logged by the current goroutine,
+// through any logger, until pop is called. It lets deep library code
+// logging with the package level functions carry the fields of the
+// surrounding operation:
+//
+//	pop := log.PushFields(log.LogFields{"job_id": id})
+//	defer pop()
+//	runJob() // entries logged inside carry job_id
+//
+// Pushes nest, later ones winning, and fields added with With win over
+// them. Goroutines started inside do not inherit the fields, pass a
+// context with ContextWithFields to them instead. pop is idempotent.
+func PushFields(fields LogFields) (pop func()) {
+	id := goroutineID()
+
+	ambient.mu.Lock()
+	if ambient.fields == nil {
+		ambient.fields = map[uint64][]LogFields{}
+	}
+	stack := ambient.fields[id]
+	if len(stack) == 0 {
+		atomic.AddInt32(&ambient.active, 1)
+	}
+	depth := len(stack)
+	ambient.fields[id] = append(stack, fields.clone())
+	ambient.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ambient.mu.Lock()
+			defer ambient.mu.Unlock()
+
+			stack := ambient.fields[id]
+			if depth >= len(stack) {
+				return
+			}
+			if depth == 0 {
+				delete(ambient.fields, id)
+				atomic.AddInt32(&ambient.active, -1)
+				return
+			}
+			ambient.fields[id] = stack[:depth]
+		})
+	}
+}
+
+// ambientFields returns the fields pushed by the current goroutine merged
+// into a new map, nil when there are none.
+func ambientFields() LogFields {
+	if atomic.LoadInt32(&ambient.active) == 0 {
+		return nil
+	}
+	id := goroutineID()
+
+	ambient.mu.Lock()
+	defer ambient.mu.Unlock()
+
+	stack := ambient.fields[id]
+	if len(stack) == 0 {
+		return nil
+	}
+	merged := LogFields{}
+	for _, f := range stack {
+		merged = merged.Add(f)
+	}
+
+	return merged.clone()
+}
+
+// goroutineID parses the ID of the current goroutine from its stack
+// header, "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+
+	return id
+}
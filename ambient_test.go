@@ -0,0 +1,38 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushFields(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	pop := PushFields(LogFields{"job": "sync", "step": 1})
+	inner := PushFields(LogFields{"step": 2})
+	l.Info("nested")
+	l.With(LogFields{"step": 3}).Info("explicit")
+	inner()
+	inner()
+	l.Info("outer")
+
+	done := make(chan struct{})
+	go func() {
+		l.Info("other goroutine")
+		close(done)
+	}()
+	<-done
+
+	pop()
+	l.Info("popped")
+
+	assert.Equal(t, "INFO : job=sync step=2 nested\n"+
+		"INFO : job=sync step=3 explicit\n"+
+		"INFO : job=sync step=1 outer\n"+
+		"INFO : other goroutine\n"+
+		"INFO : popped\n", out.String())
+	assert.Zero(t, ambient.active)
+}
@@ -0,0 +1,238 @@
+package log
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const archivedSuffix = ".archived"
+
+// ArchiveStore is an object store receiving log segments, e.g. a thin
+// wrapper of an S3, GCS or Azure Blob client.
+type ArchiveStore interface {
+	Put(ctx context.Context, key string, body io.Reader) error
+}
+
+// ArchiveConfig configures an ArchivingFile.
+type ArchiveConfig struct {
+	// Path of the local file receiving the entries.
+	Path string
+	// SegmentSizeMB starts a new segment before the file grows beyond this
+	// size, defaults to 16.
+	SegmentSizeMB int
+	// Daily starts a new segment on the first write after midnight.
+	Daily bool
+	// Store receives the segments under Prefix followed by the segment file
+	// name, e.g. "edge-7/app-2021-05-01T10-00-00.000.log.gz".
+	Store  ArchiveStore
+	Prefix string
+	// Compress gzips segments while uploading them.
+	Compress bool
+	// KeepUploaded is how long uploaded segments stay on disk after their
+	// rotation, they are removed right after the upload when zero.
+	KeepUploaded time.Duration
+	// MaxPendingBytes bounds the disk used by segments waiting for the
+	// upload while the store is unreachable, the oldest are removed first.
+	// Zero keeps all.
+	MaxPendingBytes int64
+	// Interval between upload attempts, defaults to 30 seconds.
+	Interval time.Duration
+	// UploadTimeout limits a single upload, defaults to one minute.
+	UploadTimeout time.Duration
+}
+
+// ArchivingFile is an io.WriteCloser writing entries to a local file, which
+// is split into segments like a RotatingFile. Finished segments are
+// uploaded to the store in the background, oldest first, and retried until
+// the store is reachable, for nodes with intermittent connectivity.
+type ArchivingFile struct {
+	*RotatingFile
+	cfg ArchiveConfig
+
+	failing bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewArchivingFile opens the local file and starts archiving segments.
+func NewArchivingFile(cfg ArchiveConfig) (*ArchivingFile, error) {
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("log: archive of %s has no store", cfg.Path)
+	}
+	if cfg.SegmentSizeMB <= 0 {
+		cfg.SegmentSizeMB = 16
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.UploadTimeout <= 0 {
+		cfg.UploadTimeout = time.Minute
+	}
+
+	rf, err := NewRotatingFile(cfg.Path, RotationConfig{MaxSizeMB: cfg.SegmentSizeMB, Daily: cfg.Daily})
+	if err != nil {
+		return nil, err
+	}
+
+	a := &ArchivingFile{RotatingFile: rf, cfg: cfg, done: make(chan struct{})}
+	a.wg.Add(1)
+	go a.run()
+
+	return a, nil
+}
+
+func (a *ArchivingFile) run() {
+	defer a.wg.Done()
+
+	t := time.NewTicker(a.cfg.Interval)
+	defer t.Stop()
+	for {
+		a.archive()
+		select {
+		case <-t.C:
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// archive uploads the pending segments and applies the retention.
+func (a *ArchivingFile) archive() {
+	a.millMu.Lock()
+	defer a.millMu.Unlock()
+
+	pending, err := a.backups()
+	if err != nil {
+		fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to list log segments of %s: %v\n", a.path, err)
+		return
+	}
+	pending = a.dropPending(pending)
+
+	// oldest first, stop at the first failure as the store is likely down
+	for i := len(pending) - 1; i >= 0; i-- {
+		if err := a.upload(pending[i].path); err != nil {
+			if !a.failing {
+				fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to archive log segment %s: %v\n", pending[i].path, err)
+			}
+			a.failing = true
+			break
+		}
+		if a.failing {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Archiving log segments of %s works again\n", a.path)
+		}
+		a.failing = false
+	}
+
+	a.removeUploaded()
+}
+
+// dropPending removes the oldest segments beyond MaxPendingBytes and
+// returns the remaining ones, newest first.
+func (a *ArchivingFile) dropPending(pending []backup) []backup {
+	if a.cfg.MaxPendingBytes <= 0 {
+		return pending
+	}
+
+	var total int64
+	for i, b := range pending {
+		fi, err := os.Stat(b.path)
+		if err != nil {
+			continue
+		}
+		total += fi.Size()
+		if total > a.cfg.MaxPendingBytes {
+			for _, old := range pending[i:] {
+				if err := os.Remove(old.path); err != nil {
+					fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to remove log segment %s: %v\n", old.path, err)
+				}
+			}
+			return pending[:i]
+		}
+	}
+
+	return pending
+}
+
+func (a *ArchivingFile) upload(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	key := a.cfg.Prefix + filepath.Base(path)
+	var body io.Reader = f
+	if a.cfg.Compress {
+		key += compressSuffix
+		pr, pw := io.Pipe()
+		go func() {
+			gz := gzip.NewWriter(pw)
+			_, err := io.Copy(gz, f)
+			if err == nil {
+				err = gz.Close()
+			}
+			pw.CloseWithError(err)
+		}()
+		defer pr.Close()
+		body = pr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.UploadTimeout)
+	defer cancel()
+	if err := a.cfg.Store.Put(ctx, key, body); err != nil {
+		return err
+	}
+
+	if a.cfg.KeepUploaded <= 0 {
+		return os.Remove(path)
+	}
+
+	return os.Rename(path, path+archivedSuffix)
+}
+
+// removeUploaded removes uploaded segments rotated more than KeepUploaded
+// ago.
+func (a *ArchivingFile) removeUploaded() {
+	dir := filepath.Dir(a.path)
+	ext := filepath.Ext(a.path)
+	prefix := filepath.Base(strings.TrimSuffix(a.path, ext)) + "-"
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := a.now().Add(-a.cfg.KeepUploaded)
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, archivedSuffix) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), archivedSuffix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil || !t.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to remove log segment %s: %v\n", name, err)
+		}
+	}
+}
+
+// Close stops archiving after a last attempt to upload the finished
+// segments and closes the local file.
+func (a *ArchivingFile) Close() error {
+	close(a.done)
+	a.wg.Wait()
+	a.archive()
+
+	return a.RotatingFile.Close()
+}
@@ -0,0 +1,126 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	down    bool
+}
+
+func (s *memStore) Put(ctx context.Context, key string, body io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("network is unreachable")
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = b
+	return nil
+}
+
+// object returns the stored keys and the body of key.
+func (s *memStore) object(key string) ([]string, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	return keys, s.objects[key]
+}
+
+// testClock is a clock the test moves while the archiver reads it.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestArchivingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	store := &memStore{objects: map[string][]byte{}, down: true}
+
+	a, err := NewArchivingFile(ArchiveConfig{
+		Path:            path,
+		Store:           store,
+		Prefix:          "edge-7/",
+		Compress:        true,
+		KeepUploaded:    time.Hour,
+		MaxPendingBytes: 10,
+		Interval:        time.Hour,
+	})
+	assert.NoError(t, err)
+	clk := &testClock{now: time.Date(2021, 5, 1, 10, 0, 0, 0, time.Local)}
+	// the archiver reads the clock holding millMu
+	a.millMu.Lock()
+	a.now = clk.Now
+	a.millMu.Unlock()
+
+	// offline: only the newest segments within MaxPendingBytes are kept
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err = a.Write([]byte(line))
+		assert.NoError(t, err)
+		assert.NoError(t, a.Rotate())
+		clk.Add(time.Minute)
+	}
+	a.archive()
+	pending, err := a.backups()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	keys, _ := store.object("")
+	assert.Empty(t, keys)
+
+	store.mu.Lock()
+	store.down = false
+	store.mu.Unlock()
+	a.archive()
+
+	key := "edge-7/app-2021-05-01T10-02-00.000.log.gz"
+	keys, body := store.object(key)
+	if assert.Contains(t, keys, key) {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(gz)
+		assert.NoError(t, err)
+		assert.Equal(t, "third\n", string(b))
+	}
+	_, err = os.Stat(filepath.Join(dir, "app-2021-05-01T10-02-00.000.log"+archivedSuffix))
+	assert.NoError(t, err)
+
+	// uploaded segments are removed after KeepUploaded
+	clk.Add(2 * time.Hour)
+	assert.NoError(t, a.Close())
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path}, files)
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// AuditFields are the fields an audit entry needs by default.
+var AuditFields = []string{"actor", "action", "resource", "outcome"}
+
+// audit is the audit channel of a logger, see WithAudit.
+type audit struct {
+	sink     Hook
+	required []string
+}
+
+// WithAudit sends the entries logged with Audit to sink, checking they
+// carry the required fields, AuditFields when none are given. If sink is
+// an io.Closer it is closed together with the logger.
+func WithAudit(sink Hook, required ...string) LogOption {
+	return func(l *logger) {
+		if sink == nil {
+			l.setupErrs = append(l.setupErrs, errors.New("log: nil audit sink"))
+			return
+		}
+		if len(required) == 0 {
+			required = AuditFields
+		}
+		l.audit = &audit{sink: sink, required: required}
+		l.addCloser(sink)
+	}
+}
+
+// Audit records the event with its fields for compliance, e.g.
+//
+//	err := logger.Audit("user.deleted", log.LogFields{
+//		"actor": admin, "action": "delete", "resource": "user/42", "outcome": "success",
+//	})
+//
+// The entry goes only to the sink of WithAudit, at the Info level and with
+// the event as message. The fields of the logger, e.g. added with With, are
+// added as for other entries and count as required fields. It is never sampled, limited or filtered by level,
+// and it is written before Audit returns. An error is returned when a
+// required field is missing, no audit sink is configured, the logger is
+// closed or the sink fails, so the caller can refuse the audited action
+// instead of losing its record.
+func (l *logger) Audit(event string, fields LogFields) error {
+	t := l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	if t.audit == nil {
+		return errors.New("log: no audit sink")
+	}
+	if t.closed {
+		return errors.New("log: audit on closed logger")
+	}
+	if l.provenance != nil {
+		defer l.provenance.reset()
+	}
+	fields, _ = l.callFields(nil, fields)
+	var missing []string
+	for _, f := range t.audit.required {
+		if v, ok := fields.lookup(f); !ok || v == nil || v == "" {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("log: audit event %s misses %s", event, strings.Join(missing, ", "))
+	}
+
+	e := Entry{Time: clock(), Level: LevelInfo, Message: event, Fields: fields.clone()}
+	delete(e.Fields, fieldOrderKey)
+	if err := t.audit.sink.Fire(e); err != nil {
+		return fmt.Errorf("log: audit event %s: %w", event, err)
+	}
+
+	return nil
+}
+
+// Audit records the event with the default logger, see Logger.Audit.
+func Audit(event string, fields LogFields) error {
+	return defaultLogger.Audit(event, fields)
+}
+
+// NDJSONHook writes entries to w as NDJSON, one object with time, level,
+// msg and the fields per line. Lines written to an *os.File are synced
+// before Fire returns, so it suits audit logs. If w is an io.Closer it is
+// closed by Close.
+type NDJSONHook struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewNDJSONHook creates a hook writing to w.
+func NewNDJSONHook(w io.Writer) *NDJSONHook {
+	return &NDJSONHook{w: w}
+}
+
+func (h *NDJSONHook) Fire(e Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, err := appendEntryJSON(h.buf[:0], e)
+	if err != nil {
+		return err
+	}
+	h.buf = append(b, '\n')
+	if _, err := h.w.Write(h.buf); err != nil {
+		return err
+	}
+	if f, ok := h.w.(*os.File); ok {
+		return f.Sync()
+	}
+
+	return nil
+}
+
+// Close closes the writer if it is an io.Closer.
+func (h *NDJSONHook) Close() error {
+	if c, ok := h.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
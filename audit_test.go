@@ -0,0 +1,50 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	defer Replay(ReplayConfig{})()
+
+	var out, trail bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithLevel(LevelError), WithSampleRate(0),
+		WithAudit(NewNDJSONHook(&trail)))
+
+	err := l.Audit("user.deleted", LogFields{"actor": "admin", "action": "delete", "resource": "user/42", "outcome": "success"})
+	assert.NoError(t, err)
+	err = l.Audit("user.deleted", LogFields{"actor": "admin", "action": "delete"})
+	assert.EqualError(t, err, "log: audit event user.deleted misses resource, outcome")
+
+	assert.Empty(t, out.String())
+	assert.JSONEq(t, `{"time":"2000-01-01T00:00:00Z","level":"info","msg":"user.deleted","actor":"admin","action":"delete","resource":"user/42","outcome":"success"}`, trail.String())
+
+	l.Close()
+	assert.EqualError(t, l.Audit("late", LogFields{"actor": "a", "action": "b", "resource": "c", "outcome": "d"}), "log: audit on closed logger")
+}
+
+func TestAuditBoundFields(t *testing.T) {
+	defer Replay(ReplayConfig{})()
+
+	var trail bytes.Buffer
+	l := New(nil, WithoutStdout(), WithAudit(NewNDJSONHook(&trail)))
+	defer l.Close()
+
+	req := l.With(LogFields{"actor": "admin", "request_id": "r1"}).WithFields(String("resource", "user/42"))
+	assert.NoError(t, req.Audit("user.deleted", LogFields{"action": "delete", "outcome": "success"}))
+	assert.JSONEq(t, `{"time":"2000-01-01T00:00:00Z","level":"info","msg":"user.deleted","actor":"admin","request_id":"r1","resource":"user/42","action":"delete","outcome":"success"}`, trail.String())
+}
+
+func TestAuditSinkError(t *testing.T) {
+	l := New(nil, WithoutStdout(), WithAudit(HookFunc(func(e Entry) error {
+		return errors.New("disk full")
+	}), "actor"))
+	defer l.Close()
+
+	assert.EqualError(t, l.Audit("login", LogFields{"actor": "bob"}), "log: audit event login: disk full")
+	assert.EqualError(t, New(nil, WithoutStdout()).Audit("login", nil), "log: no audit sink")
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"runtime"
+	"time"
+)
+
+// banner is the startup entry recorded by Banner.
+type banner struct {
+	app, version string
+	start        time.Time
+}
+
+// Banner logs a structured startup entry, "application started" with the
+// fields event=startup, app, version, go_version, os, arch, pid, host and
+// extra, in place of ASCII-art banners which break JSON pipelines. Close
+// then logs "application stopped" with event=shutdown, app, version and
+// uptime.
+func (l *logger) Banner(app, version string, extra LogFields) {
+	l.startBanner(1, app, version, extra)
+}
+
+// Banner logs the startup entry with the default logger.
+func Banner(app, version string, extra LogFields) {
+	defaultLogger.startBanner(1, app, version, extra)
+}
+
+// startBanner implements Banner, the caller is reported depth frames above
+// the caller of startBanner.
+func (l *logger) startBanner(depth int, app, version string, extra LogFields) {
+	fields := LogFields{
+		"event":      "startup",
+		"app":        app,
+		"version":    version,
+		"go_version": runtime.Version(),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+		"pid":        pid(),
+	}
+	if host, err := hostname(); err == nil {
+		fields["host"] = host
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+
+	logLock.Lock()
+	l.top().banner = &banner{app: app, version: version, start: clock()}
+	logLock.Unlock()
+
+	l.with(fields).(*logger).output(LevelInfo, depth, "application started")
+}
+
+// shutdownBanner logs the entry matching Banner, with logLock held.
+func (l *logger) shutdownBanner() {
+	if l.banner == nil || !l.passes(LevelInfo) {
+		return
+	}
+
+	now := clock()
+	l.emit(Entry{
+		Time:    now,
+		Level:   LevelInfo,
+		Message: "application stopped",
+		Fields: LogFields{
+			"event":   "shutdown",
+			"app":     l.banner.app,
+			"version": l.banner.version,
+			"uptime":  now.Sub(l.banner.start).Round(time.Millisecond).String(),
+		},
+	})
+	l.banner = nil
+}
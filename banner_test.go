@@ -0,0 +1,53 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanner(t *testing.T) {
+	defer Replay(ReplayConfig{Step: time.Second})()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFormatter(JsonFormatter{}))
+	l.Banner("billing", "1.4.2", LogFields{"commit": "3f2a9c1"})
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	var start, stop map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &start))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &stop))
+
+	assert.Equal(t, "application started", start["msg"])
+	assert.Equal(t, "startup", start["event"])
+	assert.Equal(t, "billing", start["app"])
+	assert.Equal(t, "1.4.2", start["version"])
+	assert.Equal(t, "3f2a9c1", start["commit"])
+	assert.Equal(t, runtime.Version(), start["go_version"])
+	assert.Equal(t, "replay-host", start["host"])
+	assert.Equal(t, float64(1), start["pid"])
+
+	assert.Equal(t, "application stopped", stop["msg"])
+	assert.Equal(t, "shutdown", stop["event"])
+	assert.Equal(t, "billing", stop["app"])
+	uptime, err := time.ParseDuration(stop["uptime"].(string))
+	assert.NoError(t, err)
+	assert.True(t, uptime > 0, "uptime is measured from the banner")
+}
+
+func TestBannerCaller(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Lshortfile))
+	l.Banner("billing", "1.4.2", nil)
+
+	assert.Contains(t, out.String(), "banner_test.go:")
+}
@@ -0,0 +1,233 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// batcher collects entries in the background and hands them to send in
+// batches of up to size entries, or whatever was collected after wait.
+type batcher struct {
+	name    string
+	entries chan Entry
+	size    int
+	wait    time.Duration
+	send    func([]Entry) error
+	dropped uint64
+	// mu guards closed, entries added after close are dropped instead of
+	// sent on the closed channel
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+	// flushes receives flush requests, the channel is closed once the
+	// entries queued before the request were sent
+	flushes chan chan struct{}
+}
+
+func newBatcher(name string, size, buffer int, wait time.Duration, send func([]Entry) error) *batcher {
+	b := &batcher{
+		name:    name,
+		entries: make(chan Entry, buffer),
+		size:    size,
+		wait:    wait,
+		send:    send,
+		done:    make(chan struct{}),
+		flushes: make(chan chan struct{}),
+	}
+	go b.run()
+
+	return b
+}
+
+// add queues the entry, it is dropped when the buffer is full or the
+// batcher is closed.
+func (b *batcher) add(e Entry) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		atomic.AddUint64(&b.dropped, 1)
+		return false
+	}
+	select {
+	case b.entries <- e:
+		return true
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+		return false
+	}
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	t := time.NewTicker(b.wait)
+	defer t.Stop()
+
+	batch := make([]Entry, 0, b.size)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.send(batch); err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to send %d log entries to %s: %v\n", len(batch), b.name, err)
+		}
+		batch = make([]Entry, 0, b.size)
+	}
+
+	for {
+		select {
+		case e, ok := <-b.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= b.size {
+				flush()
+			}
+		case <-t.C:
+			flush()
+		case ack := <-b.flushes:
+			for queued := len(b.entries); queued > 0; queued-- {
+				e, ok := <-b.entries
+				if !ok {
+					break
+				}
+				if batch = append(batch, e); len(batch) >= b.size {
+					flush()
+				}
+			}
+			flush()
+			close(ack)
+		}
+	}
+}
+
+// flush sends the entries queued so far, it returns ctx.Err() when ctx is
+// done first.
+func (b *batcher) flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case b.flushes <- ack:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close sends the remaining entries and stops the batcher.
+func (b *batcher) close() {
+	b.closeContext(context.Background())
+}
+
+// closeContext stops the batcher like close, but returns ctx.Err() when ctx
+// is done before the remaining entries were sent. The batcher keeps sending
+// them in the background.
+func (b *batcher) closeContext(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.entries)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Backoff configures retries of failed deliveries.
+type Backoff struct {
+	// MaxRetries after the first attempt.
+	MaxRetries int
+	// Min is the wait before the first retry, it doubles up to Max.
+	Min time.Duration
+	Max time.Duration
+}
+
+// DefaultBackoff is used by sinks when no backoff is configured.
+var DefaultBackoff = Backoff{MaxRetries: 5, Min: 500 * time.Millisecond, Max: 30 * time.Second}
+
+// permanentError stops retries.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// retry calls fn until it succeeds, returns a permanentError or retries are
+// exhausted.
+func (b Backoff) retry(fn func() error) error {
+	wait := b.Min
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if p, ok := err.(permanentError); ok {
+			return p.err
+		}
+		if attempt >= b.MaxRetries {
+			return err
+		}
+
+		time.Sleep(wait)
+		if wait *= 2; wait > b.Max {
+			wait = b.Max
+		}
+	}
+}
+
+// post sends body to url, server errors and 429 are retried, other
+// responses outside 2xx are permanent errors.
+func post(client *http.Client, url, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s: %s %s", url, resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+
+	return permanentError{err}
+}
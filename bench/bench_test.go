@@ -0,0 +1,141 @@
+// Package bench compares the logger with log/slog, zap and zerolog. It is a
+// separate module so the comparison does not add dependencies to the
+// logger. Run it with:
+//
+//	cd bench && go test -bench . -benchmem
+package bench
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/bialas1993/log"
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Every scenario logs "request handled" with the same five fields to
+// io.Discard, with timestamps and without the caller.
+const msg = "request handled"
+
+var fields = log.LogFields{"user": 42, "path": "/api/v1/users", "method": "GET", "status": 200, "ok": true}
+
+func newLogger(f log.Formatter, lvl log.Level) log.Logger {
+	l := log.New(io.Discard, log.WithoutStdout(), log.WithFormatter(f), log.WithLevel(lvl))
+	l.SetFlags(log.LstdFlags)
+
+	return l
+}
+
+func newZap(enc zapcore.Encoder, lvl zapcore.Level) *zap.Logger {
+	return zap.New(zapcore.NewCore(enc, zapcore.AddSync(io.Discard), lvl))
+}
+
+func zapJSON() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+}
+
+func zapConsole() zapcore.Encoder {
+	return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+}
+
+// Disabled level: a debug entry on an info logger.
+
+func BenchmarkDisabled(b *testing.B) {
+	b.Run("log", func(b *testing.B) {
+		l := newLogger(log.JsonFormatter{}, log.LevelInfo)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.With(fields).Debug(msg)
+		}
+	})
+	b.Run("slog", func(b *testing.B) {
+		l := slog.New(slog.NewJSONHandler(io.Discard, nil))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug(msg, "user", 42, "path", "/api/v1/users", "method", "GET", "status", 200, "ok", true)
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		l := newZap(zapJSON(), zapcore.InfoLevel)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug(msg, zap.Int("user", 42), zap.String("path", "/api/v1/users"), zap.String("method", "GET"), zap.Int("status", 200), zap.Bool("ok", true))
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		l := zerolog.New(io.Discard).Level(zerolog.InfoLevel).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug().Int("user", 42).Str("path", "/api/v1/users").Str("method", "GET").Int("status", 200).Bool("ok", true).Msg(msg)
+		}
+	})
+}
+
+// JSON: an info entry with five fields.
+
+func BenchmarkJSON(b *testing.B) {
+	b.Run("log", func(b *testing.B) {
+		l := newLogger(log.JsonFormatter{}, log.LevelInfo)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.With(fields).Info(msg)
+		}
+	})
+	b.Run("slog", func(b *testing.B) {
+		l := slog.New(slog.NewJSONHandler(io.Discard, nil))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info(msg, "user", 42, "path", "/api/v1/users", "method", "GET", "status", 200, "ok", true)
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		l := newZap(zapJSON(), zapcore.InfoLevel)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info(msg, zap.Int("user", 42), zap.String("path", "/api/v1/users"), zap.String("method", "GET"), zap.Int("status", 200), zap.Bool("ok", true))
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		l := zerolog.New(io.Discard).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info().Int("user", 42).Str("path", "/api/v1/users").Str("method", "GET").Int("status", 200).Bool("ok", true).Msg(msg)
+		}
+	})
+}
+
+// Console: an info entry with five fields as human readable text.
+
+func BenchmarkConsole(b *testing.B) {
+	b.Run("log", func(b *testing.B) {
+		l := newLogger(log.StdFormatter{}, log.LevelInfo)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.With(fields).Info(msg)
+		}
+	})
+	b.Run("slog", func(b *testing.B) {
+		l := slog.New(slog.NewTextHandler(io.Discard, nil))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info(msg, "user", 42, "path", "/api/v1/users", "method", "GET", "status", 200, "ok", true)
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		l := newZap(zapConsole(), zapcore.InfoLevel)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info(msg, zap.Int("user", 42), zap.String("path", "/api/v1/users"), zap.String("method", "GET"), zap.Int("status", 200), zap.Bool("ok", true))
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		l := zerolog.New(zerolog.ConsoleWriter{Out: io.Discard, NoColor: true}).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info().Int("user", 42).Str("path", "/api/v1/users").Str("method", "GET").Int("status", 200).Bool("ok", true).Msg(msg)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+module github.com/bialas1993/log/bench
+
+go 1.21
+
+require (
+	github.com/bialas1993/log v0.0.0-20261016150051-f247064be79f
+	github.com/rs/zerolog v1.33.0
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+)
+
+// builds against the repository root, the module is used from a checkout
+// until it is released with a tagged root version, see Modules in README.md
+replace github.com/bialas1993/log => ../
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func newBenchLogger(f Formatter, flags int) *logger {
+	return &logger{
+		outputs:   []*output{newOutput(f, flags, levelWriters(ioutil.Discard))},
+		formatter: f,
+		fields:    LogFields{},
+		level:     uint32(LevelDebug),
+		flags:     flags,
+	}
+}
+
+func benchmarkLogger(b *testing.B, l *logger) {
+	fields := LogFields{"user": 42, "path": "/api/v1/users", "ok": true}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.With(fields).Info("request handled")
+	}
+}
+
+func BenchmarkStdFormatter(b *testing.B) {
+	benchmarkLogger(b, newBenchLogger(StdFormatter{}, LstdFlags))
+}
+
+func BenchmarkJsonFormatter(b *testing.B) {
+	benchmarkLogger(b, newBenchLogger(JsonFormatter{}, Ldisable))
+}
+
+func BenchmarkCSVFormatter(b *testing.B) {
+	benchmarkLogger(b, newBenchLogger(CSVFormatter{Columns: []string{ColumnLevel, ColumnMsg, "user", "path"}}, Ldisable))
+}
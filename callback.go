@@ -0,0 +1,39 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+)
+
+// SafeCallback returns fn wrapped to recover from its panics, for Go
+// functions called by C libraries through cgo, where a panic would abort
+// the process. A panic is logged by the default logger as an Error entry
+// with the fields callback, the function name, panic and stack, and the
+// wrapper returns normally:
+//
+//	//export onEvent
+//	func onEvent(code C.int) {
+//		log.SafeCallback(func() { handle(int(code)) })()
+//	}
+func SafeCallback(fn func()) func() {
+	name := "unknown"
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		name = f.Name()
+	}
+
+	return func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				defaultLogger.diagnostic(LevelError, fmt.Sprintf("panic in callback %s: %v", name, rec), LogFields{
+					"callback": name,
+					"panic":    fmt.Sprint(rec),
+					"stack":    string(debug.Stack()),
+				})
+			}
+		}()
+
+		fn()
+	}
+}
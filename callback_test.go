@@ -0,0 +1,23 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeCallback(t *testing.T) {
+	initialize()
+	defer initialize()
+	var out bytes.Buffer
+	New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	called := false
+	SafeCallback(func() { called = true })()
+	assert.True(t, called)
+	assert.Empty(t, out.String())
+
+	assert.NotPanics(t, SafeCallback(func() { panic("bad input") }))
+	assert.Regexp(t, `(?s)^ERROR: callback=github.com/bialas1993/log.TestSafeCallback.func2 panic="bad input" stack=".*callback_test.go.*" panic in callback github.com/bialas1993/log.TestSafeCallback.func2: bad input\n$`, out.String())
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+var captured struct {
+	sync.Mutex
+	// stderr is os.Stderr when the capture started, orig writes to the
+	// original stderr.
+	stderr *os.File
+	orig   *os.File
+}
+
+// consoleWriter writes to a console file. While stderr is captured, writes
+// to the captured file go to the original stderr, so the logger does not
+// read its own output back.
+type consoleWriter struct {
+	f *os.File
+}
+
+func (w consoleWriter) Write(p []byte) (int, error) {
+	captured.Lock()
+	defer captured.Unlock()
+
+	if captured.orig != nil && w.f == captured.stderr {
+		return captured.orig.Write(p)
+	}
+
+	return w.f.Write(p)
+}
+
+// Stderr returns a writer to the process stderr which bypasses
+// CaptureStderr. Sinks report their own failures to it: written to the
+// captured stderr, the report would be logged back to the failing sink.
+func Stderr() io.Writer {
+	return consoleWriter{os.Stderr}
+}
+
+// CaptureStderr redirects the process stderr into the default logger, every
+// line becomes an Error entry with the field source=stderr. It catches
+// output which bypasses the logger, such as fmt.Fprintln(os.Stderr, ...) or
+// messages of C libraries, which would otherwise be lost when only the
+// structured logs are collected. The console output of the logger keeps
+// going to the original stderr.
+//
+// On Unix the descriptor 2 is redirected, on Windows the standard error
+// handle and os.Stderr. A crash message written while the process exits may
+// not be logged. restore ends the capture once the pending lines are logged,
+// it waits for child processes which inherited the captured stderr.
+func CaptureStderr() (restore func(), err error) {
+	captured.Lock()
+	defer captured.Unlock()
+
+	if captured.orig != nil {
+		return nil, errors.New("log: stderr is already captured")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := os.Stderr
+	orig, undo, err := redirectStderr(w)
+	w.Close()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	captured.stderr, captured.orig = stderr, orig
+
+	l := defaultLogger
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readStderr(l, r)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := undo(); err != nil {
+				fmt.Fprintf(orig, "Failed to restore stderr: %v\n", err)
+				return
+			}
+			<-done
+			r.Close()
+
+			captured.Lock()
+			captured.stderr, captured.orig = nil, nil
+			captured.Unlock()
+			orig.Close()
+		})
+	}, nil
+}
+
+// readStderr logs the lines read from r until it is closed.
+func readStderr(l *logger, r *os.File) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			l.diagnostic(LevelError, line, LogFields{"source": "stderr"})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
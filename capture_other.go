@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func redirectStderr(w *os.File) (*os.File, func() error, error) {
+	return nil, nil, fmt.Errorf("log: capturing stderr is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// redirectStderr points the descriptor 2 to w. It returns a file writing to
+// the original stderr and a function pointing the descriptor back to it.
+func redirectStderr(w *os.File) (*os.File, func() error, error) {
+	fd, err := unix.Dup(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	unix.CloseOnExec(fd)
+
+	if err := unix.Dup2(int(w.Fd()), 2); err != nil {
+		unix.Close(fd)
+		return nil, nil, err
+	}
+
+	return os.NewFile(uintptr(fd), "/dev/stderr"), func() error {
+		return unix.Dup2(fd, 2)
+	}, nil
+}
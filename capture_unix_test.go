@@ -0,0 +1,108 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureStderr(t *testing.T) {
+	initialize()
+	defer initialize()
+
+	var mu sync.Mutex
+	var entries []Entry
+	l := NewStdLogger(WithoutStdout(), WithHook(HookFunc(func(e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, e)
+		return nil
+	})))
+	defer l.Close()
+
+	restore, err := CaptureStderr()
+	assert.NoError(t, err)
+	_, err = CaptureStderr()
+	assert.Error(t, err)
+
+	fmt.Fprintln(os.Stderr, "stray output")
+	fmt.Fprint(os.Stderr, "no newline")
+	restore()
+	restore()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "stray output", entries[0].Message)
+		assert.Equal(t, LevelError, entries[0].Level)
+		assert.Equal(t, "stderr", entries[0].Fields["source"])
+		assert.Equal(t, "no newline", entries[1].Message)
+	}
+}
+
+func TestCaptureStderrConsole(t *testing.T) {
+	initialize()
+	defer initialize()
+
+	var mu sync.Mutex
+	count := 0
+	l := NewStdLogger(WithFlags(Ldisable), WithHook(HookFunc(func(e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		return nil
+	})))
+	defer l.Close()
+
+	restore, err := CaptureStderr()
+	assert.NoError(t, err)
+	// written to the original stderr, not read back as a captured line
+	l.Error("from the logger")
+	restore()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, count)
+}
+
+func TestCaptureStderrSinkFailure(t *testing.T) {
+	initialize()
+	defer initialize()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	count := 0
+	sink := NewHTTPSink(srv.URL, WithHTTPBatch(100, time.Hour))
+	l := NewStdLogger(WithoutStdout(), WithHook(sink), WithHook(HookFunc(func(e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		return nil
+	})))
+	defer l.Close()
+
+	restore, err := CaptureStderr()
+	assert.NoError(t, err)
+	l.Info("rejected")
+	// the failure report goes to the original stderr, not back to the sink
+	sink.Flush(context.Background())
+	restore()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,43 @@
+package log
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// redirectStderr sets the standard error handle and os.Stderr to a copy of
+// w. It returns a file writing to the original stderr and a function
+// restoring both.
+func redirectStderr(w *os.File) (*os.File, func() error, error) {
+	p := windows.CurrentProcess()
+	orig, err := windows.GetStdHandle(windows.STD_ERROR_HANDLE)
+	if err != nil {
+		return nil, nil, err
+	}
+	var origDup, pipe windows.Handle
+	if err := windows.DuplicateHandle(p, orig, p, &origDup, 0, false, windows.DUPLICATE_SAME_ACCESS); err != nil {
+		return nil, nil, err
+	}
+	if err := windows.DuplicateHandle(p, windows.Handle(w.Fd()), p, &pipe, 0, false, windows.DUPLICATE_SAME_ACCESS); err != nil {
+		windows.CloseHandle(origDup)
+		return nil, nil, err
+	}
+	if err := windows.SetStdHandle(windows.STD_ERROR_HANDLE, pipe); err != nil {
+		windows.CloseHandle(origDup)
+		windows.CloseHandle(pipe)
+		return nil, nil, err
+	}
+
+	stderr, captured := os.Stderr, os.NewFile(uintptr(pipe), "/dev/stderr")
+	os.Stderr = captured
+
+	return os.NewFile(uintptr(origDup), "/dev/stderr"), func() error {
+		if err := windows.SetStdHandle(windows.STD_ERROR_HANDLE, orig); err != nil {
+			return err
+		}
+		os.Stderr = stderr
+
+		return captured.Close()
+	}, nil
+}
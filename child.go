@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// envParentFD passes the descriptor of the pipe to the parent to a child
+// started with AdoptChild.
+const envParentFD = "LOG_PARENT_FD"
+
+// AdoptChild starts cmd with a pipe through which the child sends its
+// entries, when its logger was created with WithParent. The parent logs
+// them with the default logger and the field child set to the program
+// name, so the entries of plugins reach the sinks of the parent.
+//
+// The protocol is NDJSON: every line is an object with the keys time,
+// level, msg and the fields of the entry. Lines which are not such objects
+// are logged at Info as they are. Passing the pipe is not supported on
+// Windows.
+func AdoptChild(cmd *exec.Cmd) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, envParentFD+"="+strconv.Itoa(3+len(cmd.ExtraFiles)))
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		r.Close()
+		return err
+	}
+
+	go readChild(defaultLogger, filepath.Base(cmd.Path), r)
+
+	return nil
+}
+
+// readChild logs the entries read from r until the child closes it.
+func readChild(l *logger, name string, r *os.File) {
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			lvl, msg, fields := parseChildEntry(line)
+			fields["child"] = name
+			l.diagnostic(lvl, msg, fields)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func parseChildEntry(line string) (Level, string, LogFields) {
+	var fields LogFields
+	if err := json.Unmarshal([]byte(line), &fields); err != nil || fields == nil {
+		return LevelInfo, line, LogFields{}
+	}
+
+	msg, _ := fields["msg"].(string)
+	name, _ := fields["level"].(string)
+	delete(fields, "msg")
+	delete(fields, "level")
+	delete(fields, "time")
+
+	for lvl, n := range levelMap {
+		if n == name {
+			return lvl, msg, fields
+		}
+	}
+
+	return LevelInfo, msg, fields
+}
+
+// parentHook writes entries to the pipe of the parent.
+type parentHook struct {
+	mu  sync.Mutex
+	f   *os.File
+	buf []byte
+}
+
+func (h *parentHook) Fire(e Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, err := appendEntryJSON(h.buf[:0], e)
+	if err != nil {
+		return err
+	}
+	h.buf = append(b, '\n')
+	_, err = h.f.Write(h.buf)
+
+	return err
+}
+
+func (h *parentHook) Close() error {
+	return h.f.Close()
+}
+
+// WithParent sends the entries to the parent process instead of the
+// console when the program was started with AdoptChild, other outputs are
+// kept. Otherwise it has no effect.
+func WithParent() LogOption {
+	return func(l *logger) {
+		fd, err := strconv.Atoi(os.Getenv(envParentFD))
+		if err != nil {
+			return
+		}
+		// children of the child don't inherit the pipe
+		os.Unsetenv(envParentFD)
+
+		l.noConsole = true
+		WithHook(&parentHook{f: os.NewFile(uintptr(fd), "log-parent")})(l)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdoptChildHelper(t *testing.T) {
+	if os.Getenv("LOG_TEST_CHILD") == "" {
+		t.Skip("run by TestAdoptChild")
+	}
+
+	l := NewStdLogger(WithParent(), WithLevel(LevelDebug))
+	l.With(LogFields{"plugin": "resize"}).Warning("slow")
+	l.Debug("done")
+	l.Close()
+	os.Stdout.WriteString("plain output\n")
+}
+
+func TestAdoptChild(t *testing.T) {
+	initialize()
+	defer initialize()
+
+	var mu sync.Mutex
+	var entries []Entry
+	NewStdLogger(WithoutStdout(), WithLevel(LevelDebug), WithHook(HookFunc(func(e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, e)
+		return nil
+	})))
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestAdoptChildHelper")
+	cmd.Env = append(os.Environ(), "LOG_TEST_CHILD=1")
+	assert.NoError(t, AdoptChild(cmd))
+	assert.NoError(t, cmd.Wait())
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(entries) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	child := filepath.Base(os.Args[0])
+	assert.Equal(t, LevelWarning, entries[0].Level)
+	assert.Equal(t, "slow", entries[0].Message)
+	assert.Equal(t, LogFields{"plugin": "resize", "child": child}, entries[0].Fields)
+	assert.Equal(t, LevelDebug, entries[1].Level)
+}
+
+func TestParseChildEntry(t *testing.T) {
+	lvl, msg, fields := parseChildEntry(`{"time":"2000-01-01T00:00:00Z","level":"error","msg":"failed","code":500}`)
+	assert.Equal(t, LevelError, lvl)
+	assert.Equal(t, "failed", msg)
+	assert.Equal(t, LogFields{"code": float64(500)}, fields)
+
+	lvl, msg, fields = parseChildEntry("not json")
+	assert.Equal(t, LevelInfo, lvl)
+	assert.Equal(t, "not json", msg)
+	assert.Empty(t, fields)
+}
@@ -0,0 +1,21 @@
+module github.com/bialas1993/log/cloudwatch
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.30.0
+	github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs v1.37.0
+	github.com/bialas1993/log v0.0.0-20261016150051-f247064be79f
+)
+
+require (
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.2 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.12 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.12 // indirect
+	github.com/aws/smithy-go v1.20.2 // indirect
+	golang.org/x/sys v0.0.0-20210426230700-d19ff857e887 // indirect
+)
+
+// builds against the repository root, the module is used from a checkout
+// until it is released with a tagged root version, see Modules in README.md
+replace github.com/bialas1993/log => ../
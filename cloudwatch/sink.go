@@ -0,0 +1,318 @@
+// Package cloudwatch sends log entries to Amazon CloudWatch Logs. It is a
+// separate module, so only programs using it depend on the AWS SDK.
+//
+//	client := cloudwatchlogs.NewFromConfig(awsCfg)
+//	sink, err := cloudwatch.New(ctx, client, cloudwatch.Config{Group: "api", Stream: host})
+//	logger := log.NewJsonLogger(log.WithHook(sink))
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/bialas1993/log"
+)
+
+// PutLogEvents limits, see the CloudWatch Logs quotas.
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	maxBatchSpan   = 24 * time.Hour
+	// eventOverhead is added to the message size of every event.
+	eventOverhead = 26
+	maxEventBytes = 256*1024 - eventOverhead
+)
+
+// Client is the part of *cloudwatchlogs.Client used by the sink.
+type Client interface {
+	CreateLogGroup(ctx context.Context, in *cloudwatchlogs.CreateLogGroupInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, in *cloudwatchlogs.CreateLogStreamInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(ctx context.Context, in *cloudwatchlogs.PutLogEventsInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// Config configures a Sink.
+type Config struct {
+	// Group and Stream are created when they do not exist.
+	Group  string
+	Stream string
+	// BatchWait is how long entries are collected before they are sent,
+	// defaults to 5 seconds. Batches are sent earlier when they reach the
+	// PutLogEvents limits.
+	BatchWait time.Duration
+	// QueueSize bounds the number of entries waiting to be sent, more
+	// entries are dropped. Defaults to 10000.
+	QueueSize int
+	// Timeout of a single API call, defaults to 30 seconds.
+	Timeout time.Duration
+	// MaxRetries of a failed batch, defaults to 3.
+	MaxRetries int
+}
+
+// Sink is a hook sending entries to a CloudWatch Logs stream as JSON
+// messages with time, level, msg and the entry fields. Use it with
+// log.WithHook, it is flushed and stopped when the logger is closed.
+type Sink struct {
+	client  Client
+	cfg     Config
+	entries chan log.Entry
+	dropped uint64
+	// mu guards closed, entries fired after Close are dropped
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+
+	// used by the sender goroutine
+	token *string
+	batch []types.InputLogEvent
+	size  int
+	// first and last are the earliest and latest timestamps of the batch
+	first, last int64
+}
+
+// New creates the log group and stream if needed and starts the sender.
+func New(ctx context.Context, client Client, cfg Config) (*Sink, error) {
+	if cfg.BatchWait <= 0 {
+		cfg.BatchWait = 5 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+
+	_, err := client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{LogGroupName: aws.String(cfg.Group)})
+	if err != nil && !alreadyExists(err) {
+		return nil, fmt.Errorf("cloudwatch: create log group %s: %w", cfg.Group, err)
+	}
+	_, err = client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(cfg.Group),
+		LogStreamName: aws.String(cfg.Stream),
+	})
+	if err != nil && !alreadyExists(err) {
+		return nil, fmt.Errorf("cloudwatch: create log stream %s: %w", cfg.Stream, err)
+	}
+
+	s := &Sink{
+		client:  client,
+		cfg:     cfg,
+		entries: make(chan log.Entry, cfg.QueueSize),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+
+	return s, nil
+}
+
+func alreadyExists(err error) bool {
+	var exists *types.ResourceAlreadyExistsException
+	return errors.As(err, &exists)
+}
+
+// Fire queues the entry, it is dropped when the queue is full or the sink
+// is closed.
+func (s *Sink) Fire(e log.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		atomic.AddUint64(&s.dropped, 1)
+		return nil
+	}
+	select {
+	case s.entries <- e:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full
+// or the sink was closed.
+func (s *Sink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close sends the queued entries and stops the sender.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.entries)
+	}
+	s.mu.Unlock()
+	<-s.done
+
+	return nil
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+
+	t := time.NewTicker(s.cfg.BatchWait)
+	defer t.Stop()
+
+	for {
+		select {
+		case e, ok := <-s.entries:
+			if !ok {
+				s.flush()
+				return
+			}
+			s.add(e)
+		case <-t.C:
+			s.flush()
+		}
+	}
+}
+
+// add appends the entry to the batch, sending the batch first when the
+// entry would exceed a PutLogEvents limit.
+func (s *Sink) add(e log.Entry) {
+	msg, err := message(e)
+	if err != nil {
+		fmt.Fprintf(log.Stderr(), "Failed to encode log entry for cloudwatch: %v\n", err)
+		return
+	}
+	size := len(msg) + eventOverhead
+	ts := e.Time.UnixMilli()
+
+	if len(s.batch) > 0 {
+		first, last := s.first, s.last
+		if ts < first {
+			first = ts
+		}
+		if ts > last {
+			last = ts
+		}
+		span := time.Duration(last-first) * time.Millisecond
+		if len(s.batch) == maxBatchEvents || s.size+size > maxBatchBytes || span > maxBatchSpan {
+			s.flush()
+		}
+	}
+
+	if len(s.batch) == 0 {
+		s.first, s.last = ts, ts
+	} else if ts < s.first {
+		s.first = ts
+	} else if ts > s.last {
+		s.last = ts
+	}
+	s.batch = append(s.batch, types.InputLogEvent{
+		Message:   aws.String(msg),
+		Timestamp: aws.Int64(ts),
+	})
+	s.size += size
+}
+
+// message renders the entry as a JSON object within the event size limit.
+// A message too long is cut on a character boundary, when the fields alone
+// exceed the limit they are left out and truncated is set.
+func message(e log.Entry) (string, error) {
+	fields := make(log.LogFields, len(e.Fields)+3)
+	for k, v := range e.Fields {
+		fields[k] = v
+	}
+	fields["time"] = e.Time.Format(time.RFC3339Nano)
+	fields["level"] = e.Level.String()
+
+	msg := e.Message
+	for {
+		fields["msg"] = msg
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return "", err
+		}
+		if len(b) <= maxEventBytes {
+			return string(b), nil
+		}
+		if msg == "" {
+			break
+		}
+		// every byte cut shortens the JSON by at least a byte
+		msg = truncate(msg, len(msg)-(len(b)-maxEventBytes))
+	}
+
+	e.Fields = log.LogFields{"truncated": true}
+	return message(e)
+}
+
+// truncate cuts s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+func (s *Sink) flush() {
+	if len(s.batch) == 0 {
+		return
+	}
+
+	// events of a call must be in chronological order
+	sort.SliceStable(s.batch, func(i, j int) bool {
+		return *s.batch[i].Timestamp < *s.batch[j].Timestamp
+	})
+
+	if err := s.put(s.batch); err != nil {
+		fmt.Fprintf(log.Stderr(), "Failed to send %d log entries to cloudwatch: %v\n", len(s.batch), err)
+	}
+	s.batch, s.size = nil, 0
+}
+
+// put sends the events, it updates the sequence token when CloudWatch
+// expects another one and retries other failures.
+func (s *Sink) put(events []types.InputLogEvent) error {
+	var err error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+		var out *cloudwatchlogs.PutLogEventsOutput
+		out, err = s.client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(s.cfg.Group),
+			LogStreamName: aws.String(s.cfg.Stream),
+			LogEvents:     events,
+			SequenceToken: s.token,
+		})
+		cancel()
+		if err == nil {
+			s.token = out.NextSequenceToken
+			return nil
+		}
+
+		var invalid *types.InvalidSequenceTokenException
+		if errors.As(err, &invalid) {
+			s.token = invalid.ExpectedSequenceToken
+			continue
+		}
+		var accepted *types.DataAlreadyAcceptedException
+		if errors.As(err, &accepted) {
+			s.token = accepted.ExpectedSequenceToken
+			return nil
+		}
+	}
+
+	return err
+}
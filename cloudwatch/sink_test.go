@@ -0,0 +1,142 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/bialas1993/log"
+)
+
+type fakeClient struct {
+	mu      sync.Mutex
+	created []string
+	puts    []*cloudwatchlogs.PutLogEventsInput
+	token   string
+}
+
+func (c *fakeClient) CreateLogGroup(ctx context.Context, in *cloudwatchlogs.CreateLogGroupInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	c.created = append(c.created, "group:"+*in.LogGroupName)
+	return nil, &types.ResourceAlreadyExistsException{}
+}
+
+func (c *fakeClient) CreateLogStream(ctx context.Context, in *cloudwatchlogs.CreateLogStreamInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	c.created = append(c.created, "stream:"+*in.LogStreamName)
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (c *fakeClient) PutLogEvents(ctx context.Context, in *cloudwatchlogs.PutLogEventsInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if aws.ToString(in.SequenceToken) != c.token {
+		return nil, &types.InvalidSequenceTokenException{ExpectedSequenceToken: aws.String(c.token)}
+	}
+	c.puts = append(c.puts, in)
+	c.token += "x"
+
+	return &cloudwatchlogs.PutLogEventsOutput{NextSequenceToken: aws.String(c.token)}, nil
+}
+
+func TestSink(t *testing.T) {
+	client := &fakeClient{token: "t1"}
+	sink, err := New(context.Background(), client, Config{Group: "api", Stream: "host-1", BatchWait: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(client.created, ","); got != "group:api,stream:host-1" {
+		t.Errorf("created %s", got)
+	}
+
+	now := time.Now()
+	sink.Fire(log.Entry{Time: now.Add(time.Second), Level: log.LevelError, Message: "second", Fields: log.LogFields{"user": "ann"}})
+	sink.Fire(log.Entry{Time: now, Level: log.LevelInfo, Message: "first", Fields: log.LogFields{}})
+	sink.Fire(log.Entry{Time: now.Add(25 * time.Hour), Level: log.LevelInfo, Message: "next day", Fields: log.LogFields{}})
+	sink.Close()
+
+	if len(client.puts) != 2 {
+		t.Fatalf("got %d PutLogEvents calls, want 2", len(client.puts))
+	}
+	events := client.puts[0].LogEvents
+	if len(events) != 2 || *events[0].Timestamp != now.UnixMilli() {
+		t.Fatalf("events not sorted: %v", events)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal([]byte(*events[1].Message), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg["msg"] != "second" || msg["level"] != "error" || msg["user"] != "ann" {
+		t.Errorf("unexpected message %v", msg)
+	}
+	if aws.ToString(client.puts[1].SequenceToken) != "t1x" {
+		t.Errorf("sequence token %q not carried over", aws.ToString(client.puts[1].SequenceToken))
+	}
+}
+
+func TestSinkAfterClose(t *testing.T) {
+	sink, err := New(context.Background(), &fakeClient{}, Config{Group: "api", Stream: "host-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Close()
+
+	if err := sink.Fire(log.Entry{Time: time.Now(), Level: log.LevelInfo, Message: "after close"}); err != nil {
+		t.Fatal(err)
+	}
+	if sink.Dropped() != 1 {
+		t.Errorf("dropped %d entries, want 1", sink.Dropped())
+	}
+}
+
+func TestSinkBatchSpan(t *testing.T) {
+	client := &fakeClient{}
+	sink, err := New(context.Background(), client, Config{Group: "api", Stream: "host-1", BatchWait: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for _, ts := range []time.Time{now, now.Add(23 * time.Hour), now.Add(-2 * time.Hour)} {
+		sink.Fire(log.Entry{Time: ts, Level: log.LevelInfo, Message: "tick", Fields: log.LogFields{}})
+	}
+	sink.Close()
+
+	if len(client.puts) != 2 || len(client.puts[0].LogEvents) != 2 {
+		t.Fatalf("got %d PutLogEvents calls, want 2 with the first spanning 23h", len(client.puts))
+	}
+}
+
+func TestMessageTruncated(t *testing.T) {
+	now := time.Now()
+	msg, err := message(log.Entry{Time: now, Level: log.LevelInfo, Message: strings.Repeat("ż\"", maxEventBytes/2), Fields: log.LogFields{"user": "ann"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(msg), &m); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(msg) > maxEventBytes || !utf8.ValidString(m["msg"].(string)) || m["user"] != "ann" {
+		t.Errorf("message of %d bytes not truncated to a valid prefix", len(msg))
+	}
+
+	msg, err = message(log.Entry{Time: now, Level: log.LevelInfo, Message: "dump", Fields: log.LogFields{"blob": strings.Repeat("x", maxEventBytes)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = nil
+	if err := json.Unmarshal([]byte(msg), &m); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if m["msg"] != "dump" || m["truncated"] != true || m["blob"] != nil {
+		t.Errorf("unexpected message %v", m)
+	}
+}
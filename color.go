@@ -0,0 +1,94 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// Console capabilities, see consoleMode.
+const (
+	consolePlain = iota
+	consoleVT
+	consoleLegacy
+)
+
+// console returns the writer of the console file f for the colorized
+// formatter. Escape codes are kept on terminals interpreting them,
+// translated to console API calls on Windows consoles without virtual
+// terminal processing and stripped otherwise, e.g. when the output is
+// redirected to a file. The formatter is thus safe to use unconditionally.
+func console(f *os.File) io.Writer {
+	switch consoleMode(f) {
+	case consoleVT:
+		return consoleWriter{f}
+	case consoleLegacy:
+		return newLegacyConsole(f)
+	}
+
+	return &ansiWriter{w: consoleWriter{f}}
+}
+
+// ansiWriter passes text to w and SGR escape sequences, e.g. "\x1b[31;1m",
+// to sgr, or drops them when sgr is nil. Other escape sequences are dropped.
+type ansiWriter struct {
+	w   io.Writer
+	sgr func(params []int)
+	buf []byte
+}
+
+func (a *ansiWriter) Write(p []byte) (int, error) {
+	a.buf = a.buf[:0]
+	for i := 0; i < len(p); i++ {
+		if p[i] != 0x1b || i+1 == len(p) || p[i+1] != '[' {
+			a.buf = append(a.buf, p[i])
+			continue
+		}
+
+		// CSI: parameters and intermediates, then a final byte 0x40-0x7e
+		end := i + 2
+		for end < len(p) && (p[end] < 0x40 || p[end] > 0x7e) {
+			end++
+		}
+		if end == len(p) {
+			break
+		}
+		if p[end] == 'm' && a.sgr != nil {
+			if err := a.flush(); err != nil {
+				return 0, err
+			}
+			a.sgr(sgrParams(p[i+2 : end]))
+		}
+		i = end
+	}
+	if err := a.flush(); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (a *ansiWriter) flush() error {
+	if len(a.buf) == 0 {
+		return nil
+	}
+	_, err := a.w.Write(a.buf)
+	a.buf = a.buf[:0]
+
+	return err
+}
+
+// sgrParams parses "31;1", an empty list means reset.
+func sgrParams(b []byte) []int {
+	params := []int{}
+	start := 0
+	for i := 0; i <= len(b); i++ {
+		if i == len(b) || b[i] == ';' {
+			n, _ := strconv.Atoi(string(b[start:i]))
+			params = append(params, n)
+			start = i + 1
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"io"
+	"os"
+)
+
+func consoleMode(f *os.File) int {
+	if isTerminal(f) {
+		return consoleVT
+	}
+
+	return consolePlain
+}
+
+func newLegacyConsole(f *os.File) io.Writer {
+	return consoleWriter{f}
+}
@@ -0,0 +1,30 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnsiWriterStrips(t *testing.T) {
+	var buf bytes.Buffer
+	a := &ansiWriter{w: &buf}
+	a.Write([]byte(CLR_R + "ERROR: " + RESET + "failed\n"))
+	a.Write([]byte("\x1b[2Kcleared\n"))
+
+	assert.Equal(t, "ERROR: failed\ncleared\n", buf.String())
+}
+
+func TestAnsiWriterSGR(t *testing.T) {
+	var buf bytes.Buffer
+	var calls [][]int
+	a := &ansiWriter{w: &buf, sgr: func(params []int) {
+		calls = append(calls, params)
+		buf.WriteString("|")
+	}}
+	a.Write([]byte(CLR_Y + "WARN : " + RESET + "slow\n"))
+
+	assert.Equal(t, "|WARN : |slow\n", buf.String())
+	assert.Equal(t, [][]int{{33, 1}, {0}}, calls)
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+var procSetConsoleTextAttribute = windows.NewLazySystemDLL("kernel32.dll").NewProc("SetConsoleTextAttribute")
+
+const (
+	fgBlue      = 0x1
+	fgGreen     = 0x2
+	fgRed       = 0x4
+	fgIntensity = 0x8
+)
+
+// consoleMode enables virtual terminal processing, available since
+// Windows 10. Older consoles are legacy, redirected output is plain.
+func consoleMode(f *os.File) int {
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return consolePlain
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return consoleVT
+	}
+	if err := windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err == nil {
+		return consoleVT
+	}
+
+	return consoleLegacy
+}
+
+// newLegacyConsole translates the colors of SGR sequences to console text
+// attributes.
+func newLegacyConsole(f *os.File) io.Writer {
+	h := windows.Handle(f.Fd())
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(h, &info); err != nil {
+		return &ansiWriter{w: consoleWriter{f}}
+	}
+	initial := info.Attributes
+	attr := initial
+
+	return &ansiWriter{w: consoleWriter{f}, sgr: func(params []int) {
+		for _, p := range params {
+			switch {
+			case p == 0:
+				attr = initial
+			case p == 1:
+				attr |= fgIntensity
+			case p >= 30 && p <= 37:
+				// ANSI orders the color bits red, green, blue
+				c := uint16(p - 30)
+				attr = attr&^(fgRed|fgGreen|fgBlue) | (c&1)*fgRed | (c>>1&1)*fgGreen | (c>>2&1)*fgBlue
+			}
+		}
+		procSetConsoleTextAttribute.Call(uintptr(h), uintptr(attr))
+	}}
+}
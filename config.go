@@ -0,0 +1,151 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Config describes a logger before it is constructed. It is validated by
+// NewFromConfig and, for the other constructors, built from their arguments
+// and options.
+type Config struct {
+	// Name is the source name used for syslog and the Windows event log,
+	// defaults to the program name.
+	Name string
+	// SystemLog enables logging to syslog (Linux, macOS, FreeBSD) or the
+	// event log (Windows).
+	SystemLog bool
+	// SystemLogRequired makes NewFromConfig fail when the system log can not
+	// be set up, otherwise the error is logged and other outputs are used.
+	SystemLogRequired bool
+	// NoConsole disables writing to stdout and stderr.
+	NoConsole bool
+	// Output is an additional writer, it may be nil.
+	Output io.Writer
+	// Formatter used to render entries, StdFormatter when nil.
+	Formatter Formatter
+	// Level and Flags default to LevelDefault and LstdFlags when zero, so
+	// the zero Config logs like New. LevelFatal and Ldisable are zero as
+	// well, pass WithLevel or WithFlags to NewFromConfig to use them.
+	Level Level
+	Flags int
+}
+
+// ConfigErrors collects all problems found by Config.Validate.
+type ConfigErrors []error
+
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("log: invalid config (%d problems): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Validate checks the config for contradictory options. It returns nil or
+// ConfigErrors describing every problem found.
+func (c Config) Validate() error {
+	var errs ConfigErrors
+	f := c.formatter()
+
+	if c.Level > LevelTrace && c.Level != LevelOff {
+		errs = append(errs, fmt.Errorf("level %d is out of range, use one of LevelFatal..LevelTrace or LevelOff", c.Level))
+	}
+
+	if f.HasFlags() && c.Flags&(Lshortfile|Llongfile) != 0 && f.Flags()&(Lshortfile|Llongfile) == 0 {
+		errs = append(errs, fmt.Errorf("formatter %T overrides flags, Lshortfile/Llongfile will not capture the caller; call SetFlags after construction", f))
+	}
+
+	if c.SystemLog {
+		switch runtime.GOOS {
+		case "linux", "darwin", "freebsd", "windows":
+		default:
+			errs = append(errs, fmt.Errorf("system log is not supported on %s, use an io.Writer output instead", runtime.GOOS))
+		}
+	}
+
+	// escape codes are stripped from the console when it is not a terminal
+	if _, ok := f.(ColorizedStdFormatter); ok && c.Output != nil && !isTerminal(c.Output) {
+		errs = append(errs, fmt.Errorf("colorized formatter writes to %s which is not a terminal, escape codes will be logged as text", writerName(c.Output)))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func (c Config) formatter() Formatter {
+	if c.Formatter == nil {
+		return StdFormatter{}
+	}
+
+	return c.Formatter
+}
+
+// options converts the config into options applied by new.
+func (c Config) options() []LogOption {
+	lvl, flags := c.Level, c.Flags
+	if lvl == LevelFatal {
+		lvl = LevelDefault
+	}
+	if flags == Ldisable {
+		flags = LstdFlags
+	}
+
+	opts := []LogOption{
+		WithFormatter(c.formatter()),
+		WithLevel(lvl),
+		WithFlags(flags),
+	}
+	if c.SystemLog {
+		opts = append(opts, WithSystemLog(c.SystemLogRequired))
+	}
+	if c.NoConsole {
+		opts = append(opts, WithoutStdout())
+	}
+
+	return opts
+}
+
+// NewFromConfig validates the config and creates a logger from it.
+func NewFromConfig(c Config, opts ...LogOption) (Logger, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	l, err := newLogger(c.Name, c.SystemLog, true, c.Output, append(c.options(), opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func writerName(w io.Writer) string {
+	w = sinkOf(w)
+	if f, ok := w.(*os.File); ok {
+		return f.Name()
+	}
+
+	return fmt.Sprintf("%T", w)
+}
@@ -0,0 +1,79 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{Level: LevelDefault, Flags: LstdFlags}.Validate())
+
+	err := Config{
+		Output:    &bytes.Buffer{},
+		Formatter: ColorizedStdFormatter{},
+		Level:     LevelTrace + 1,
+	}.Validate()
+	if assert.Error(t, err) {
+		errs := err.(ConfigErrors)
+		assert.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Error(), "out of range")
+		assert.Contains(t, errs[1].Error(), "*bytes.Buffer")
+	}
+
+	err = Config{Formatter: JsonFormatter{}, Flags: Lshortfile}.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "will not capture the caller")
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	_, err := NewFromConfig(Config{Level: 42})
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	l, err := NewFromConfig(Config{Output: &buf, Level: LevelDebug}, WithFlags(Ldisable))
+	assert.NoError(t, err)
+	l.Debug("config")
+	assert.Equal(t, "DEBUG: config\n", buf.String())
+}
+
+func TestNewFromZeroConfig(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewFromConfig(Config{Output: &buf, NoConsole: true})
+	assert.NoError(t, err)
+	l.Debug("hidden")
+	l.Info("zero config")
+
+	assert.True(t, l.Enabled(LevelInfo))
+	assert.False(t, l.DebugEnabled())
+	assert.Regexp(t, `^INFO : \d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} zero config\n$`, buf.String())
+
+	buf.Reset()
+	l, err = NewFromConfig(Config{Output: &buf, NoConsole: true}, WithLevel(LevelFatal), WithFlags(Ldisable))
+	assert.NoError(t, err)
+	l.Error("hidden")
+	assert.False(t, l.Enabled(LevelError))
+	assert.Empty(t, buf.String())
+}
+
+func TestSystemLogRequired(t *testing.T) {
+	ok, reason := SystemLogAvailable()
+	assert.NotEmpty(t, reason)
+
+	l, err := NewFromConfig(Config{Name: "logtest", SystemLog: true, SystemLogRequired: true, Level: LevelDefault})
+	if ok {
+		assert.NoError(t, err)
+		l.Close()
+	} else {
+		assert.Error(t, err)
+		assert.Nil(t, l)
+
+		var out bytes.Buffer
+		l = New(&out, WithoutStdout(), WithFlags(Ldisable), WithSystemLog(true))
+		l.Info("still logged")
+		assert.Contains(t, out.String(), "ERROR: error=\"log: system log required: ")
+		assert.Contains(t, out.String(), "Failed to set up the system log\nINFO : still logged\n")
+	}
+}
@@ -0,0 +1,302 @@
+package log
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextWithFields returns a context carrying fields, on top of those
+// already in ctx. Loggers add them to entries logged with the *Ctx methods,
+// e.g. a middleware stores the request ID once and handlers call
+// logger.InfoCtx(r.Context(), "order placed").
+func ContextWithFields(ctx context.Context, fields LogFields) context.Context {
+	merged := LogFields{}
+	for k, v := range FieldsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, keyContextFields, merged)
+}
+
+// FieldsFromContext returns the fields stored with ContextWithFields.
+func FieldsFromContext(ctx context.Context) LogFields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(keyContextFields).(LogFields)
+
+	return fields
+}
+
+// ContextExtractor returns fields found in a context, e.g. the IDs of the
+// active span of a tracing system.
+type ContextExtractor func(ctx context.Context) LogFields
+
+// WithContextExtractor adds the fields returned by x for the context of the
+// *Ctx methods and of WithContextFields to every entry, e.g. trace_id and
+// span_id with the extractor of the otel module. Fields stored with
+// ContextWithFields win over extracted ones.
+func WithContextExtractor(x ContextExtractor) LogOption {
+	return func(l *logger) {
+		l.extractors = append(l.extractors, x)
+	}
+}
+
+// contextFields returns the extracted fields and those stored with
+// ContextWithFields.
+func (l *logger) contextFields(ctx context.Context) LogFields {
+	if ctx == nil {
+		return nil
+	}
+
+	stored := FieldsFromContext(ctx)
+	if len(l.extractors) == 0 {
+		return stored
+	}
+
+	fields := LogFields{}
+	for _, x := range l.extractors {
+		for k, v := range x(ctx) {
+			fields[k] = v
+		}
+	}
+	for k, v := range stored {
+		fields[k] = v
+	}
+
+	return fields
+}
+
+type loggerKey struct{}
+
+// IntoContext returns a context carrying l, e.g. a request-scoped logger
+// with the request ID, so functions down the call stack get it with
+// FromContext instead of a Logger parameter.
+func IntoContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored with IntoContext, the default
+// logger when there is none.
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(Logger); ok && l != nil {
+			return l
+		}
+	}
+
+	return defaultLogger
+}
+
+// TraceCtx logs with the Trace severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) TraceCtx(ctx context.Context, v ...interface{}) {
+	if !l.wants(LevelTrace) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelTrace, 0, fmt.Sprint(v...))
+}
+
+// TracefCtx logs with the Trace severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) TracefCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.wants(LevelTrace) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelTrace, 0, fmt.Sprintf(format, v...))
+}
+
+// DebugCtx logs with the Debug severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) DebugCtx(ctx context.Context, v ...interface{}) {
+	if !l.wants(LevelDebug) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelDebug, 0, fmt.Sprint(v...))
+}
+
+// DebugfCtx logs with the Debug severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) DebugfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.wants(LevelDebug) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelDebug, 0, fmt.Sprintf(format, v...))
+}
+
+// InfoCtx logs with the Info severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) InfoCtx(ctx context.Context, v ...interface{}) {
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelInfo, 0, fmt.Sprint(v...))
+}
+
+// InfofCtx logs with the Info severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelInfo, 0, fmt.Sprintf(format, v...))
+}
+
+// WarningCtx logs with the Warning severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) WarningCtx(ctx context.Context, v ...interface{}) {
+	if !l.wants(LevelWarning) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelWarning, 0, fmt.Sprint(v...))
+}
+
+// WarningfCtx logs with the Warning severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) WarningfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.wants(LevelWarning) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelWarning, 0, fmt.Sprintf(format, v...))
+}
+
+// ErrorCtx logs with the Error severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) ErrorCtx(ctx context.Context, v ...interface{}) {
+	if !l.wants(LevelError) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelError, 0, fmt.Sprint(v...))
+}
+
+// ErrorfCtx logs with the Error severity and the fields of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.wants(LevelError) {
+		l.clear()
+		return
+	}
+	l.outputCall(ctx, nil, LevelError, 0, fmt.Sprintf(format, v...))
+}
+
+// TraceCtx uses the default logger and logs with the Trace severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func TraceCtx(ctx context.Context, v ...interface{}) {
+	if !defaultLogger.wants(LevelTrace) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelTrace, 0, fmt.Sprint(v...))
+}
+
+// TracefCtx uses the default logger and logs with the Trace severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func TracefCtx(ctx context.Context, format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelTrace) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelTrace, 0, fmt.Sprintf(format, v...))
+}
+
+// DebugCtx uses the default logger and logs with the Debug severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	if !defaultLogger.wants(LevelDebug) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelDebug, 0, fmt.Sprint(v...))
+}
+
+// DebugfCtx uses the default logger and logs with the Debug severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func DebugfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelDebug) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelDebug, 0, fmt.Sprintf(format, v...))
+}
+
+// InfoCtx uses the default logger and logs with the Info severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelInfo, 0, fmt.Sprint(v...))
+}
+
+// InfofCtx uses the default logger and logs with the Info severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelInfo, 0, fmt.Sprintf(format, v...))
+}
+
+// WarningCtx uses the default logger and logs with the Warning severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func WarningCtx(ctx context.Context, v ...interface{}) {
+	if !defaultLogger.wants(LevelWarning) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelWarning, 0, fmt.Sprint(v...))
+}
+
+// WarningfCtx uses the default logger and logs with the Warning severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func WarningfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelWarning) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelWarning, 0, fmt.Sprintf(format, v...))
+}
+
+// ErrorCtx uses the default logger and logs with the Error severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Print.
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	if !defaultLogger.wants(LevelError) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelError, 0, fmt.Sprint(v...))
+}
+
+// ErrorfCtx uses the default logger and logs with the Error severity and the fields
+// of ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelError) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.outputCall(ctx, nil, LevelError, 0, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtxMethods(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0))
+
+	ctx := ContextWithFields(context.Background(), LogFields{"request": "r1", "user": "ann"})
+	ctx = ContextWithFields(ctx, LogFields{"user": "bob"})
+	assert.Equal(t, LogFields{"request": "r1", "user": "bob"}, FieldsFromContext(ctx))
+
+	l.With(LogFields{"component": "api"}).InfoCtx(ctx, "order placed")
+	l.ErrorfCtx(ctx, "payment %s", "declined")
+	l.DebugCtx(ctx, "hidden")
+	l.Info("plain")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "INFO : component=api request=r1 user=bob order placed", strings.TrimSpace(lines[0]))
+		assert.Equal(t, "ERROR: request=r1 user=bob payment declined", strings.TrimSpace(lines[1]))
+		assert.Equal(t, "INFO : plain", strings.TrimSpace(lines[2]))
+	}
+}
+
+func TestWithContextFieldsUsesContext(t *testing.T) {
+	var out bytes.Buffer
+	ctx := ContextWithFields(context.Background(), LogFields{"request": "r1"})
+	l := New(&out, WithoutStdout(), WithFlags(0)).WithContextFields(ctx, LogFields{"service": "api"})
+	l.Info("started")
+
+	assert.Equal(t, "INFO : request=r1 service=api started", strings.TrimSpace(out.String()))
+}
+
+func TestIntoContext(t *testing.T) {
+	assert.Equal(t, Logger(defaultLogger), FromContext(context.Background()))
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0))
+	ctx := IntoContext(context.Background(), l)
+	FromContext(ctx).Info("from context")
+
+	assert.Same(t, l, FromContext(ctx))
+	assert.Equal(t, "INFO : from context", strings.TrimSpace(out.String()))
+}
+
+type traceKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	extract := func(ctx context.Context) LogFields {
+		if id, ok := ctx.Value(traceKey{}).(string); ok {
+			return LogFields{"trace_id": id, "user": "extracted"}
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithContextExtractor(extract))
+	ctx := ContextWithFields(context.WithValue(context.Background(), traceKey{}, "t1"), LogFields{"user": "ann"})
+	l.InfoCtx(ctx, "traced")
+	l.Info("untraced")
+	l.WithContextFields(context.WithValue(context.Background(), traceKey{}, "t2"), nil).Info("bound")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "INFO : trace_id=t1 user=ann traced", strings.TrimSpace(lines[0]))
+		assert.Equal(t, "INFO : untraced", strings.TrimSpace(lines[1]))
+		assert.Equal(t, "INFO : trace_id=t2 user=extracted bound", strings.TrimSpace(lines[2]))
+	}
+}
+
+func TestCtxMethodsConcurrent(t *testing.T) {
+	var entries []Entry
+	l := New(nil, WithoutStdout(), WithHook(HookFunc(func(e Entry) error {
+		// hooks run with the logger lock held
+		entries = append(entries, e)
+		return nil
+	})))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		req := strconv.Itoa(g)
+		ctx := ContextWithFields(context.Background(), LogFields{"req": req})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.InfoCtx(ctx, req)
+				l.Infow(req, "call", req)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, entries, 1600)
+	for _, e := range entries {
+		if _, ok := e.Fields["call"]; ok {
+			assert.Equal(t, LogFields{"call": e.Message}, e.Fields)
+		} else {
+			assert.Equal(t, LogFields{"req": e.Message}, e.Fields)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// customBase maps levels added with RegisterLevel to the built-in level
+// they rank as.
+var customBase = map[Level]Level{}
+
+// RegisterLevel adds a level to match an existing operational taxonomy,
+// e.g. RegisterLevel(10, "notice", "NOTE : ", 5). value is the Level used
+// with Log, name is printed by formatters and accepted by ParseLevel, tag
+// is the prefix of text output and syslogPriority (0-7) is sent to syslog
+// and journald.
+//
+// The priority also ranks the level among the built-in ones: 0 and 1 as
+// LevelFatal, 2 as LevelPanic, 3 as LevelError, 4 as LevelWarning, 5 and 6
+// as LevelInfo and 7 as LevelDebug. The level is logged when its rank
+// passes the logger level, goes to the writers of its rank and is returned
+// by Level.Severity. Logging a custom level never exits nor panics.
+//
+// Register levels in init functions, before logging starts; RegisterLevel
+// is not safe for concurrent use with logging.
+func RegisterLevel(value uint8, name, tag string, syslogPriority int) error {
+	lvl := Level(value)
+	if _, ok := levelMap[lvl]; ok || lvl == LevelOff {
+		return fmt.Errorf("log: level %d is already registered as %s", value, lvl)
+	}
+	name = strings.ToLower(name)
+	if _, err := ParseLevel(name); err == nil || name == "" {
+		return fmt.Errorf("log: level name %q is taken", name)
+	}
+	if syslogPriority < 0 || syslogPriority > 7 {
+		return fmt.Errorf("log: syslog priority %d of level %s is not between 0 and 7", syslogPriority, name)
+	}
+
+	base := []Level{LevelFatal, LevelFatal, LevelPanic, LevelError, LevelWarning, LevelInfo, LevelInfo, LevelDebug}[syslogPriority]
+	customBase[lvl] = base
+	levelMap[lvl] = name
+	levelTags[lvl] = tag
+	journalPriority[lvl] = fmt.Sprint(syslogPriority)
+	syslogSeverity[lvl] = syslogPriority
+
+	return nil
+}
+
+// Severity returns the built-in level lvl ranks as, lvl itself unless it
+// was added with RegisterLevel. Hooks compare severities, e.g.
+// e.Level.Severity() <= LevelError, to treat custom levels like built-in
+// ones.
+func (lvl Level) Severity() Level {
+	if lvl <= LevelTrace {
+		return lvl
+	}
+	if base, ok := customBase[lvl]; ok {
+		return base
+	}
+
+	return lvl
+}
+
+// Log logs with the level, which may be added with RegisterLevel.
+// Arguments are handled in the manner of fmt.Print. Unlike Fatal and Panic,
+// it does not exit nor panic for LevelFatal and LevelPanic.
+func (l *logger) Log(lvl Level, v ...interface{}) {
+	if !l.wants(lvl) {
+		l.clear()
+		return
+	}
+	l.output(lvl, 0, fmt.Sprint(v...))
+}
+
+// Log uses the default logger, logs with the level.
+// Arguments are handled in the manner of fmt.Print.
+func Log(lvl Level, v ...interface{}) {
+	if !defaultLogger.wants(lvl) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(lvl, 0, fmt.Sprint(v...))
+}
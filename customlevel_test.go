@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterLevel(t *testing.T) {
+	notice, critical := Level(100), Level(101)
+	if _, ok := levelMap[notice]; !ok {
+		assert.NoError(t, RegisterLevel(100, "Notice", "NOTE : ", 5))
+		assert.NoError(t, RegisterLevel(101, "critical", "CRIT : ", 2))
+	}
+	assert.Error(t, RegisterLevel(100, "other", "", 5), "value taken")
+	assert.Error(t, RegisterLevel(102, "warn", "", 4), "name taken")
+	assert.Error(t, RegisterLevel(102, "loud", "", 8), "priority out of range")
+
+	var out, js bytes.Buffer
+	var hooked []Entry
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithLevel(LevelWarning),
+		WithSecondaryOutput(&js, JsonFormatter{}),
+		WithRoute(Route(MatchLevel(LevelError)).To(HookFunc(func(e Entry) error { hooked = append(hooked, e); return nil }))))
+
+	l.Log(notice, "hidden")
+	l.Log(critical, "disk ", "failing")
+	l.SetLevel(LevelInfo)
+	l.Log(notice, "config reloaded")
+	l.Log(LevelWarning, "slow")
+
+	assert.Equal(t, "CRIT : disk failing\nNOTE : config reloaded\nWARN : slow\n", out.String())
+	assert.Contains(t, js.String(), `"level":"critical"`)
+	assert.Len(t, hooked, 1)
+
+	lvl, err := ParseLevel("CRITICAL")
+	assert.NoError(t, err)
+	assert.Equal(t, critical, lvl)
+	assert.Equal(t, LevelPanic, critical.Severity())
+	assert.Equal(t, "notice", notice.String())
+	assert.Equal(t, CLR_C+"NOTE : "+RESET, ColorizedStdFormatter{}.Prefixes()[notice])
+}
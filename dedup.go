@@ -0,0 +1,120 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// dedupRule suppresses repeated entries of a logger returned by Once or
+// Every.
+type dedupRule struct {
+	key    string
+	window time.Duration
+}
+
+// dedupState counts the entries suppressed for a key, it is kept by the
+// logger the rule was derived from.
+type dedupState struct {
+	start      time.Time
+	window     time.Duration
+	lvl        Level
+	msg        string
+	suppressed int
+}
+
+// Once returns a logger logging only the first entry for key during the
+// lifetime of the process, e.g. a deprecation warning:
+//
+//	logger.Once("legacy-config").Warning("config.ini is deprecated, use config.yaml")
+//
+// The number of suppressed entries is logged when the logger is closed.
+func (l *logger) Once(key string) Logger {
+	c := l.derive()
+	c.dedup = &dedupRule{key: key}
+
+	return c
+}
+
+// Every returns a logger logging an entry at most once per window for
+// each level and message, e.g. logger.Every(time.Minute).Info("cache
+// miss"). The first entry after a window with suppressed repeats gets the
+// field repeated with their number. There is no timer: when the message is
+// not logged again, its repeats are logged as an entry of their own by the
+// next call of an Every logger after the window, or when the logger is
+// closed.
+func (l *logger) Every(window time.Duration) Logger {
+	c := l.derive()
+	c.dedup = &dedupRule{window: window}
+
+	return c
+}
+
+// deduplicated reports whether the entry is kept, it is called with logLock
+// held.
+func (l *logger) deduplicated(e *Entry) bool {
+	key := "once " + l.dedup.key
+	if l.dedup.window > 0 {
+		key = fmt.Sprintf("every %v %s %s", l.dedup.window, e.Level, e.Message)
+	}
+
+	t := l.top()
+	if t.dedups == nil {
+		t.dedups = map[string]*dedupState{}
+	}
+	now := clock()
+	st, ok := t.dedups[key]
+	if ok && (st.window == 0 || now.Sub(st.start) < st.window) {
+		st.suppressed++
+		return false
+	}
+	if ok && st.suppressed > 0 {
+		e.Fields = e.Fields.clone()
+		e.Fields["repeated"] = st.suppressed
+	}
+	t.dedups[key] = &dedupState{start: now, window: l.dedup.window, lvl: e.Level, msg: e.Message}
+
+	if l.dedup.window > 0 && now.Sub(t.dedupSwept) >= l.dedup.window {
+		t.dedupSwept = now
+		t.sweepDedups(now)
+	}
+
+	return true
+}
+
+// sweepDedups removes the states of Every whose window ended, so messages
+// which are not logged again don't pile up. Their pending repeats are
+// logged. It is called with logLock held.
+func (l *logger) sweepDedups(now time.Time) {
+	for key, st := range l.dedups {
+		if st.window == 0 || now.Sub(st.start) < st.window {
+			continue
+		}
+		if st.suppressed > 0 && l.passes(st.lvl) {
+			l.emit(Entry{Time: now, Level: st.lvl, Message: st.msg, Fields: LogFields{"repeated": st.suppressed}})
+		}
+		delete(l.dedups, key)
+	}
+}
+
+// flushDedups logs the entries suppressed by Once and Every, with logLock
+// held.
+func (l *logger) flushDedups() {
+	for key, st := range l.dedups {
+		if st.suppressed > 0 && l.passes(st.lvl) {
+			l.emit(Entry{Time: clock(), Level: st.lvl, Message: st.msg, Fields: LogFields{"repeated": st.suppressed}})
+		}
+		delete(l.dedups, key)
+	}
+}
+
+// Once returns a logger of the default logger logging only the first entry
+// for key, see Logger.Once.
+func Once(key string) Logger {
+	return defaultLogger.Once(key)
+}
+
+// Every returns a logger of the default logger logging an entry at most
+// once per window, see Logger.Every.
+func Every(window time.Duration) Logger {
+	return defaultLogger.Every(window)
+}
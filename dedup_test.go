@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnce(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	for i := 0; i < 3; i++ {
+		l.Once("legacy-config").Warning("config.ini is deprecated")
+	}
+	l.Once("other").Warning("other key")
+	l.Warning("not deduplicated")
+	l.Warning("not deduplicated")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"WARN : config.ini is deprecated",
+		"WARN : other key",
+		"WARN : not deduplicated",
+		"WARN : not deduplicated",
+		"WARN : repeated=2 config.ini is deprecated",
+	}, lines)
+}
+
+func TestEvery(t *testing.T) {
+	defer Replay(ReplayConfig{})()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+	every := l.Every(time.Hour)
+
+	every.Info("cache miss")
+	every.Info("cache miss")
+	every.Error("cache miss")
+	every.Info("cache miss")
+	l.(*logger).dedups["every 1h0m0s info cache miss"].start = time.Time{} // window closed
+	every.Info("cache miss")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"INFO : cache miss",
+		"ERROR: cache miss",
+		"INFO : repeated=2 cache miss",
+	}, lines)
+}
+
+func TestEveryEvictsEndedWindows(t *testing.T) {
+	defer Replay(ReplayConfig{})()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+	every := l.Every(time.Hour)
+
+	every.Info("cache miss")
+	every.Info("cache miss")
+	every.Info("once only")
+	lg := l.(*logger)
+	for _, st := range lg.dedups {
+		st.start = time.Time{} // windows closed
+	}
+	lg.dedupSwept = time.Time{}
+	every.Info("next")
+
+	assert.Equal(t, "INFO : cache miss\nINFO : once only\nINFO : repeated=1 cache miss\nINFO : next\n", out.String())
+	assert.Len(t, lg.dedups, 1)
+	assert.Contains(t, lg.dedups, "every 1h0m0s info next")
+}
@@ -0,0 +1,43 @@
+package log
+
+import (
+	"os"
+)
+
+// emergencyMax bounds the line written by Emergency, longer messages are
+// truncated.
+const emergencyMax = 1024
+
+const tagEmergency = "EMERG: "
+
+// WithEmergencyOutput sets the file Emergency writes to, os.Stderr by
+// default. Open it up front, e.g. a file next to the regular log, since
+// nothing can be opened when Emergency is needed.
+func WithEmergencyOutput(f *os.File) LogOption {
+	return func(l *logger) {
+		l.emergencyFd = f.Fd()
+	}
+}
+
+// Emergency writes "EMERG: msg" as a single write to the emergency output
+// and nothing else: it takes no locks, does not allocate, skips formatters,
+// fields, hooks and the level, so it works when the process runs out of
+// memory or another goroutine hangs while holding the logger. Use it for
+// last-gasp diagnostics, e.g. from a signal handling goroutine about to
+// exit.
+func (l *logger) Emergency(msg string) {
+	emergencyWrite(l.emergencyFd, msg)
+}
+
+// Emergency writes msg to the emergency output of the default logger.
+func Emergency(msg string) {
+	emergencyWrite(defaultLogger.emergencyFd, msg)
+}
+
+func emergencyWrite(fd uintptr, msg string) {
+	var buf [emergencyMax]byte
+	n := copy(buf[:], tagEmergency)
+	n += copy(buf[n:len(buf)-1], msg)
+	buf[n] = '\n'
+	writeFd(fd, buf[:n+1])
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmergency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "emergency.log")
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithEmergencyOutput(f))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		l.Emergency("out of memory")
+	})
+	if !raceEnabled {
+		assert.Zero(t, allocs)
+	}
+
+	// works with the logger lock held by a stuck goroutine
+	logLock.Lock()
+	l.Emergency(strings.Repeat("x", 2*emergencyMax))
+	logLock.Unlock()
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(t, lines, 12)
+	assert.Equal(t, "EMERG: out of memory", lines[0])
+	assert.Len(t, lines[11], emergencyMax-1)
+	assert.Empty(t, out.String())
+}
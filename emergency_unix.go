@@ -0,0 +1,13 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"syscall"
+)
+
+// writeFd writes b with a single system call, bypassing os.File.
+func writeFd(fd uintptr, b []byte) {
+	syscall.Write(int(fd), b)
+}
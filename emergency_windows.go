@@ -0,0 +1,10 @@
+package log
+
+import (
+	"syscall"
+)
+
+// writeFd writes b with a single system call, bypassing os.File.
+func writeFd(fd uintptr, b []byte) {
+	syscall.Write(syscall.Handle(fd), b)
+}
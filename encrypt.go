@@ -0,0 +1,164 @@
+package log
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks values encrypted by WithFieldEncryption.
+const encryptedPrefix = "enc:v1:"
+
+// encryptFailed replaces values which could not be encrypted, the plain
+// value is never logged.
+const encryptFailed = "!ENCRYPTFAILED"
+
+// FieldEncryptor is an enricher encrypting the values of selected fields,
+// see WithFieldEncryption.
+type FieldEncryptor struct {
+	keys map[string]bool
+	pub  *rsa.PublicKey
+}
+
+// NewFieldEncryptor creates an enricher encrypting the fields with pub.
+func NewFieldEncryptor(keys []string, pub *rsa.PublicKey) *FieldEncryptor {
+	f := &FieldEncryptor{keys: make(map[string]bool, len(keys)), pub: pub}
+	for _, k := range keys {
+		f.keys[k] = true
+	}
+
+	return f
+}
+
+// WithFieldEncryption encrypts the values of the given fields, also in
+// groups, e.g. user.ssn for ssn, other fields and the message stay
+// searchable. Every value is sealed with a fresh
+// AES-256-GCM key which is encrypted with pub using RSA-OAEP, the result is
+// logged as "enc:v1:<base64>". Holders of the private key read values with
+// DecryptField.
+func WithFieldEncryption(keys []string, pub *rsa.PublicKey) LogOption {
+	return WithEnricher(NewFieldEncryptor(keys, pub))
+}
+
+// Enrich replaces the selected field values with their encrypted form.
+func (f *FieldEncryptor) Enrich(e *Entry) {
+	for k, v := range e.Fields {
+		if _, _, ok := ungroup(k, f.has); !ok {
+			continue
+		}
+
+		enc, err := f.encrypt(v)
+		if err != nil {
+			enc = encryptFailed
+		}
+		e.Fields[k] = enc
+	}
+}
+
+func (f *FieldEncryptor) has(key string) bool {
+	return f.keys[key]
+}
+
+// encrypt seals the JSON encoding of v, so DecryptField returns the value
+// with its type. The payload is the length of the wrapped key, the wrapped
+// key, the nonce and the sealed value.
+func (f *FieldEncryptor) encrypt(v interface{}) (string, error) {
+	plain, err := json.Marshal(jsonValue(v))
+	if err != nil {
+		return "", err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, f.pub, key, nil)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 2, 2+len(wrapped)+len(nonce)+len(plain)+gcm.Overhead())
+	binary.BigEndian.PutUint16(b, uint16(len(wrapped)))
+	b = append(b, wrapped...)
+	b = append(b, nonce...)
+	b = gcm.Seal(b, nonce, plain, nil)
+
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// jsonValue keeps errors readable, encoding/json renders them as {}.
+func jsonValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+
+	return v
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// DecryptField decrypts a value logged by WithFieldEncryption. Numbers are
+// returned as float64 and objects as maps, as by encoding/json.
+func DecryptField(priv *rsa.PrivateKey, value string) (interface{}, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return nil, fmt.Errorf("log: value is not encrypted, missing %q prefix", encryptedPrefix)
+	}
+	b, err := base64.StdEncoding.DecodeString(value[len(encryptedPrefix):])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 2 {
+		return nil, errors.New("log: encrypted value is truncated")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return nil, errors.New("log: encrypted value is truncated")
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, b[2:2+n], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := b[2+n:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, errors.New("log: encrypted value is truncated")
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(plain, &v)
+
+	return v, err
+}
@@ -0,0 +1,39 @@
+package log
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldEncryption(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	l := New(&out, WithFormatter(JsonFormatter{}), WithFieldEncryption([]string{"email", "age"}, &priv.PublicKey))
+	l.With(LogFields{"email": "ann@example.com", "age": 42, "country": "PL"}).Info("signup")
+
+	var logged map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &logged))
+	assert.Equal(t, "PL", logged["country"])
+	assert.Equal(t, "signup", logged["msg"])
+	assert.NotContains(t, out.String(), "ann@example.com")
+
+	email, err := DecryptField(priv, logged["email"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "ann@example.com", email)
+
+	age, err := DecryptField(priv, logged["age"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(42), age)
+
+	_, err = DecryptField(priv, "ann@example.com")
+	assert.Error(t, err)
+}
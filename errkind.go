@@ -0,0 +1,220 @@
+package log
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+)
+
+// Error kinds returned by ErrKind, stable across services so dashboards can
+// aggregate failures by class.
+const (
+	KindTimeout    = "timeout"
+	KindNotFound   = "not_found"
+	KindPermission = "permission"
+	KindConflict   = "conflict"
+	KindInternal   = "internal"
+)
+
+// ErrorKindField is the field key set by ErrFields.
+const ErrorKindField = "error.kind"
+
+// ErrKind classifies err, it returns an empty string for a nil error. An
+// error in the chain can declare its kind with an ErrorKind() string method,
+// otherwise the kind is derived from well known errors, Timeout() methods,
+// gRPC status codes and HTTP status codes of StatusCode() methods. Errors
+// not recognized are KindInternal.
+func ErrKind(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var kinded interface{ ErrorKind() string }
+	if errors.As(err, &kinded) {
+		return kinded.ErrorKind()
+	}
+
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return KindTimeout
+	case errors.As(err, &timeout) && timeout.Timeout():
+		return KindTimeout
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, sql.ErrNoRows):
+		return KindNotFound
+	case errors.Is(err, os.ErrPermission):
+		return KindPermission
+	case errors.Is(err, os.ErrExist):
+		return KindConflict
+	}
+
+	if code, ok := grpcCode(err); ok {
+		return GRPCCodeKind(code)
+	}
+
+	var status interface{ StatusCode() int }
+	if errors.As(err, &status) {
+		return HTTPStatusKind(status.StatusCode())
+	}
+
+	return KindInternal
+}
+
+// ErrFields returns the error and error.kind fields of err, e.g.
+// l.With(log.ErrFields(err)).Error("Failed to load profile").
+func ErrFields(err error) LogFields {
+	if err == nil {
+		return LogFields{}
+	}
+
+	return LogFields{"error": err.Error(), ErrorKindField: ErrKind(err)}
+}
+
+// StackTracer is implemented by errors carrying the stack they were created
+// with. Errors of github.com/pkg/errors are recognized as well, their
+// StackTrace method returns program counters.
+type StackTracer interface {
+	StackTrace() Stacktrace
+}
+
+// WithError returns a logger adding the fields of err to its entries, see
+// ErrorFields. A nil err adds no fields.
+func (l *logger) WithError(err error) Logger {
+	return l.with(ErrorFields(err))
+}
+
+// WithError returns a logger of the default logger adding the fields of
+// err, see ErrorFields.
+func WithError(err error) Logger {
+	return defaultLogger.with(ErrorFields(err))
+}
+
+// ErrorFields returns the standard fields of err:
+//
+//   - error, err itself, rendered as its message and picked up by
+//     ErrorReporter hooks
+//   - error_type, the type of err, e.g. *fs.PathError
+//   - error_cause, the messages of the errors err wraps, found with
+//     repeated errors.Unwrap, when it wraps any
+//   - error_stack, the stack of the innermost error implementing
+//     StackTracer, when there is one
+func ErrorFields(err error) LogFields {
+	if err == nil {
+		return LogFields{}
+	}
+
+	kv := []interface{}{"error", err, "error_type", fmt.Sprintf("%T", err)}
+	var causes []string
+	stack := errorStack(err)
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+		if st := errorStack(cause); st != nil {
+			stack = st
+		}
+	}
+	if causes != nil {
+		kv = append(kv, "error_cause", causes)
+	}
+	if stack != nil {
+		kv = append(kv, "error_stack", stack)
+	}
+
+	return Ordered(kv...)
+}
+
+// errorStack returns the stack carried by err itself, not the errors it
+// wraps.
+func errorStack(err error) Stacktrace {
+	if st, ok := err.(StackTracer); ok {
+		return st.StackTrace()
+	}
+
+	// github.com/pkg/errors returns a slice of program counters
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if out := m.Type().Out(0); out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	if frames.Len() == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		// pkg/errors frames are return addresses, as runtime.Callers reports
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+
+	var st Stacktrace
+	it := runtime.CallersFrames(pcs)
+	for {
+		frame, more := it.Next()
+		st = append(st, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+
+	return st
+}
+
+// GRPCCodeKind maps a gRPC status code to an error kind.
+func GRPCCodeKind(code uint32) string {
+	switch code {
+	case 4: // DeadlineExceeded
+		return KindTimeout
+	case 5: // NotFound
+		return KindNotFound
+	case 7, 16: // PermissionDenied, Unauthenticated
+		return KindPermission
+	case 6, 10: // AlreadyExists, Aborted
+		return KindConflict
+	}
+
+	return KindInternal
+}
+
+// HTTPStatusKind maps an HTTP status code to an error kind.
+func HTTPStatusKind(status int) string {
+	switch status {
+	case 408, 504:
+		return KindTimeout
+	case 404, 410:
+		return KindNotFound
+	case 401, 403:
+		return KindPermission
+	case 409, 412:
+		return KindConflict
+	}
+
+	return KindInternal
+}
+
+// grpcCode returns the code of an error in the chain with a GRPCStatus
+// method, as returned by google.golang.org/grpc/status, without depending on
+// the grpc module.
+func grpcCode(err error) (uint32, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		m := reflect.ValueOf(err).MethodByName("GRPCStatus")
+		if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+			continue
+		}
+		st := m.Call(nil)[0]
+		if st.Kind() == reflect.Ptr && st.IsNil() {
+			continue
+		}
+		code := st.MethodByName("Code")
+		if !code.IsValid() || code.Type().NumIn() != 0 || code.Type().NumOut() != 1 || code.Type().Out(0).Kind() != reflect.Uint32 {
+			continue
+		}
+		return uint32(code.Call(nil)[0].Uint()), true
+	}
+
+	return 0, false
+}
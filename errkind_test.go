@@ -0,0 +1,97 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type grpcCodeType uint32
+
+type grpcStatus struct{ code grpcCodeType }
+
+func (s *grpcStatus) Code() grpcCodeType { return s.code }
+
+type grpcError struct{ st *grpcStatus }
+
+func (e grpcError) Error() string           { return "rpc error" }
+func (e grpcError) GRPCStatus() *grpcStatus { return e.st }
+
+type httpError int
+
+func (e httpError) Error() string   { return fmt.Sprintf("http status %d", int(e)) }
+func (e httpError) StatusCode() int { return int(e) }
+
+type quotaError struct{}
+
+func (quotaError) Error() string     { return "quota exceeded" }
+func (quotaError) ErrorKind() string { return "quota" }
+
+func TestErrKind(t *testing.T) {
+	_, statErr := os.Stat("/nonexistent/file")
+
+	for err, kind := range map[error]string{
+		nil:                                    "",
+		context.DeadlineExceeded:               KindTimeout,
+		fmt.Errorf("query: %w", sql.ErrNoRows): KindNotFound,
+		statErr:                                KindNotFound,
+		os.ErrPermission:                       KindPermission,
+		os.ErrExist:                            KindConflict,
+		grpcError{&grpcStatus{5}}:              KindNotFound,
+		fmt.Errorf("call: %w", grpcError{&grpcStatus{16}}): KindPermission,
+		httpError(409):     KindConflict,
+		httpError(502):     KindInternal,
+		quotaError{}:       "quota",
+		errors.New("boom"): KindInternal,
+		context.Canceled:   KindInternal,
+	} {
+		assert.Equal(t, kind, ErrKind(err), "%v", err)
+	}
+
+	assert.Equal(t, LogFields{"error": "http status 404", "error.kind": KindNotFound}, ErrFields(httpError(404)))
+}
+
+// pcError carries program counters like the errors of github.com/pkg/errors.
+type pcError struct{ pcs []pcFrame }
+
+type pcFrame uintptr
+
+func (e pcError) Error() string { return "pc error" }
+
+func (e pcError) StackTrace() []pcFrame { return e.pcs }
+
+func newPCError() error {
+	pcs := make([]uintptr, 4)
+	n := runtime.Callers(1, pcs)
+	frames := make([]pcFrame, n)
+	for i := range frames {
+		frames[i] = pcFrame(pcs[i])
+	}
+	return pcError{frames}
+}
+
+func TestWithError(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	err := fmt.Errorf("load config: %w", fmt.Errorf("read: %w", os.ErrNotExist))
+	l.WithError(err).Error("Failed to start")
+	l.WithError(nil).Info("no error")
+
+	assert.Equal(t, `ERROR: error="load config: read: file does not exist" error_type=*fmt.wrapError `+
+		`error_cause="[read: file does not exist file does not exist]" Failed to start`+"\n"+
+		"INFO : no error\n", out.String())
+	assert.Equal(t, err, ErrorFields(err)["error"])
+
+	fields := ErrorFields(fmt.Errorf("wrapped: %w", newPCError()))
+	st := fields["error_stack"].(Stacktrace)
+	assert.Equal(t, "github.com/bialas1993/log.newPCError", st[0].Function)
+	assert.Equal(t, []string{"pc error"}, fields["error_cause"])
+}
@@ -0,0 +1,181 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// EscapeProfile selects how EscapingFormatter escapes message text and field
+// values for the place the output ends up in.
+type EscapeProfile int
+
+const (
+	// EscapeJSON makes text safe inside a JSON string, escaping quotes,
+	// backslashes, control characters, U+2028, U+2029 and <, > and & as
+	// encoding/json does.
+	EscapeJSON EscapeProfile = iota
+	// EscapeShell makes text safe inside a double-quoted shell string and on
+	// a terminal, escaping ", $, `, \ and all control characters, so
+	// entries can't run commands or send terminal escape sequences.
+	EscapeShell
+	// EscapeHTML makes text safe in HTML element content and attribute
+	// values, escaping &, <, >, " and ' as entities and control characters
+	// as visible text.
+	EscapeHTML
+)
+
+// Escape escapes s for the profile. In all profiles invalid UTF-8 is
+// replaced by U+FFFD, and control characters and Unicode bidirectional
+// formatting characters, which can make text display differently than it
+// reads, are written as visible escapes like \u001b or \u202e.
+func Escape(p EscapeProfile, s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x7f || escapedASCII(p, c) {
+			return string(appendEscaped(make([]byte, 0, len(s)+16), p, s))
+		}
+	}
+
+	return s
+}
+
+func escapedASCII(p EscapeProfile, c byte) bool {
+	switch p {
+	case EscapeJSON:
+		return c == '"' || c == '\\' || c == '<' || c == '>' || c == '&'
+	case EscapeShell:
+		return c == '"' || c == '\\' || c == '$' || c == '`'
+	}
+
+	return c == '&' || c == '<' || c == '>' || c == '"' || c == '\''
+}
+
+func appendEscaped(b []byte, p EscapeProfile, s string) []byte {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+
+		switch {
+		case r < utf8.RuneSelf && escapedASCII(p, byte(r)):
+			b = appendEscapedASCII(b, p, byte(r))
+		case r == utf8.RuneError && size == 1:
+			b = append(b, "\uFFFD"...)
+		case r == '\t' && p == EscapeHTML, r == '\n' && p == EscapeHTML:
+			b = append(b, byte(r))
+		case isControl(r) && p == EscapeJSON:
+			b = appendJSONControl(b, r)
+		case isControl(r):
+			b = append(b, escapeCode(r)...)
+		default:
+			b = append(b, s[i-size:i]...)
+		}
+	}
+
+	return b
+}
+
+func appendEscapedASCII(b []byte, p EscapeProfile, c byte) []byte {
+	switch p {
+	case EscapeJSON:
+		if c == '"' || c == '\\' {
+			return append(b, '\\', c)
+		}
+		return append(b, escapeCode(rune(c))...)
+	case EscapeShell:
+		return append(b, '\\', c)
+	}
+
+	switch c {
+	case '&':
+		return append(b, "&amp;"...)
+	case '<':
+		return append(b, "&lt;"...)
+	case '>':
+		return append(b, "&gt;"...)
+	case '"':
+		return append(b, "&#34;"...)
+	}
+	return append(b, "&#39;"...)
+}
+
+func appendJSONControl(b []byte, r rune) []byte {
+	switch r {
+	case '\n':
+		return append(b, `\n`...)
+	case '\r':
+		return append(b, `\r`...)
+	case '\t':
+		return append(b, `\t`...)
+	}
+	return append(b, escapeCode(r)...)
+}
+
+// escapeCode returns r as \uNNNN, which is valid JSON and visible text
+// elsewhere.
+func escapeCode(r rune) string {
+	hex := strconv.FormatInt(int64(r), 16)
+	for len(hex) < 4 {
+		hex = "0" + hex
+	}
+
+	return `\u` + hex
+}
+
+// isControl reports whether r is a C0 or C1 control character, DEL, a line
+// or paragraph separator or a bidirectional formatting character.
+func isControl(r rune) bool {
+	switch {
+	case r < 0x20, r >= 0x7f && r <= 0x9f:
+		return true
+	case r == 0x061c, r == 0x200e, r == 0x200f, r == 0x2028, r == 0x2029:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+
+	return false
+}
+
+// EscapingFormatter escapes the message and the string, error and
+// fmt.Stringer field values with its profile before rendering the entry with
+// the embedded formatter. Use it per sink, e.g. for entries shown on an HTML
+// admin page:
+//
+//	log.WithSecondaryOutput(page, log.EscapingFormatter{Formatter: log.StdFormatter{}, Profile: log.EscapeHTML})
+type EscapingFormatter struct {
+	Formatter
+	Profile EscapeProfile
+}
+
+// Output renders the entry with escaped text.
+func (f EscapingFormatter) Output(flags int, lvl string, fields LogFields, msg string) string {
+	fields, order := fields.unordered()
+	escaped := make(LogFields, len(fields)+1)
+	for k, v := range fields {
+		escaped[Escape(f.Profile, k)] = f.escapeValue(v)
+	}
+	if order != nil {
+		keys := make([]string, len(order))
+		for i, k := range order {
+			keys[i] = Escape(f.Profile, k)
+		}
+		escaped[fieldOrderKey] = keys
+	}
+
+	return f.Formatter.Output(flags, lvl, escaped, Escape(f.Profile, msg))
+}
+
+func (f EscapingFormatter) escapeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return Escape(f.Profile, v)
+	case []byte:
+		return Escape(f.Profile, string(v))
+	case error:
+		return Escape(f.Profile, v.Error())
+	case fmt.Stringer:
+		return Escape(f.Profile, v.String())
+	}
+
+	return v
+}
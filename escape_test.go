@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		profile  EscapeProfile
+		in, want string
+	}{
+		{EscapeJSON, `say "hi" \ bye`, `say \"hi\" \\ bye`},
+		{EscapeJSON, "line\nbreak\ttab", `line\nbreak\ttab`},
+		{EscapeJSON, "<b>&", `\u003cb\u003e\u0026`},
+		{EscapeJSON, "a\u2028b", `a\u2028b`},
+		{EscapeShell, "$(rm -rf /) `id` \"x\"", "\\$(rm -rf /) \\`id\\` \\\"x\\\""},
+		{EscapeShell, "\x1b[2Jcleared", `\u001b[2Jcleared`},
+		{EscapeShell, "8-bit \u009b2J csi", `8-bit \u009b2J csi`},
+		{EscapeShell, "line\nbreak", `line\u000abreak`},
+		{EscapeHTML, `<img src=x onerror='alert(1)'>`, `&lt;img src=x onerror=&#39;alert(1)&#39;&gt;`},
+		{EscapeHTML, "a & \"b\"\n", "a &amp; &#34;b&#34;\n"},
+		// bidi overrides reorder what is displayed, e.g. "txt.exe" shown as "exe.txt"
+		{EscapeHTML, "invoice\u202etxt.exe", `invoice\u202etxt.exe`},
+		{EscapeShell, "\u2066isolate\u2069", `\u2066isolate\u2069`},
+		// invalid UTF-8 and a truncated sequence
+		{EscapeJSON, "bad \xff byte \xe2\x82", "bad \uFFFD byte \uFFFD\uFFFD"},
+		// printable non-ASCII text, combining marks and emoji are kept
+		{EscapeHTML, "Zo\u00eb, e\u0301, \u65e5\u672c, \U0001f469\u200d\U0001f4bb", "Zo\u00eb, e\u0301, \u65e5\u672c, \U0001f469\u200d\U0001f4bb"},
+		{EscapeShell, "plain text", "plain text"},
+	} {
+		assert.Equal(t, tc.want, Escape(tc.profile, tc.in), "%q", tc.in)
+	}
+}
+
+func TestEscapeJSONValid(t *testing.T) {
+	in := "q\" \\ \x00 \x7f \u0085 \u2028 \u202e \xc0"
+	var out string
+	assert.NoError(t, json.Unmarshal([]byte(`"`+Escape(EscapeJSON, in)+`"`), &out))
+	assert.Equal(t, "q\" \\ \x00 \x7f \u0085 \u2028 \u202e \uFFFD", out)
+}
+
+func TestEscapingFormatter(t *testing.T) {
+	var page bytes.Buffer
+	l := New(nil, WithoutStdout(), WithFlags(0),
+		WithSecondaryOutput(&page, EscapingFormatter{Formatter: StdFormatter{}, Profile: EscapeHTML}))
+	l.With(LogFields{"user": "<script>", "err": errors.New("a<b"), "n": 3}).Info("login <b>failed</b>")
+	l.Close()
+
+	out := page.String()
+	assert.Contains(t, out, "login &lt;b&gt;failed&lt;/b&gt;")
+	assert.Contains(t, out, "user=&lt;script&gt;")
+	assert.Contains(t, out, "err=a&lt;b")
+	assert.Contains(t, out, "n=3")
+	assert.NotContains(t, out, "<")
+}
@@ -0,0 +1,160 @@
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// EventLogQuery selects the event log records read by ReadEventLog.
+type EventLogQuery struct {
+	// Source is the name the records were written with, the name passed
+	// to NewSyslogLogger.
+	Source string
+	// Since skips older records, all records are read when it is zero.
+	Since time.Time
+	// Max limits the number of records to the most recent ones, 0 reads
+	// all.
+	Max int
+}
+
+// ReadEventLog returns the records of the Windows Application event log
+// written by q.Source, oldest first. The message of an entry is the text
+// written by the logger, its level follows the event type and its fields
+// are event_id, record, source and computer. On other systems it returns
+// an error.
+//
+// Together with ExportNDJSON or Reemit it bridges hosts logging only to the
+// event log into file or HTTP based collection:
+//
+//	entries, err := log.ReadEventLog(log.EventLogQuery{Source: "billing", Since: lastRun})
+//	...
+//	err = log.Reemit(entries, log.NewLokiSink(cfg))
+func ReadEventLog(q EventLogQuery) ([]Entry, error) {
+	if q.Source == "" {
+		return nil, errors.New("event log: no source")
+	}
+
+	return readEventLog(q)
+}
+
+// ExportNDJSON writes entries to w as NDJSON, an object with time, level,
+// msg and the fields per line, the format read by AdoptChild.
+func ExportNDJSON(w io.Writer, entries []Entry) error {
+	var b []byte
+	for _, e := range entries {
+		var err error
+		if b, err = appendEntryJSON(b[:0], e); err != nil {
+			return err
+		}
+		if _, err = w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reemit passes entries to the sinks in order, e.g. a HTTPSink. It returns
+// the errors of all sinks and entries.
+func Reemit(entries []Entry, sinks ...Hook) error {
+	var errs []string
+	for _, e := range entries {
+		for _, s := range sinks {
+			if err := s.Fire(e); err != nil {
+				errs = append(errs, fmt.Sprintf("%T: %v", s, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("reemit: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// eventRecordSize is the size of the fixed part of EVENTLOGRECORD.
+const eventRecordSize = 56
+
+// eventRecordLevels maps the EVENTLOG_*_TYPE values to levels.
+var eventRecordLevels = map[uint16]Level{
+	0x0001: LevelError,
+	0x0002: LevelWarning,
+	0x0004: LevelInfo,
+}
+
+// eventRecord is a parsed EVENTLOGRECORD.
+type eventRecord struct {
+	Entry
+	source string
+}
+
+// parseEventRecords parses the EVENTLOGRECORD structures returned by
+// ReadEventLogW.
+func parseEventRecords(b []byte) ([]eventRecord, error) {
+	var records []eventRecord
+	for len(b) > 0 {
+		if len(b) < eventRecordSize {
+			return records, errors.New("event log: truncated record")
+		}
+		le := binary.LittleEndian
+		length := int(le.Uint32(b))
+		if length < eventRecordSize || length > len(b) {
+			return records, fmt.Errorf("event log: invalid record length %d", length)
+		}
+		rec := b[:length]
+		b = b[length:]
+
+		source, next := utf16String(rec, eventRecordSize)
+		computer, _ := utf16String(rec, next)
+
+		var msg []string
+		offset := int(le.Uint32(rec[36:]))
+		for i := 0; i < int(le.Uint16(rec[26:])); i++ {
+			var s string
+			s, offset = utf16String(rec, offset)
+			msg = append(msg, s)
+		}
+
+		lvl, ok := eventRecordLevels[le.Uint16(rec[24:])]
+		if !ok {
+			lvl = LevelInfo
+		}
+		records = append(records, eventRecord{
+			Entry: Entry{
+				Time:    time.Unix(int64(le.Uint32(rec[12:])), 0),
+				Level:   lvl,
+				Message: strings.TrimRight(strings.Join(msg, " "), "\r\n"),
+				Fields: LogFields{
+					"event_id": le.Uint32(rec[20:]) & 0xffff,
+					"record":   le.Uint32(rec[8:]),
+					"source":   source,
+					"computer": computer,
+				},
+			},
+			source: source,
+		})
+	}
+
+	return records, nil
+}
+
+// utf16String reads a NUL terminated UTF-16 string at offset and returns
+// it with the offset following it.
+func utf16String(b []byte, offset int) (string, int) {
+	var s []uint16
+	for offset >= 0 && offset+1 < len(b) {
+		c := binary.LittleEndian.Uint16(b[offset:])
+		offset += 2
+		if c == 0 {
+			break
+		}
+		s = append(s, c)
+	}
+
+	return string(utf16.Decode(s)), offset
+}
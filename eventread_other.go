@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func readEventLog(q EventLogQuery) ([]Entry, error) {
+	return nil, fmt.Errorf("event log is not supported on %s", runtime.GOOS)
+}
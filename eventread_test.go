@@ -0,0 +1,98 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRecordBytes builds an EVENTLOGRECORD as returned by ReadEventLogW.
+func eventRecordBytes(record, eventID uint32, typ uint16, at time.Time, source, computer string, strs ...string) []byte {
+	utf16z := func(s string) []byte {
+		var b []byte
+		for _, c := range append(utf16.Encode([]rune(s)), 0) {
+			b = append(b, byte(c), byte(c>>8))
+		}
+		return b
+	}
+
+	names := append(utf16z(source), utf16z(computer)...)
+	var text []byte
+	for _, s := range strs {
+		text = append(text, utf16z(s)...)
+	}
+	length := eventRecordSize + len(names) + len(text) + 4
+
+	b := make([]byte, eventRecordSize, length)
+	le := binary.LittleEndian
+	le.PutUint32(b[0:], uint32(length))
+	le.PutUint32(b[8:], record)
+	le.PutUint32(b[12:], uint32(at.Unix()))
+	le.PutUint32(b[16:], uint32(at.Unix()))
+	le.PutUint32(b[20:], eventID)
+	le.PutUint16(b[24:], typ)
+	le.PutUint16(b[26:], uint16(len(strs)))
+	le.PutUint32(b[36:], uint32(eventRecordSize+len(names)))
+	b = append(append(b, names...), text...)
+
+	return append(b, 0, 0, 0, 0)
+}
+
+func TestParseEventRecords(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	buf := append(eventRecordBytes(7, 2, 0x0001, at, "billing", "HOST-1", "ERROR: payment failed\r\n"),
+		eventRecordBytes(8, 1, 0x0004, at.Add(time.Second), "other", "HOST-1", "started")...)
+
+	records, err := parseEventRecords(buf)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "billing", records[0].source)
+		assert.Equal(t, LevelError, records[0].Level)
+		assert.Equal(t, "ERROR: payment failed", records[0].Message)
+		assert.True(t, at.Equal(records[0].Time))
+		assert.Equal(t, LogFields{"event_id": uint32(2), "record": uint32(7), "source": "billing", "computer": "HOST-1"}, records[0].Fields)
+		assert.Equal(t, LevelInfo, records[1].Level)
+	}
+
+	_, err = parseEventRecords(buf[:eventRecordSize+4])
+	assert.Error(t, err)
+}
+
+func TestExportNDJSON(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: at, Level: LevelWarning, Message: "disk low", Fields: LogFields{"record": 1}},
+		{Time: at, Level: LevelInfo, Message: "ok"},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, ExportNDJSON(&out, entries))
+	assert.Equal(t, `{"time":"2024-03-01T10:00:00Z","level":"warning","msg":"disk low","record":1}`+"\n"+
+		`{"time":"2024-03-01T10:00:00Z","level":"info","msg":"ok"}`+"\n", out.String())
+
+	lvl, msg, fields := parseChildEntry(`{"level":"warning","msg":"disk low","record":1}`)
+	assert.Equal(t, LevelWarning, lvl)
+	assert.Equal(t, "disk low", msg)
+	assert.Equal(t, LogFields{"record": float64(1)}, fields)
+}
+
+func TestReemit(t *testing.T) {
+	var got []string
+	sink := HookFunc(func(e Entry) error {
+		got = append(got, e.Message)
+		return nil
+	})
+	failing := HookFunc(func(e Entry) error { return errors.New("offline") })
+
+	err := Reemit([]Entry{{Message: "a"}, {Message: "b"}}, sink, failing)
+	assert.Equal(t, []string{"a", "b"}, got)
+	assert.EqualError(t, err, "reemit: log.HookFunc: offline; log.HookFunc: offline")
+
+	_, err = ReadEventLog(EventLogQuery{})
+	assert.EqualError(t, err, "event log: no source")
+}
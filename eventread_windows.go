@@ -0,0 +1,77 @@
+package log
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32          = windows.NewLazySystemDLL("advapi32.dll")
+	procOpenEventLog  = advapi32.NewProc("OpenEventLogW")
+	procReadEventLog  = advapi32.NewProc("ReadEventLogW")
+	procCloseEventLog = advapi32.NewProc("CloseEventLog")
+)
+
+const (
+	eventLogSequentialRead = 0x0001
+	eventLogBackwardsRead  = 0x0008
+)
+
+// readEventLog reads the Application log from the newest record backwards
+// until a record older than q.Since or q.Max records of the source.
+func readEventLog(q EventLogQuery) ([]Entry, error) {
+	name, err := windows.UTF16PtrFromString("Application")
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := procOpenEventLog.Call(0, uintptr(unsafe.Pointer(name)))
+	if h == 0 {
+		return nil, err
+	}
+	defer procCloseEventLog.Call(h)
+
+	var entries []Entry
+	buf := make([]byte, 64*1024)
+	for {
+		var read, needed uint32
+		r, _, err := procReadEventLog.Call(h, eventLogSequentialRead|eventLogBackwardsRead, 0,
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)),
+			uintptr(unsafe.Pointer(&read)), uintptr(unsafe.Pointer(&needed)))
+		if r == 0 {
+			switch err {
+			case windows.ERROR_HANDLE_EOF:
+				return reverseEntries(entries), nil
+			case windows.ERROR_INSUFFICIENT_BUFFER:
+				buf = make([]byte, needed)
+				continue
+			}
+			return nil, err
+		}
+
+		records, err := parseEventRecords(buf[:read])
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			if !q.Since.IsZero() && rec.Time.Before(q.Since) {
+				return reverseEntries(entries), nil
+			}
+			if rec.source != q.Source {
+				continue
+			}
+			entries = append(entries, rec.Entry)
+			if q.Max > 0 && len(entries) == q.Max {
+				return reverseEntries(entries), nil
+			}
+		}
+	}
+}
+
+func reverseEntries(entries []Entry) []Entry {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/bialas1993/log"
 )
 
-func ExampleLogsLevel() {
+func Example_logsLevel() {
 	os.Stderr = os.Stdout
 	l := log.New(nil)
 	l.SetFlags(log.Ldisable)
@@ -34,7 +34,7 @@ func ExampleLogsLevel() {
 	// INFO : infof
 }
 
-func ExampleLogsLevelWithContext() {
+func Example_logsLevelWithContext() {
 	os.Stderr = os.Stdout
 	l := log.New(nil).WithContextFields(context.Background(), log.LogFields{
 		"_context": "set",
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// fanout writes to all its writers. Unlike io.MultiWriter it does not stop
+// at the first failing writer, e.g. a broken syslog connection does not
+// stop writing to the log file.
+type fanout struct {
+	writers []io.Writer
+	// onError is called for every failed write, when nil the first failure
+	// of a writer and its recovery are reported to stderr.
+	onError func(w io.Writer, err error)
+	failing []bool
+	// latency records the duration of writes under the writer names.
+	latency *LatencyMonitor
+	names   []string
+}
+
+func newFanout(onError func(io.Writer, error), latency *LatencyMonitor, writers ...io.Writer) *fanout {
+	f := &fanout{writers: writers, onError: onError, failing: make([]bool, len(writers)), latency: latency}
+	if latency != nil {
+		f.names = make([]string, len(writers))
+		for i, w := range writers {
+			f.names[i] = writerName(w)
+		}
+	}
+
+	return f
+}
+
+// with returns a fanout writing to w as well.
+func (f *fanout) with(w io.Writer) *fanout {
+	return newFanout(f.onError, f.latency, append(f.writers[:len(f.writers):len(f.writers)], w)...)
+}
+
+// Write writes p to every writer. It never fails, errors of the writers
+// are reported to the error handler.
+func (f *fanout) Write(p []byte) (int, error) {
+	for i, w := range f.writers {
+		start := f.start()
+		n, err := w.Write(p)
+		f.observe(i, start)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		f.result(i, err)
+	}
+
+	return len(p), nil
+}
+
+// WriteString avoids converting s for writers implementing
+// io.StringWriter, as io.MultiWriter does.
+func (f *fanout) WriteString(s string) (int, error) {
+	var p []byte
+	for i, w := range f.writers {
+		var n int
+		var err error
+		start := f.start()
+		if sw, ok := w.(io.StringWriter); ok {
+			n, err = sw.WriteString(s)
+		} else {
+			if p == nil {
+				p = []byte(s)
+			}
+			n, err = w.Write(p)
+		}
+		f.observe(i, start)
+		if err == nil && n < len(s) {
+			err = io.ErrShortWrite
+		}
+		f.result(i, err)
+	}
+
+	return len(s), nil
+}
+
+// start returns the start time of a write, zero when latency is not
+// recorded.
+func (f *fanout) start() time.Time {
+	if f.latency == nil {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+func (f *fanout) observe(i int, start time.Time) {
+	if f.latency != nil {
+		f.latency.observe(f.names[i], time.Since(start))
+	}
+}
+
+func (f *fanout) result(i int, err error) {
+	if f.onError != nil {
+		if err != nil {
+			f.onError(sinkOf(f.writers[i]), err)
+		}
+		return
+	}
+
+	switch {
+	case err != nil && !f.failing[i]:
+		fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to write log to %T: %v\n", sinkOf(f.writers[i]), err)
+	case err == nil && f.failing[i]:
+		fmt.Fprintf(consoleWriter{os.Stderr}, "Writing log to %T works again\n", sinkOf(f.writers[i]))
+	}
+	f.failing[i] = err != nil
+}
+
+// WithWriteErrorHandler calls fn for every failed write to one of the
+// writers of the logger, e.g. to count them or to alert. The other writers
+// are written regardless. By default the first failure of a writer and its
+// recovery are reported to stderr.
+func WithWriteErrorHandler(fn func(w io.Writer, err error)) LogOption {
+	return func(l *logger) {
+		l.onWriteErr = fn
+	}
+}
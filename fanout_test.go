@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+func TestFanoutIsolatesFailures(t *testing.T) {
+	broken := &failingWriter{err: errors.New("connection reset")}
+	var file bytes.Buffer
+	var errs []error
+	l := New(broken, WithoutStdout(), WithFlags(Ldisable), WithOutput(&file), WithWriteErrorHandler(func(w io.Writer, err error) {
+		assert.Equal(t, broken, w)
+		errs = append(errs, err)
+	}))
+
+	l.Info("one")
+	l.Error("two")
+	assert.Equal(t, "INFO : one\nERROR: two\n", file.String())
+	assert.Len(t, errs, 2)
+}
+
+func TestFanoutReportsTransitions(t *testing.T) {
+	r, pw, err := os.Pipe()
+	assert.NoError(t, err)
+	old := os.Stderr
+	os.Stderr = pw
+
+	w := &failingWriter{err: errors.New("disk full")}
+	f := newFanout(nil, nil, w)
+	f.Write([]byte("a"))
+	f.WriteString("b")
+	w.err = nil
+	f.Write([]byte("c"))
+
+	os.Stderr = old
+	pw.Close()
+	stderr, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "Failed to write log to *log.failingWriter: disk full\nWriting log to *log.failingWriter works again\n", string(stderr))
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// sinkFieldMap renames the fields of the entries sent to a sink, see
+// WithSinkFieldMap.
+type sinkFieldMap struct {
+	sink  interface{}
+	names map[string]string
+}
+
+// WithSinkFieldMap renames fields for a single sink, e.g. a legacy consumer
+// expecting svc instead of service:
+//
+//	log.WithSinkFieldMap(legacyFile, map[string]string{"service": "svc"})
+//
+// Fields mapped to an empty name are left out. sink is an output writer,
+// see WithLevelForOutput, or a comparable Hook added with WithHook or
+// WithRoute. The fields are renamed after the pipeline, right before the
+// entry is formatted for the sink or passed to it, other sinks keep the
+// original names.
+func WithSinkFieldMap(sink interface{}, names map[string]string) LogOption {
+	return func(l *logger) {
+		switch sink.(type) {
+		case io.Writer, Hook:
+		default:
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: field map: %T is neither an io.Writer nor a Hook", sink))
+			return
+		}
+		if !reflect.TypeOf(sink).Comparable() {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: field map: %T can not be told apart from other sinks, pass a pointer", sink))
+			return
+		}
+		l.fieldMaps = append(l.fieldMaps, sinkFieldMap{sink: sink, names: names})
+	}
+}
+
+// fieldMapFor returns the field names of the sink or nil, the last map given
+// for a sink wins.
+func (l *logger) fieldMapFor(sink interface{}) map[string]string {
+	// comparing interfaces holding the same uncomparable type panics
+	if sink == nil || len(l.fieldMaps) == 0 || !reflect.TypeOf(sink).Comparable() {
+		return nil
+	}
+
+	var names map[string]string
+	for _, m := range l.fieldMaps {
+		if m.sink == sink {
+			names = m.names
+		}
+	}
+
+	return names
+}
+
+// renameFields returns a copy of fields with the keys renamed by names.
+func renameFields(fields LogFields, names map[string]string) LogFields {
+	renamed := make(LogFields, len(fields))
+	has := func(name string) bool {
+		_, ok := names[name]
+		return ok
+	}
+	for k, v := range fields {
+		if prefix, name, ok := ungroup(k, has); ok {
+			if names[name] == "" {
+				continue
+			}
+			k = prefix + names[name]
+		}
+		renamed[k] = v
+	}
+
+	return renamed
+}
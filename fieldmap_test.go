@@ -0,0 +1,29 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSinkFieldMap(t *testing.T) {
+	var main, legacy, added bytes.Buffer
+	hook := &closingHook{}
+	l := New(&main, WithoutStdout(), WithFlags(0), WithOutput(&legacy), WithHook(hook),
+		WithSinkFieldMap(&legacy, map[string]string{"service": "svc", "token": ""}),
+		WithSinkFieldMap(hook, map[string]string{"service": "app"}),
+		WithSinkFieldMap(&added, map[string]string{"service": "svc"}))
+	l.AddOutput(&added)
+
+	l.With(LogFields{"service": "api", "token": "t"}).Info("started")
+
+	assert.Equal(t, "INFO : service=api token=t started\n", main.String())
+	assert.Equal(t, "INFO : svc=api started\n", legacy.String())
+	assert.Equal(t, "INFO : svc=api token=t started\n", added.String())
+	assert.Equal(t, LogFields{"app": "api", "token": "t"}, hook.entries[0].Fields)
+
+	var out bytes.Buffer
+	New(&out, WithoutStdout(), WithFlags(0), WithSinkFieldMap("legacy", nil))
+	assert.Contains(t, out.String(), "neither an io.Writer nor a Hook")
+}
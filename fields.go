@@ -0,0 +1,403 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// fieldOrderKey is a reserved field holding the key order of fields created
+// with Ordered. It is skipped by Keys and never rendered. Entries passed to
+// stages, hooks and subscribers don't carry it, see unordered.
+const fieldOrderKey = "\x00order"
+
+// badKey is used for a value without key in alternating key-value lists.
+const badKey = "!BADKEY"
+
+// Ordered creates fields from alternating keys and values which formatters
+// render in the given order instead of sorting them, e.g.
+// Ordered("method", "GET", "path", "/", "status", 200). A trailing value
+// without key is stored under !BADKEY.
+func Ordered(keysAndValues ...interface{}) LogFields {
+	fields := make(LogFields, len(keysAndValues)/2+2)
+	order := make([]string, 0, len(keysAndValues)/2+1)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, value := badKey, keysAndValues[i]
+		if i+1 < len(keysAndValues) {
+			key, value = fmt.Sprint(keysAndValues[i]), keysAndValues[i+1]
+		}
+		if _, ok := fields[key]; !ok {
+			order = append(order, key)
+		}
+		fields[key] = value
+	}
+	fields[fieldOrderKey] = order
+
+	return fields
+}
+
+func (l LogFields) order() []string {
+	order, _ := l[fieldOrderKey].([]string)
+	return order
+}
+
+// unordered returns the fields without the key order of Ordered, and the
+// order. The fields are copied only when they carry an order.
+func (l LogFields) unordered() (LogFields, []string) {
+	if _, ok := l[fieldOrderKey]; !ok {
+		return l, nil
+	}
+
+	fields := make(LogFields, len(l)-1)
+	for k, v := range l {
+		if k != fieldOrderKey {
+			fields[k] = v
+		}
+	}
+
+	return fields, l.order()
+}
+
+// ordered returns the fields with the key order taken by unordered, for the
+// formatters of the outputs.
+func (l LogFields) ordered(order []string) LogFields {
+	if order == nil {
+		return l
+	}
+
+	fields := make(LogFields, len(l)+1)
+	for k, v := range l {
+		fields[k] = v
+	}
+	fields[fieldOrderKey] = order
+
+	return fields
+}
+
+// Keys returns the field names, those set with Ordered first in their
+// order, followed by the others sorted.
+func (l LogFields) Keys() []string {
+	order := l.order()
+	keys := make([]string, 0, len(l))
+	seen := make(map[string]bool, len(order))
+	for _, key := range order {
+		if _, ok := l[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	rest := make([]string, 0, len(l)-len(keys))
+	for key := range l {
+		if key != fieldOrderKey && !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// mergeOrder appends keys of b missing in a to a copy of a.
+func mergeOrder(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+
+	res := make([]string, len(a), len(a)+len(b))
+	copy(res, a)
+	seen := make(map[string]bool, len(a))
+	for _, key := range a {
+		seen[key] = true
+	}
+	for _, key := range b {
+		if !seen[key] {
+			res = append(res, key)
+		}
+	}
+
+	return res
+}
+
+// FieldType is the kind of value of a Field.
+type FieldType uint8
+
+const (
+	StringType FieldType = iota + 1
+	IntType
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+)
+
+// Field is a typed field created by String, Int, Bool, Duration, Time or
+// Err, see WithFields. Scalars are kept without an interface value, so
+// creating fields doesn't allocate. Loggers returned by WithFields keep the
+// fields as they are and formatters implementing typedAppender, e.g.
+// JsonFormatter, render them without converting them. Stages, hooks,
+// subscribers and other formatters get them in LogFields as their Value.
+type Field struct {
+	Key     string
+	Type    FieldType
+	Integer int64
+	Str     string
+	// Time holds the value of a TimeType.
+	Time time.Time
+	// Iface holds the error of an ErrorType.
+	Iface interface{}
+}
+
+// String creates a string field.
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, Str: value}
+}
+
+// Int creates an int field.
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+// Bool creates a bool field.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, Type: BoolType}
+	if value {
+		f.Integer = 1
+	}
+
+	return f
+}
+
+// Duration creates a time.Duration field, text formatters render it like
+// 1.2s.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time creates a time.Time field, formatters render it with
+// TimeFieldLayout.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Time: value}
+}
+
+// Err creates the error field of err, it is left out when err is nil.
+func Err(err error) Field {
+	return Field{Key: "error", Type: ErrorType, Iface: err}
+}
+
+// Value returns the value of the field as stored in LogFields, e.g. an int
+// for Int and a time.Duration for Duration.
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.Str
+	case IntType:
+		return int(f.Integer)
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.Time
+	}
+
+	return f.Iface
+}
+
+// TimeFieldLayout is the layout of time values of fields, e.g. of Time.
+var TimeFieldLayout = time.RFC3339Nano
+
+// fieldsOf converts typed fields to LogFields keeping their order.
+func fieldsOf(fields []Field) LogFields {
+	lf := make(LogFields, len(fields)+1)
+	order := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f.Type == ErrorType && f.Iface == nil {
+			continue
+		}
+		if _, ok := lf[f.Key]; !ok {
+			order = append(order, f.Key)
+		}
+		lf[f.Key] = f.Value()
+	}
+	lf[fieldOrderKey] = order
+
+	return lf
+}
+
+// addTyped returns typed followed by fields, with the keys prefixed by the
+// group. A key given again keeps its place and takes the later value, as
+// with fieldsOf, and nil errors are left out. typed is not modified.
+func addTyped(typed, fields []Field, group string) []Field {
+	res := make([]Field, len(typed), len(typed)+len(fields))
+	copy(res, typed)
+next:
+	for _, f := range fields {
+		if f.Type == ErrorType && f.Iface == nil {
+			continue
+		}
+		if group != "" {
+			f.Key = group + "." + f.Key
+		}
+		for i := range res {
+			if res[i].Key == f.Key {
+				res[i] = f
+				continue next
+			}
+		}
+		res = append(res, f)
+	}
+
+	return res
+}
+
+// typedEntry holds the fields of the entries of a logger with typed fields,
+// built on its first entry and kept until the logger is derived.
+type typedEntry struct {
+	// fields are the bound and the typed fields with their order, entry the
+	// same without order, for the Entry.
+	fields, entry LogFields
+	order         []string
+	// rest are the bound fields without the keys of the typed ones,
+	// rendered by typedAppender after the typed fields.
+	rest LogFields
+	// fast reports whether entries can be rendered by typedAppender: the
+	// bound fields have no order of their own and no typed field takes the
+	// key of a JSON header.
+	fast bool
+}
+
+// typedFields returns the fields of the entries of l, a logger with typed
+// fields. It is called with logLock held.
+func (l *logger) typedFields() *typedEntry {
+	if l.typedCache != nil {
+		return l.typedCache
+	}
+
+	typed := fieldsOf(l.typed)
+	t := &typedEntry{fields: l.bound.Add(typed), fast: l.bound.order() == nil}
+	t.entry, t.order = t.fields.unordered()
+	for k, v := range l.bound {
+		if _, ok := typed[k]; !ok {
+			if t.rest == nil {
+				t.rest = make(LogFields, len(l.bound))
+			}
+			t.rest[k] = v
+		}
+	}
+	for _, f := range l.typed {
+		if f.Key == "time" || f.Key == "level" || f.Key == "msg" {
+			t.fast = false
+		}
+	}
+	l.typedCache = t
+
+	return t
+}
+
+// hasTyped reports whether a typed field of l has a key of fields.
+func (l *logger) hasTyped(fields LogFields) bool {
+	for _, f := range l.typed {
+		if _, ok := fields[f.Key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bind adds the fields to those bound to l, a logger just derived. Typed
+// fields with the same keys are converted first, so the fields keep the
+// order they were added in.
+func (l *logger) bind(fields LogFields) {
+	if l.hasTyped(fields) {
+		l.bound = l.bound.Add(fieldsOf(l.typed))
+		l.typed = nil
+	}
+	l.bound = l.bound.Add(fields)
+}
+
+// typedAppender is implemented by formatters rendering typed fields as they
+// are, see JsonFormatter. appendTyped renders the entry like AppendOutput
+// of fields and the typed fields, which come first.
+type typedAppender interface {
+	appendTyped(b []byte, flags int, lvl string, typed []Field, fields LogFields, msg string) []byte
+}
+
+// appendJSON appends the JSON value of the field to b as appendJSON of
+// LogFields renders its Value. Errors are left to LogFields.
+func (f Field) appendJSON(b []byte) []byte {
+	switch f.Type {
+	case StringType:
+		return appendJSONString(b, f.Str)
+	case BoolType:
+		return strconv.AppendBool(b, f.Integer == 1)
+	case TimeType:
+		n := len(b)
+		b = f.Time.AppendFormat(append(b, '"'), TimeFieldLayout)
+		for _, c := range b[n+1:] {
+			if c < 0x20 || c >= utf8.RuneSelf || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+				return appendJSONString(b[:n], string(b[n+1:]))
+			}
+		}
+		return append(b, '"')
+	}
+
+	return strconv.AppendInt(b, f.Integer, 10)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string to b, escaped as
+// encoding/json does, without allocating.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(append(b, s[start:i]...), "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(append(b, s[start:i]...), '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+
+	return append(append(b, s[start:]...), '"')
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrdered(t *testing.T) {
+	fields := Ordered("method", "GET", "path", "/users", "status", 200).Add(LogFields{"b": 1, "a": 2})
+	assert.Equal(t, []string{"method", "path", "status", "a", "b"}, fields.Keys())
+	assert.Equal(t, []string{"x", badKey}, Ordered("x", 1, "odd").Keys())
+
+	var text, js bytes.Buffer
+	l := New(&text, WithSecondaryOutput(&js, JsonFormatter{}))
+	l.SetFlags(Ldisable)
+	l.With(LogFields{"zone": "eu"}).With(Ordered("method", "GET", "path", "/users", "status", 200)).Info("handled")
+
+	assert.Equal(t, "INFO : method=GET path=/users status=200 zone=eu handled\n", text.String())
+	assert.Equal(t, `{"level":"info","msg":"handled","method":"GET","path":"/users","status":200,"zone":"eu"}`+"\n", js.String())
+}
+
+func TestOrderedNotPassedOn(t *testing.T) {
+	var page bytes.Buffer
+	hook := &closingHook{}
+	l := New(nil, WithoutStdout(), WithFlags(0), WithHook(hook),
+		WithSecondaryOutput(&page, EscapingFormatter{Formatter: StdFormatter{}, Profile: EscapeHTML}))
+	entries, cancel := l.Subscribe(nil)
+	defer cancel()
+
+	l.With(Ordered("b", "<1>", "a", 2)).Info("handled")
+
+	assert.Equal(t, LogFields{"b": "<1>", "a": 2}, hook.entries[0].Fields)
+	assert.Equal(t, LogFields{"b": "<1>", "a": 2}, (<-entries).Fields)
+	assert.Equal(t, "INFO : b=&lt;1&gt; a=2 handled\n", page.String())
+}
+
+func TestTypedFields(t *testing.T) {
+	at := time.Date(2021, 5, 1, 10, 0, 0, 500, time.UTC)
+	assert.Equal(t, at, Time("at", at).Value())
+	assert.Equal(t, time.Time{}, Time("at", time.Time{}).Value())
+	assert.Equal(t, 1200*time.Millisecond, Duration("took", 1200*time.Millisecond).Value())
+	assert.Equal(t, true, Bool("ok", true).Value())
+
+	var text, js bytes.Buffer
+	l := New(&text, WithoutStdout(), WithFlags(Ldisable), WithSecondaryOutput(&js, JsonFormatter{}))
+	l.WithFields(String("path", "/users"), Int("status", 200), Bool("cached", false),
+		Duration("took", 1200*time.Millisecond), Time("at", at), Err(nil)).Info("handled")
+	l.WithFields(Err(errors.New("timeout"))).Error("failed")
+
+	assert.Equal(t, "INFO : path=/users status=200 cached=false took=1.2s at=2021-05-01T10:00:00.0000005Z handled\n"+
+		"ERROR: error=timeout failed\n", text.String())
+	assert.Equal(t, `{"level":"info","msg":"handled","path":"/users","status":200,"cached":false,"took":1200000000,"at":"2021-05-01T10:00:00.0000005Z"}`+"\n"+
+		`{"level":"error","msg":"failed","error":"timeout"}`+"\n", js.String())
+}
+
+func TestTypedFieldsWith(t *testing.T) {
+	var text, js bytes.Buffer
+	l := New(&text, WithoutStdout(), WithFlags(Ldisable), WithSecondaryOutput(&js, JsonFormatter{}))
+	l = l.With(LogFields{"b": 1, "a": 1}).WithFields(String("z", "1"), String("a", "2")).WithGroup("g").WithFields(Int("n", 1))
+	l.Info("typed")
+	l.With(LogFields{"n": 2}).Info("overridden")
+
+	assert.Equal(t, "INFO : z=1 a=2 g.n=1 b=1 typed\n"+
+		"INFO : z=1 a=2 g.n=2 b=1 overridden\n", text.String())
+	assert.Equal(t, `{"level":"info","msg":"typed","z":"1","a":"2","g.n":1,"b":1}`+"\n"+
+		`{"level":"info","msg":"overridden","z":"1","a":"2","g.n":2,"b":1}`+"\n", js.String())
+}
+
+func TestTypedFieldsAllocs(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFormatter(JsonFormatter{}))
+	plain := l.WithFields()
+	typed := l.WithFields(String("path", "/users"), Int("status", 200), Bool("cached", false),
+		Duration("took", time.Second), Time("at", time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	want := testing.AllocsPerRun(100, func() {
+		out.Reset()
+		plain.Info("handled")
+	})
+	allocs := testing.AllocsPerRun(100, func() {
+		out.Reset()
+		typed.Info("handled")
+	})
+	if !raceEnabled {
+		assert.Equal(t, want, allocs)
+	}
+	assert.Equal(t, `{"level":"info","msg":"handled","path":"/users","status":200,"cached":false,"took":1000000000,"at":"1600-01-01T00:00:00Z"}`+"\n", out.String())
+}
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{"", "plain", `"q" \ </a> & b`, "\n\r\t\x00\x1f", "é ü 世界", "\u2028\u2029", "bad \xff utf8"} {
+		want, err := json.Marshal(s)
+		assert.NoError(t, err)
+		assert.Equal(t, string(want), string(appendJSONString(nil, s)), s)
+	}
+}
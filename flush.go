@@ -0,0 +1,142 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Flusher is implemented by sinks and writers buffering entries, e.g.
+// HTTPSink. Flush returns once the entries buffered so far were delivered
+// or ctx is done.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// ContextCloser is implemented by sinks and writers whose Close waits for
+// buffered entries, CloseContext gives up waiting when ctx is done.
+type ContextCloser interface {
+	CloseContext(ctx context.Context) error
+}
+
+// Flush flushes the hooks and writers of the logger implementing Flusher,
+// e.g. before handing over to another process. It returns ctx.Err() when
+// ctx is done first and the errors of the flushers otherwise.
+func (l *logger) Flush(ctx context.Context) error {
+	logLock.Lock()
+	var flushers []Flusher
+	for _, h := range l.hooks {
+		if f, ok := h.(Flusher); ok {
+			flushers = append(flushers, f)
+		}
+	}
+	for _, c := range l.closers {
+		if f, ok := c.(Flusher); ok && !hasFlusher(flushers, f) {
+			flushers = append(flushers, f)
+		}
+	}
+	logLock.Unlock()
+
+	var errs []string
+	for _, f := range flushers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := f.Flush(ctx); err != nil {
+			if err == ctx.Err() {
+				return err
+			}
+			errs = append(errs, fmt.Sprintf("%T: %v", f, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("flush: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func hasFlusher(flushers []Flusher, f Flusher) bool {
+	for _, o := range flushers {
+		if sameValue(o, f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameValue compares interfaces without panicking on uncomparable types.
+func sameValue(a, b interface{}) bool {
+	defer func() { recover() }()
+	return a == b
+}
+
+// CloseContext closes the logger like Close, bounding the wait for sinks
+// draining their buffers by ctx: shutdown code can give log delivery a
+// share of its grace period. Closers not implementing ContextCloser are
+// closed in the background once ctx is done. It returns ctx.Err() when ctx
+// is done first and the errors of the closers otherwise.
+func (l *logger) CloseContext(ctx context.Context) error {
+	l = l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	if !l.initialized || l.closed {
+		return nil
+	}
+	l.closed = true
+
+	l.flushSummaries()
+	l.flushRateLimits()
+	l.flushDedups()
+	l.shutdownBanner()
+	defer l.unsubscribeAll()
+
+	var errs []string
+	for _, c := range l.closers {
+		if err := closeContext(ctx, c); err != nil {
+			if err == ctx.Err() {
+				return err
+			}
+			errs = append(errs, fmt.Sprintf("%v: %v", c, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("close: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// closeContext closes c, waiting for a plain io.Closer in the background
+// until ctx is done.
+func closeContext(ctx context.Context, c io.Closer) error {
+	if cc, ok := c.(ContextCloser); ok {
+		return cc.CloseContext(ctx)
+	}
+	if ctx.Done() == nil {
+		return c.Close()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush flushes the buffering sinks of the default logger.
+func Flush(ctx context.Context) error {
+	return defaultLogger.Flush(ctx)
+}
+
+// CloseContext closes the default logger, giving up on draining its sinks
+// when ctx is done.
+func CloseContext(ctx context.Context) error {
+	return defaultLogger.CloseContext(ctx)
+}
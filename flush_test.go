@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlush(t *testing.T) {
+	var mu sync.Mutex
+	var received int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		mu.Lock()
+		received += len(batch)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, WithHTTPBatch(100, time.Hour))
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithRoute(Route(MatchLevel(LevelInfo)).To(sink)))
+	l.Info("one")
+	l.Info("two")
+
+	assert.NoError(t, l.Flush(context.Background()))
+	mu.Lock()
+	assert.Equal(t, 2, received, "queued entries are sent by Flush")
+	mu.Unlock()
+
+	assert.NoError(t, l.CloseContext(context.Background()))
+	assert.NoError(t, l.CloseContext(context.Background()), "closing again does nothing")
+}
+
+type slowCloser struct {
+	release chan struct{}
+}
+
+func (c slowCloser) Write(p []byte) (int, error) { return len(p), nil }
+
+func (c slowCloser) Close() error {
+	<-c.release
+	return nil
+}
+
+func TestCloseContext(t *testing.T) {
+	w := slowCloser{release: make(chan struct{})}
+	defer close(w.release)
+	l := New(w, WithoutStdout())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, l.CloseContext(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
@@ -2,15 +2,19 @@ package log
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"runtime"
-	"sort"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
+// AppendFormatter is implemented by formatters which can render an entry
+// directly into a buffer. Outputs without prefixes and flags use it to skip
+// the intermediate string.
+type AppendFormatter interface {
+	AppendOutput(b []byte, flags int, lvl string, fields LogFields, msg string) []byte
+}
+
 type Formatter interface {
 	// Output method should create a formatted string to display
 	Output(flags int, lvl string, fields LogFields, msg string) string
@@ -30,25 +34,15 @@ type StdFormatter struct{}
 
 func (f StdFormatter) formatFields(fields LogFields) string {
 	fieldsStr := ""
-
-	keys := make([]string, len(fields))
-	i := 0
-	for field := range fields {
-		keys[i] = field
-		i++
+	if name, ok := fields[LoggerField].(string); ok {
+		fieldsStr = "[" + name + "] "
 	}
 
-	sort.Strings(keys)
-
-	for _, key := range keys {
-		var valueStr string
-		value := fields[key]
-
-		if stringer, ok := value.(fmt.Stringer); ok {
-			valueStr = stringer.String()
-		} else {
-			valueStr = fmt.Sprintf("%v", value)
+	for _, key := range fields.Keys() {
+		if _, ok := fields[key].(string); ok && key == LoggerField {
+			continue
 		}
+		valueStr := formatValue(fields[key])
 
 		if strings.Contains(valueStr, " ") {
 			valueStr = `"` + valueStr + `"`
@@ -60,6 +54,25 @@ func (f StdFormatter) formatFields(fields LogFields) string {
 	return fieldsStr
 }
 
+// formatValue renders a field value for text formatters, times with
+// TimeFieldLayout. Values of the typed fields are rendered without fmt.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(TimeFieldLayout)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
 func (f StdFormatter) HasFlags() bool {
 	return false
 }
@@ -83,31 +96,19 @@ func (f StdFormatter) Output(flags int, lvl string, fields LogFields, msg string
 	return buf.String()
 }
 
-type JsonFormatter struct {
-	mu sync.Mutex
-}
+type JsonFormatter struct{}
 
-func (f *JsonFormatter) createHeadersFields(flags int) LogFields {
+func (f JsonFormatter) createHeadersFields(flags int) LogFields {
 	var timeBuffer bytes.Buffer
 	var fileBuffer bytes.Buffer
 	var file string
 	var line int
 	fields := LogFields{}
 
-	t := time.Now()
+	t := clock()
 
-	f.mu.Lock()
-	defer f.mu.Unlock()
 	if flags&(Lshortfile|Llongfile) != 0 {
-		// Release lock while getting caller info - it's expensive.
-		f.mu.Unlock()
-		var ok bool
-		_, file, line, ok = runtime.Caller(3)
-		if !ok {
-			file = "???"
-			line = 0
-		}
-		f.mu.Lock()
+		file, line = caller()
 	}
 
 	if flags&(Ldate|Ltime|Lmicroseconds) != 0 {
@@ -162,19 +163,32 @@ func (f *JsonFormatter) createHeadersFields(flags int) LogFields {
 	return fields
 }
 
-func (f JsonFormatter) formatFields(fields LogFields) string {
-	b, _ := json.Marshal(fields)
+func (f JsonFormatter) Output(flags int, lvl string, fields LogFields, msg string) string {
+	return string(f.AppendOutput(nil, flags, lvl, fields, msg))
+}
 
-	return string(b)
+// AppendOutput appends the JSON object of the entry to b.
+func (f JsonFormatter) AppendOutput(b []byte, flags int, lvl string, fields LogFields, msg string) []byte {
+	return f.appendTyped(b, flags, lvl, nil, fields, msg)
 }
 
-func (f JsonFormatter) Output(flags int, lvl string, fields LogFields, msg string) string {
-	headersFields := f.createHeadersFields(flags)
-	msgFields := LogFields{"msg": msg, "level": lvl}
-	ff := f.formatFields(fields.Add(msgFields).Add(headersFields))
-	buf := bytes.NewBufferString(ff)
+func (f JsonFormatter) appendTyped(b []byte, flags int, lvl string, typed []Field, fields LogFields, msg string) []byte {
+	all := make(LogFields, len(fields)+4)
+	for k, v := range fields {
+		all[k] = v
+	}
+	all["msg"] = msg
+	all["level"] = lvl
+	for k, v := range f.createHeadersFields(flags) {
+		all[k] = v
+	}
 
-	return buf.String()
+	out, err := all.appendTypedJSON(b, typed)
+	if err != nil {
+		return b
+	}
+
+	return out
 }
 
 func (f JsonFormatter) HasFlags() bool {
@@ -191,11 +205,12 @@ func (f JsonFormatter) Flags() int {
 
 func (f JsonFormatter) Prefixes() map[Level]string {
 	return map[Level]string{
-		LevelDebug:  "",
-		LevelError:  "",
-		LevelFatal:  "",
-		LevelWaring: "",
-		LevelInfo:   "",
+		LevelTrace:   "",
+		LevelDebug:   "",
+		LevelError:   "",
+		LevelFatal:   "",
+		LevelWarning: "",
+		LevelInfo:    "",
 	}
 }
 
@@ -220,12 +235,20 @@ var (
 )
 
 func (ColorizedStdFormatter) Prefixes() map[Level]string {
-	return map[Level]string{
-		LevelDebug:  CLR_W + "DEBUG: " + RESET,
-		LevelPanic:  CLR_0 + "PANIC: " + RESET,
-		LevelError:  CLR_R + "ERROR: " + RESET,
-		LevelFatal:  CLR_R + "FATAL: " + RESET,
-		LevelWaring: CLR_Y + "WARN : " + RESET,
-		LevelInfo:   CLR_C + "INFO : " + RESET,
+	colors := map[Level]string{
+		LevelTrace:   CLR_B,
+		LevelDebug:   CLR_W,
+		LevelPanic:   CLR_0,
+		LevelError:   CLR_R,
+		LevelFatal:   CLR_R,
+		LevelWarning: CLR_Y,
+		LevelInfo:    CLR_C,
 	}
+
+	prefixes := make(map[Level]string, len(levelTags))
+	for lvl, tag := range levelTags {
+		prefixes[lvl] = colors[lvl.Severity()] + tag + RESET
+	}
+
+	return prefixes
 }
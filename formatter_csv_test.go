@@ -0,0 +1,19 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVFormatter(t *testing.T) {
+	f := CSVFormatter{Columns: []string{ColumnLevel, ColumnMsg, "user", "note"}}
+	assert.Equal(t, "level,msg,user,note\n", f.Header())
+
+	var buf bytes.Buffer
+	l := New(&buf, WithFormatter(f))
+	l.With(LogFields{"user": 42, "note": `say "hi", bye`, "dropped": true}).Warning("multi\nline")
+
+	assert.Equal(t, "warning,\"multi\nline\",42,\"say \"\"hi\"\", bye\"\n", buf.String())
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"strings"
+)
+
+// W3C fields with values taken from the entry instead of its fields.
+const (
+	W3CDate  = "date"
+	W3CTime  = "time"
+	W3CLevel = "x-level"
+	W3CMsg   = "x-msg"
+)
+
+var defaultW3CFields = []string{W3CDate, W3CTime, W3CLevel, W3CMsg}
+
+// W3CFormatter renders entries in the W3C Extended Log File Format used by
+// IIS tooling and analyzers like AWStats. Each file must start with the
+// directives returned by Header, RotatingFile writes them to every new file
+// when RotationConfig.Header is set:
+//
+//	f := log.W3CFormatter{Fields: []string{"date", "time", "cs-method", "cs-uri-stem", "sc-status"}}
+//	file, err := log.NewRotatingFile("access.log", log.RotationConfig{Daily: true, Header: f.Header})
+type W3CFormatter struct {
+	// Fields in output order, other names than date, time, x-level and x-msg
+	// are looked up in the entry fields. Defaults to date time x-level x-msg.
+	Fields []string
+}
+
+func (f W3CFormatter) fields() []string {
+	if len(f.Fields) == 0 {
+		return defaultW3CFields
+	}
+
+	return f.Fields
+}
+
+// Header returns the #Version, #Date and #Fields directives.
+func (f W3CFormatter) Header() string {
+	var b strings.Builder
+	b.WriteString("#Version: 1.0\n")
+	b.WriteString("#Date: " + clock().UTC().Format("2006-01-02 15:04:05") + "\n")
+	b.WriteString("#Fields: " + strings.Join(f.fields(), " ") + "\n")
+
+	return b.String()
+}
+
+func (f W3CFormatter) Output(flags int, lvl string, fields LogFields, msg string) string {
+	// W3C times are always UTC.
+	t := clock().UTC()
+	names := f.fields()
+	values := make([]string, len(names))
+
+	for i, name := range names {
+		switch name {
+		case W3CDate:
+			values[i] = t.Format("2006-01-02")
+		case W3CTime:
+			values[i] = t.Format("15:04:05")
+		case W3CLevel:
+			values[i] = lvl
+		case W3CMsg:
+			values[i] = w3cValue(msg)
+		default:
+			if v, ok := fields[name]; ok {
+				values[i] = w3cValue(formatValue(v))
+			} else {
+				values[i] = "-"
+			}
+		}
+	}
+
+	return strings.Join(values, " ")
+}
+
+// w3cValue quotes values containing spaces, missing values are written as "-".
+func w3cValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	if strings.ContainsAny(s, " \t\"") {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+
+	return s
+}
+
+func (f W3CFormatter) HasFlags() bool {
+	return true
+}
+
+func (f W3CFormatter) HasPrefixes() bool {
+	return true
+}
+
+func (f W3CFormatter) Flags() int {
+	return Ldisable
+}
+
+func (f W3CFormatter) Prefixes() map[Level]string {
+	return map[Level]string{}
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestW3CFormatter(t *testing.T) {
+	f := W3CFormatter{Fields: []string{W3CLevel, "cs-method", "cs-uri-stem", "sc-status", W3CMsg}}
+	path := filepath.Join(t.TempDir(), "access.log")
+	file, err := NewRotatingFile(path, RotationConfig{Header: f.Header})
+	assert.NoError(t, err)
+
+	l := New(file, WithFormatter(f))
+	l.With(LogFields{"cs-method": "GET", "sc-status": 200}).Info(`said "hi" there`)
+	assert.NoError(t, file.Rotate())
+	l.Info("")
+	l.Close()
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "#Version: 1.0", lines[0])
+		assert.Equal(t, "#Fields: x-level cs-method cs-uri-stem sc-status x-msg", lines[2])
+		assert.Equal(t, "info - - - -", lines[3])
+	}
+
+	backups, err := file.backups()
+	assert.NoError(t, err)
+	if assert.Len(t, backups, 1) {
+		b, err = os.ReadFile(backups[0].path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), "#Fields: ")
+		assert.Contains(t, string(b), `info GET - 200 "said ""hi"" there"`+"\n")
+	}
+}
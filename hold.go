@@ -0,0 +1,138 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// LegalHoldConfig configures a LegalHold.
+type LegalHoldConfig struct {
+	// Sink persists the held entries, it should write to append-only
+	// storage which the usual retention does not delete.
+	Sink Hook
+	// UserField is the field holding the user ID, defaults to "user_id".
+	UserField string
+	// CaseField is the field holding the case tag, a string or a []string,
+	// defaults to "case".
+	CaseField string
+	// UserIDs and Cases are held from the start.
+	UserIDs []string
+	Cases   []string
+}
+
+// LegalHold passes entries of held users and cases to its sink, whatever
+// the level of the entry, so they are preserved for e-discovery. The sink
+// receives the entry with the field legal_hold naming the criterion, e.g.
+// "user_id=42". Holds can be added and released while logging.
+type LegalHold struct {
+	cfg LegalHoldConfig
+
+	mu    sync.RWMutex
+	users map[string]bool
+	cases map[string]bool
+}
+
+// NewLegalHold creates the hold, add it to a logger with WithLegalHold.
+func NewLegalHold(cfg LegalHoldConfig) *LegalHold {
+	if cfg.UserField == "" {
+		cfg.UserField = "user_id"
+	}
+	if cfg.CaseField == "" {
+		cfg.CaseField = "case"
+	}
+
+	h := &LegalHold{cfg: cfg, users: map[string]bool{}, cases: map[string]bool{}}
+	for _, id := range cfg.UserIDs {
+		h.users[id] = true
+	}
+	for _, c := range cfg.Cases {
+		h.cases[c] = true
+	}
+
+	return h
+}
+
+// WithLegalHold consults h for every entry before it is written, also for
+// entries below the logger level. The sink is closed together with the
+// logger if it is an io.Closer.
+func WithLegalHold(h *LegalHold) LogOption {
+	return func(l *logger) {
+		l.holds = append(l.holds, h)
+		l.addCloser(h.cfg.Sink)
+	}
+}
+
+// HoldUser preserves the entries of the user from now on.
+func (h *LegalHold) HoldUser(id string) {
+	h.mu.Lock()
+	h.users[id] = true
+	h.mu.Unlock()
+}
+
+// ReleaseUser ends the hold of the user.
+func (h *LegalHold) ReleaseUser(id string) {
+	h.mu.Lock()
+	delete(h.users, id)
+	h.mu.Unlock()
+}
+
+// HoldCase preserves the entries tagged with the case from now on.
+func (h *LegalHold) HoldCase(tag string) {
+	h.mu.Lock()
+	h.cases[tag] = true
+	h.mu.Unlock()
+}
+
+// ReleaseCase ends the hold of the case.
+func (h *LegalHold) ReleaseCase(tag string) {
+	h.mu.Lock()
+	delete(h.cases, tag)
+	h.mu.Unlock()
+}
+
+// criterion returns the hold matched by the entry, or an empty string.
+func (h *LegalHold) criterion(e Entry) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if v, ok := e.Fields.lookup(h.cfg.UserField); ok {
+		if id := fmt.Sprint(v); h.users[id] {
+			return h.cfg.UserField + "=" + id
+		}
+	}
+
+	var tags []string
+	v, _ := e.Fields.lookup(h.cfg.CaseField)
+	switch v := v.(type) {
+	case nil:
+	case []string:
+		tags = v
+	default:
+		tags = []string{fmt.Sprint(v)}
+	}
+	for _, tag := range tags {
+		if h.cases[tag] {
+			return h.cfg.CaseField + "=" + tag
+		}
+	}
+
+	return ""
+}
+
+// hold passes the entry to the sinks of the holds it matches.
+func (l *logger) hold(e Entry) {
+	for _, h := range l.holds {
+		c := h.criterion(e)
+		if c == "" {
+			continue
+		}
+
+		held := e
+		held.Fields = e.Fields.clone()
+		held.Fields["legal_hold"] = c
+		if err := h.cfg.Sink.Fire(held); err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to persist held entry: %v\n", err)
+		}
+	}
+}
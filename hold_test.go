@@ -0,0 +1,33 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegalHold(t *testing.T) {
+	var out bytes.Buffer
+	var held []Entry
+	hold := NewLegalHold(LegalHoldConfig{
+		Sink:    HookFunc(func(e Entry) error { held = append(held, e); return nil }),
+		UserIDs: []string{"42"},
+	})
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithLevel(LevelInfo), WithLegalHold(hold))
+
+	l.With(LogFields{"user_id": 42}).Debug("below level")
+	l.With(LogFields{"user_id": 7}).Info("other user")
+	hold.HoldCase("acme")
+	l.With(LogFields{"case": []string{"globex", "acme"}}).Info("tagged")
+	hold.ReleaseUser("42")
+	l.With(LogFields{"user_id": 42}).Debug("released")
+
+	assert.Equal(t, "INFO : user_id=7 other user\nINFO : case=\"[globex acme]\" tagged\n", out.String())
+	if assert.Len(t, held, 2) {
+		assert.Equal(t, "below level", held[0].Message)
+		assert.Equal(t, LevelDebug, held[0].Level)
+		assert.Equal(t, "user_id=42", held[0].Fields["legal_hold"])
+		assert.Equal(t, "case=acme", held[1].Fields["legal_hold"])
+	}
+}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Entry is a single log record as seen by hooks.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  LogFields
+}
+
+// Hook receives every entry which passes the logger level, after it was
+// written to the outputs. Hooks are called with the logger lock held and
+// must not log through the same logger.
+type Hook interface {
+	Fire(e Entry) error
+}
+
+// HookFunc adapts a function to the Hook interface.
+type HookFunc func(e Entry) error
+
+func (f HookFunc) Fire(e Entry) error {
+	return f(e)
+}
+
+// WithHook adds a hook to the logger. If the hook is an io.Closer it is
+// closed together with the logger.
+func WithHook(h Hook) LogOption {
+	return func(l *logger) {
+		l.hooks = append(l.hooks, h)
+		l.addCloser(h)
+	}
+}
+
+// Enricher modifies entries before they are written, e.g. to add fields.
+// Enrichers run with the logger lock held, in the order they were added.
+type Enricher interface {
+	Enrich(e *Entry)
+}
+
+// EnricherFunc adapts a function to the Enricher interface.
+type EnricherFunc func(e *Entry)
+
+func (f EnricherFunc) Enrich(e *Entry) {
+	f(e)
+}
+
+// WithEnricher adds an enricher to the logger.
+func WithEnricher(en Enricher) LogOption {
+	return func(l *logger) {
+		l.enrichers = append(l.enrichers, en)
+	}
+}
+
+// newEntry creates the entry with the fields and passes it through the
+// enrich stage.
+func (l *logger) newEntry(s Level, msg string, fields LogFields) Entry {
+	fields, _ = fields.unordered()
+	e := Entry{
+		Time:    clock(),
+		Level:   s,
+		Message: msg,
+		Fields:  fields,
+	}
+	l.enrich(&e)
+
+	return e
+}
+
+// enrich adds the fields pushed by the goroutine, runs the enrichers on the
+// entry and adds the stacktrace.
+func (l *logger) enrich(e *Entry) {
+	if fields := ambientFields(); fields != nil {
+		e.Fields = fields.Add(e.Fields)
+	}
+	if len(l.enrichers) > 0 {
+		e.Fields = e.Fields.clone()
+		for _, en := range l.enrichers {
+			var before LogFields
+			if l.provenance != nil {
+				before = e.Fields.clone()
+			}
+			en.Enrich(e)
+			if l.provenance != nil {
+				l.provenance.recordEnricher(en, before, e.Fields)
+			}
+		}
+	}
+	l.addStacktrace(e)
+}
+
+func (l *logger) fireHooks(e Entry) {
+	if len(l.hooks) == 0 {
+		return
+	}
+
+	e.Fields = e.Fields.clone()
+	for _, h := range l.hooks {
+		e := e
+		if names := l.fieldMapFor(h); names != nil {
+			e.Fields = renameFields(e.Fields, names)
+		}
+		if !l.hookAllowed(h, e) {
+			continue
+		}
+		var start time.Time
+		if l.latency != nil {
+			start = time.Now()
+		}
+		err := h.Fire(e)
+		if l.latency != nil {
+			l.latency.observe(fmt.Sprintf("%T", h), time.Since(start))
+		}
+		if err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to fire hook %T: %v\n", h, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package httplog provides HTTP middleware logging through
+// github.com/bialas1993/log loggers.
+package httplog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/bialas1993/log"
+)
+
+// IncidentHeader is the response header carrying the incident ID.
+const IncidentHeader = "X-Incident-Id"
+
+// Recoverer returns middleware recovering from handler panics. The panic is
+// logged at the Error level with the stack, request fields and a generated
+// incident ID, and the client gets a 500 response quoting the same ID so
+// support can correlate reports with the log entry. The fields of the
+// request context are added as well, e.g. trace IDs. Outside of handlers,
+// log.RecoverAndLog recovers and logs panics.
+//
+// http.ErrAbortHandler is re-panicked, as net/http uses it to abort a
+// response silently.
+func Recoverer(l log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				id := IncidentID()
+				l.With(log.LogFields{
+					"incident_id": id,
+					"panic":       fmt.Sprint(rec),
+					"stack":       string(debug.Stack()),
+					"method":      r.Method,
+					"path":        r.URL.Path,
+					"remote_addr": r.RemoteAddr,
+					"user_agent":  r.UserAgent(),
+					"request_id":  r.Header.Get("X-Request-Id"),
+				}).ErrorfCtx(r.Context(), "panic serving %s %s: %v", r.Method, r.URL.Path, rec)
+
+				w.Header().Set(IncidentHeader, id)
+				http.Error(w, fmt.Sprintf("%s (incident %s)", http.StatusText(http.StatusInternalServerError), id), http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IncidentID returns a random 16 character hex identifier.
+func IncidentID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
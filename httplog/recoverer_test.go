@@ -0,0 +1,48 @@
+package httplog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bialas1993/log"
+	"github.com/bialas1993/log/logtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverer(t *testing.T) {
+	r := logtest.NewRecorder()
+	h := Recoverer(r)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req = req.WithContext(log.ContextWithFields(req.Context(), log.LogFields{"tenant": "acme"}))
+	req.Header.Set("X-Request-Id", "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(IncidentHeader)
+	assert.Len(t, id, 16)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "incident "+id)
+
+	logtest.AssertEntry(t, r, log.LevelError, "panic serving POST /orders: boom", log.LogFields{
+		"incident_id": id,
+		"request_id":  "req-1",
+		"path":        "/orders",
+		"tenant":      "acme",
+	})
+	e := r.Entries()[0]
+	assert.Contains(t, e.Fields["stack"], "TestRecoverer")
+}
+
+func TestRecovererAbort(t *testing.T) {
+	h := Recoverer(logtest.NewRecorder())(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
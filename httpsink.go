@@ -0,0 +1,149 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPSink is a hook posting entries as JSON arrays to an HTTP endpoint, as
+// accepted by many hosted log services. Every entry is an object with time,
+// level, msg and the entry fields. Use it with WithHook, it is flushed and
+// stopped when the logger is closed.
+type HTTPSink struct {
+	url       string
+	headers   map[string]string
+	batchSize int
+	interval  time.Duration
+	queueSize int
+	backoff   Backoff
+	client    *http.Client
+	batcher   *batcher
+}
+
+// HTTPSinkOption modifies an HTTPSink.
+type HTTPSinkOption func(*HTTPSink)
+
+// WithHTTPHeader adds a header to every request, e.g. an auth token.
+func WithHTTPHeader(key, value string) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.headers[key] = value
+	}
+}
+
+// WithHTTPBatch posts after size entries were collected or every interval.
+func WithHTTPBatch(size int, interval time.Duration) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.batchSize = size
+		s.interval = interval
+	}
+}
+
+// WithHTTPQueueSize bounds the number of entries waiting to be posted,
+// more entries are dropped.
+func WithHTTPQueueSize(n int) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.queueSize = n
+	}
+}
+
+// WithHTTPBackoff sets retries of failed requests.
+func WithHTTPBackoff(b Backoff) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.backoff = b
+	}
+}
+
+// WithHTTPClient sets the client used for requests.
+func WithHTTPClient(c *http.Client) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.client = c
+	}
+}
+
+// NewHTTPSink creates the sink and starts its background sender.
+func NewHTTPSink(url string, opts ...HTTPSinkOption) *HTTPSink {
+	s := &HTTPSink{
+		url:       url,
+		headers:   map[string]string{},
+		batchSize: 100,
+		interval:  5 * time.Second,
+		queueSize: 10000,
+		backoff:   DefaultBackoff,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.batcher = newBatcher(url, s.batchSize, s.queueSize, s.interval, s.send)
+
+	return s
+}
+
+// Fire queues the entry for the next request.
+func (s *HTTPSink) Fire(e Entry) error {
+	s.batcher.add(e)
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full
+// or the sink was closed.
+func (s *HTTPSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.batcher.dropped)
+}
+
+// Close posts the queued entries and stops the sender.
+func (s *HTTPSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// CloseContext stops the sender like Close, giving up when ctx is done.
+func (s *HTTPSink) CloseContext(ctx context.Context) error {
+	return s.batcher.closeContext(ctx)
+}
+
+// Flush posts the entries queued so far, giving up when ctx is done.
+func (s *HTTPSink) Flush(ctx context.Context) error {
+	return s.batcher.flush(ctx)
+}
+
+func (s *HTTPSink) send(entries []Entry) error {
+	body, err := entriesJSON(entries)
+	if err != nil {
+		return err
+	}
+
+	return s.backoff.retry(func() error {
+		return post(s.client, s.url, "application/json", s.headers, body)
+	})
+}
+
+// entriesJSON renders entries as a JSON array of objects.
+func entriesJSON(entries []Entry) ([]byte, error) {
+	b := []byte{'['}
+	for i, e := range entries {
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		var err error
+		if b, err = appendEntryJSON(b, e); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(b, ']'), nil
+}
+
+// appendEntryJSON appends the entry as an object with time, level, msg and
+// the entry fields.
+func appendEntryJSON(b []byte, e Entry) ([]byte, error) {
+	fields := e.Fields.clone()
+	fields["time"] = e.Time.Format(time.RFC3339Nano)
+	fields["level"] = e.Level.String()
+	fields["msg"] = e.Message
+
+	return fields.appendJSON(b)
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSink(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var batch []map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, WithHTTPHeader("Authorization", "Bearer token"), WithHTTPBatch(2, time.Hour))
+	l := New(&bytes.Buffer{}, WithHook(sink))
+	l.With(LogFields{"user": "ann"}).Info("one")
+	l.Info("two")
+	l.Warning("three")
+	l.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, batches, 2) {
+		assert.Len(t, batches[0], 2)
+		assert.Equal(t, "one", batches[0][0]["msg"])
+		assert.Equal(t, "ann", batches[0][0]["user"])
+		assert.Equal(t, "warning", batches[1][0]["level"])
+		assert.NotEmpty(t, batches[1][0]["time"])
+	}
+}
+
+func TestHTTPSinkAfterClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL)
+	l := New(&bytes.Buffer{}, WithHook(sink))
+	l.Close()
+
+	assert.NotPanics(t, func() {
+		l.Info("after close")
+		assert.NoError(t, sink.Fire(Entry{Level: LevelInfo, Message: "after close"}))
+	})
+	assert.Equal(t, uint64(1), sink.Dropped())
+}
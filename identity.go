@@ -0,0 +1,117 @@
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Identity describes the machine a process runs on.
+type Identity struct {
+	Hostname   string
+	Region     string
+	Zone       string
+	InstanceID string
+}
+
+// fields returns the non-empty values as host, region, zone and
+// instance_id.
+func (id Identity) fields() LogFields {
+	fields := LogFields{}
+	for k, v := range map[string]string{
+		"host":        id.Hostname,
+		"region":      id.Region,
+		"zone":        id.Zone,
+		"instance_id": id.InstanceID,
+	} {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+
+	return fields
+}
+
+// identityEnricher adds the identity to entries, the provider is called
+// once, for the first entry.
+type identityEnricher struct {
+	provider func() Identity
+	once     sync.Once
+	fields   LogFields
+}
+
+func (en *identityEnricher) Enrich(e *Entry) {
+	en.once.Do(func() {
+		en.fields = en.provider().fields()
+	})
+
+	for k, v := range en.fields {
+		if _, ok := e.Fields[k]; !ok {
+			e.Fields[k] = v
+		}
+	}
+}
+
+// WithIdentityProvider adds the identity returned by p to every entry as
+// the fields host, region, zone and instance_id, fields set on the entry
+// win. p is called once, when the first entry is logged, so it may query a
+// metadata service, e.g. WithIdentityProvider(EC2Identity).
+func WithIdentityProvider(p func() Identity) LogOption {
+	return WithEnricher(&identityEnricher{provider: p})
+}
+
+// HostIdentity returns the hostname only.
+func HostIdentity() Identity {
+	host, _ := hostname()
+	return Identity{Hostname: host}
+}
+
+var ec2MetadataURL = "http://169.254.169.254/latest"
+
+// EC2Identity returns the hostname and the region, availability zone and
+// instance ID from the EC2 instance metadata service (IMDSv2). Outside of
+// EC2 it gives up after a second and returns the hostname only.
+func EC2Identity() Identity {
+	id := HostIdentity()
+	client := &http.Client{Timeout: time.Second}
+
+	req, _ := http.NewRequest(http.MethodPut, ec2MetadataURL+"/api/token", nil)
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := metadataGet(client, req)
+	if err != nil {
+		return id
+	}
+
+	for path, v := range map[string]*string{
+		"placement/region":            &id.Region,
+		"placement/availability-zone": &id.Zone,
+		"instance-id":                 &id.InstanceID,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, ec2MetadataURL+"/meta-data/"+path, nil)
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+		*v, _ = metadataGet(client, req)
+	}
+
+	return id
+}
+
+func metadataGet(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", req.URL, resp.Status)
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithIdentityProvider(t *testing.T) {
+	calls := 0
+	provider := func() Identity {
+		calls++
+		return Identity{Hostname: "web-1", Region: "eu-west-1"}
+	}
+
+	var entries []Entry
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithIdentityProvider(provider), WithHook(HookFunc(func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})))
+	l.Info("one")
+	l.With(LogFields{"host": "override"}).Info("two")
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, LogFields{"host": "web-1", "region": "eu-west-1"}, entries[0].Fields)
+	assert.Equal(t, "override", entries[1].Fields["host"])
+}
+
+func TestEC2Identity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			assert.Equal(t, http.MethodPut, r.Method)
+			w.Write([]byte("secret"))
+			return
+		}
+		assert.Equal(t, "secret", r.Header.Get("X-aws-ec2-metadata-token"))
+		switch r.URL.Path {
+		case "/latest/meta-data/placement/region":
+			w.Write([]byte("eu-west-1"))
+		case "/latest/meta-data/placement/availability-zone":
+			w.Write([]byte("eu-west-1a"))
+		case "/latest/meta-data/instance-id":
+			w.Write([]byte("i-0abc\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(u string) { ec2MetadataURL = u }(ec2MetadataURL)
+	ec2MetadataURL = srv.URL + "/latest"
+
+	restore := Replay(ReplayConfig{Hostname: "web-1"})
+	defer restore()
+
+	assert.Equal(t, Identity{Hostname: "web-1", Region: "eu-west-1", Zone: "eu-west-1a", InstanceID: "i-0abc"}, EC2Identity())
+
+	srv.Close()
+	assert.Equal(t, Identity{Hostname: "web-1"}, EC2Identity())
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"strings"
+)
+
+// journalPriority maps levels to syslog priorities used by journald.
+var journalPriority = map[Level]string{
+	LevelFatal:   "2",
+	LevelPanic:   "2",
+	LevelError:   "3",
+	LevelWarning: "4",
+	LevelInfo:    "6",
+	LevelDebug:   "7",
+	LevelTrace:   "7",
+}
+
+// journalFieldName converts a field key to a valid journal field name:
+// uppercase letters, digits and underscores, not starting with an
+// underscore or digit and at most 64 characters long.
+func journalFieldName(key string) string {
+	b := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			b = append(b, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+			b = append(b, c)
+		default:
+			b = append(b, '_')
+		}
+	}
+
+	name := strings.TrimLeft(string(b), "_0123456789")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return name
+}
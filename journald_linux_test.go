@@ -0,0 +1,43 @@
+package log
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaldHook(t *testing.T) {
+	old := journalSocket
+	defer func() { journalSocket = old }()
+	journalSocket = filepath.Join(t.TempDir(), "journal.sock")
+
+	srv, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	l := New(&bytes.Buffer{}, WithJournald())
+	l.With(LogFields{"user-id": 42, "_secret": "x", "trace": "a\nb"}).Warning("disk full")
+	l.Close()
+
+	buf := make([]byte, 4096)
+	n, err := srv.Read(buf)
+	assert.NoError(t, err)
+
+	want := "MESSAGE=disk full\nPRIORITY=4\nSYSLOG_IDENTIFIER=" + filepath.Base(os.Args[0]) + "\n" +
+		"SECRET=x\n" +
+		"TRACE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n" +
+		"USER_ID=42\n"
+	assert.Equal(t, want, string(buf[:n]))
+}
+
+func TestJournalFieldName(t *testing.T) {
+	assert.Equal(t, "MY_FIELD", journalFieldName("my.field"))
+	assert.Equal(t, "ID", journalFieldName("_1id"))
+	assert.Equal(t, "", journalFieldName("__"))
+}
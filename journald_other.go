@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package log
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// WithJournald is only available on Linux, elsewhere it logs an error once
+// the logger is created.
+func WithJournald() LogOption {
+	return func(l *logger) {
+		l.setupErrs = append(l.setupErrs, fmt.Errorf("journald is not supported on %s", runtime.GOOS))
+	}
+}
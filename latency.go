@@ -0,0 +1,175 @@
+package log
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the histogram buckets, bucket i counts durations up to
+// 1µs << i, the last one everything longer.
+const latencyBuckets = 28
+
+type histogram struct {
+	counts [latencyBuckets]uint64
+	total  uint64
+	max    time.Duration
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < latencyBuckets-1 && d > time.Microsecond<<uint(i) {
+		i++
+	}
+	h.counts[i]++
+	h.total++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// quantile returns the upper bound of the bucket holding the quantile q,
+// the maximum for the last bucket.
+func (h *histogram) quantile(q float64) time.Duration {
+	rank := uint64(q*float64(h.total) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var n uint64
+	for i, c := range h.counts {
+		if n += c; n >= rank {
+			if i == latencyBuckets-1 || time.Microsecond<<uint(i) > h.max {
+				return h.max
+			}
+			return time.Microsecond << uint(i)
+		}
+	}
+
+	return h.max
+}
+
+// SinkLatency summarizes the write latency of a sink over an interval.
+type SinkLatency struct {
+	// Sink is the file name of a file writer and the type of other writers
+	// and hooks.
+	Sink   string
+	Writes uint64
+	P50    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// LatencyConfig configures a LatencyMonitor.
+type LatencyConfig struct {
+	// Threshold of the p99 latency, a slower sink is reported with a
+	// warning at the end of the interval. Defaults to 100ms.
+	Threshold time.Duration
+	// Interval of the histograms, defaults to a minute.
+	Interval time.Duration
+}
+
+// LatencyMonitor records how long writes to every writer and hook of a
+// logger take. At the end of every interval it logs a warning for each sink
+// whose p99 latency exceeded the threshold, which tells whether the disk, a
+// network collector or a hook slows logging down.
+type LatencyMonitor struct {
+	cfg LatencyConfig
+
+	mu    sync.Mutex
+	hists map[string]*histogram
+	last  []SinkLatency
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewLatencyMonitor creates the monitor, add it with WithLatencyMonitor.
+func NewLatencyMonitor(cfg LatencyConfig) *LatencyMonitor {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 100 * time.Millisecond
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+
+	return &LatencyMonitor{cfg: cfg, hists: map[string]*histogram{}, stop: make(chan struct{})}
+}
+
+// WithLatencyMonitor instruments the writers and hooks of the logger, the
+// warnings are logged through the logger itself.
+func WithLatencyMonitor(m *LatencyMonitor) LogOption {
+	return func(l *logger) {
+		l.latency = m
+		l.closers = append(l.closers, m)
+		go m.run(l)
+	}
+}
+
+func (m *LatencyMonitor) observe(sink string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.hists[sink]
+	if !ok {
+		h = &histogram{}
+		m.hists[sink] = h
+	}
+	h.observe(d)
+}
+
+// Stats returns the latencies of the last complete interval, sorted by
+// sink.
+func (m *LatencyMonitor) Stats() []SinkLatency {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]SinkLatency(nil), m.last...)
+}
+
+// rotate ends the interval and returns its latencies.
+func (m *LatencyMonitor) rotate() []SinkLatency {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]SinkLatency, 0, len(m.hists))
+	for sink, h := range m.hists {
+		stats = append(stats, SinkLatency{Sink: sink, Writes: h.total, P50: h.quantile(0.5), P99: h.quantile(0.99), Max: h.max})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Sink < stats[j].Sink })
+	m.hists = map[string]*histogram{}
+	m.last = stats
+
+	return stats
+}
+
+func (m *LatencyMonitor) run(l *logger) {
+	t := time.NewTicker(m.cfg.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			for _, s := range m.rotate() {
+				if s.P99 > m.cfg.Threshold {
+					l.diagnostic(LevelWarning, "log sink is slow", LogFields{
+						"sink":   s.Sink,
+						"p99":    s.P99.String(),
+						"max":    s.Max.String(),
+						"writes": s.Writes,
+					})
+				}
+			}
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// Close stops the monitor, it does not wait for a warning being logged.
+func (m *LatencyMonitor) Close() error {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+
+	return nil
+}
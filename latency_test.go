@@ -0,0 +1,67 @@
+package log
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowWriter struct {
+	delay time.Duration
+}
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	var h histogram
+	for i := 0; i < 99; i++ {
+		h.observe(3 * time.Microsecond)
+	}
+	h.observe(5 * time.Millisecond)
+
+	assert.Equal(t, 4*time.Microsecond, h.quantile(0.5))
+	assert.Equal(t, 4*time.Microsecond, h.quantile(0.99))
+	assert.Equal(t, 5*time.Millisecond, h.quantile(1))
+}
+
+func TestLatencyMonitor(t *testing.T) {
+	var mu sync.Mutex
+	var warnings []Entry
+	m := NewLatencyMonitor(LatencyConfig{Threshold: time.Millisecond, Interval: 50 * time.Millisecond})
+	l := New(slowWriter{2 * time.Millisecond}, WithoutStdout(), WithLatencyMonitor(m), WithHook(HookFunc(func(e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Level == LevelWarning {
+			warnings = append(warnings, e)
+		}
+		return nil
+	})))
+	defer l.Close()
+
+	l.Info("one")
+	l.Info("two")
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(warnings) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, "log sink is slow", warnings[0].Message)
+	assert.Equal(t, "log.slowWriter", warnings[0].Fields["sink"])
+	assert.Equal(t, uint64(2), warnings[0].Fields["writes"])
+	mu.Unlock()
+
+	stats := m.Stats()
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "log.HookFunc", stats[0].Sink)
+		assert.Equal(t, "log.slowWriter", stats[1].Sink)
+		assert.True(t, stats[1].P99 >= 2*time.Millisecond)
+	}
+}
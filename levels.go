@@ -0,0 +1,127 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// levelOverrides holds levels set for named components. Names are dotted
+// paths, a component without an override inherits the level of its closest
+// parent and finally the level of the logger.
+type levelOverrides map[string]Level
+
+// effective returns the level for name and the name it was set on, empty
+// when it is inherited from the logger.
+func (o levelOverrides) effective(name string, root Level) (Level, string) {
+	for name != "" {
+		if lvl, ok := o[name]; ok {
+			return lvl, name
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	return root, ""
+}
+
+// SetNamedLevel overrides the level of the component name, e.g. "db" or
+// "db.pool", and of its children without own overrides.
+func (l *logger) SetNamedLevel(name string, lvl Level) {
+	l = l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	named := l.namedLevels()
+	next := make(levelOverrides, len(named)+1)
+	for k, v := range named {
+		next[k] = v
+	}
+	next[name] = lvl
+	l.named.Store(next)
+}
+
+// namedLevels returns the current overrides. They are replaced, never
+// changed, so logging goroutines read them without logLock.
+func (l *logger) namedLevels() levelOverrides {
+	named, _ := l.named.Load().(levelOverrides)
+	return named
+}
+
+// loadLevel returns the level of l. SetLevel changes it while other
+// goroutines log, they read it without logLock.
+func (l *logger) loadLevel() Level {
+	return Level(atomic.LoadUint32(&l.level))
+}
+
+// storeLevel sets the level, derived loggers copy it under logLock.
+func (l *logger) storeLevel(lvl Level) {
+	atomic.StoreUint32(&l.level, uint32(lvl))
+}
+
+// ResetNamedLevel removes the override of name, it inherits its level again.
+func (l *logger) ResetNamedLevel(name string) {
+	l = l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	named := l.namedLevels()
+	if _, ok := named[name]; !ok {
+		return
+	}
+	next := make(levelOverrides, len(named))
+	for k, v := range named {
+		if k != name {
+			next[k] = v
+		}
+	}
+	l.named.Store(next)
+}
+
+// EffectiveLevel returns the level used for the component name and where
+// it comes from: the name of the component the level was set on, or an
+// empty string when it is the level of the logger.
+func (l *logger) EffectiveLevel(name string) (Level, string) {
+	l = l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	return l.namedLevels().effective(name, l.loadLevel())
+}
+
+// SetNamedLevel overrides the level of a component of the default logger.
+func SetNamedLevel(name string, lvl Level) {
+	defaultLogger.SetNamedLevel(name, lvl)
+}
+
+// ResetNamedLevel removes a component override of the default logger.
+func ResetNamedLevel(name string) {
+	defaultLogger.ResetNamedLevel(name)
+}
+
+// EffectiveLevel returns the level of a component of the default logger.
+func EffectiveLevel(name string) (Level, string) {
+	return defaultLogger.EffectiveLevel(name)
+}
+
+// ParseLevel returns the level of a name as printed by Level.String, in any
+// case. "warn" is accepted for LevelWarning and "silent" for LevelOff.
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch name {
+	case "warn":
+		return LevelWarning, nil
+	case "off", "silent":
+		return LevelOff, nil
+	}
+	for lvl, n := range levelMap {
+		if n == name {
+			return lvl, nil
+		}
+	}
+
+	return LevelDefault, fmt.Errorf("unknown level %q", s)
+}
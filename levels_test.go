@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveLevel(t *testing.T) {
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithLevel(LevelWarning))
+	l.SetNamedLevel("db", LevelDebug)
+	l.SetNamedLevel("db.pool", LevelError)
+
+	check := func(name string, lvl Level, origin string) {
+		got, from := l.EffectiveLevel(name)
+		assert.Equal(t, lvl, got, name)
+		assert.Equal(t, origin, from, name)
+	}
+
+	check("http", LevelWarning, "")
+	check("db", LevelDebug, "db")
+	check("db.query", LevelDebug, "db")
+	check("db.pool.conn", LevelError, "db.pool")
+
+	l.ResetNamedLevel("db.pool")
+	check("db.pool.conn", LevelDebug, "db")
+
+	l.SetLevel(LevelInfo)
+	l.ResetNamedLevel("db")
+	check("db.pool.conn", LevelInfo, "")
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	defer os.Unsetenv("LOG_TEST_LEVEL")
+
+	os.Setenv("LOG_TEST_LEVEL", "Debug")
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithLevelFromEnv("LOG_TEST_LEVEL"))
+	lvl, _ := l.EffectiveLevel("")
+	assert.Equal(t, LevelDebug, lvl)
+
+	var out bytes.Buffer
+	os.Setenv("LOG_TEST_LEVEL", "verbose")
+	l = New(&out, WithoutStdout(), WithFlags(Ldisable), WithLevel(LevelError), WithLevelFromEnv("LOG_TEST_LEVEL"))
+	lvl, _ = l.EffectiveLevel("")
+	assert.Equal(t, LevelDefault, lvl)
+	assert.Equal(t, "WARN : log: LOG_TEST_LEVEL: unknown level \"verbose\", using info\n", out.String())
+
+	os.Unsetenv("LOG_TEST_LEVEL")
+	l = New(&bytes.Buffer{}, WithoutStdout(), WithLevel(LevelError), WithLevelFromEnv("LOG_TEST_LEVEL"))
+	lvl, _ = l.EffectiveLevel("")
+	assert.Equal(t, LevelError, lvl)
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]Level{"trace": LevelTrace, "WARN": LevelWarning, " warning ": LevelWarning, "Fatal": LevelFatal, "off": LevelOff, "Silent": LevelOff} {
+		lvl, err := ParseLevel(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, lvl, s)
+	}
+
+	_, err := ParseLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestLevelOff(t *testing.T) {
+	var exited bool
+	osExit = func(int) { exited = true }
+	defer func() { osExit = os.Exit }()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+	l.SetLevel(LevelOff)
+	l.Error("hidden")
+	l.Log(LevelOff, "hidden")
+	assert.False(t, l.Enabled(LevelFatal))
+	l.Fatal("hidden")
+
+	assert.True(t, exited)
+	assert.Empty(t, out.String())
+	assert.Equal(t, "off", LevelOff.String())
+	assert.NoError(t, Config{Level: LevelOff}.Validate())
+}
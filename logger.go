@@ -4,14 +4,19 @@
 package log
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Level uint8
@@ -21,10 +26,22 @@ const (
 	LevelFatal Level = iota
 	LevelPanic
 	LevelError
-	LevelWaring
+	LevelWarning
 	LevelInfo
 	LevelDebug
+	LevelTrace
 	LevelDefault = LevelInfo
+
+	// LevelOff ranks above LevelFatal, as the logger level it disables all
+	// output, e.g. SetLevel(LevelOff) for a --quiet flag. Fatal and Panic
+	// still exit and panic, without writing anything.
+	LevelOff Level = 255
+	// LevelSilent is an alias of LevelOff.
+	LevelSilent = LevelOff
+
+	// Deprecated: LevelWaring is a misspelling kept for compatibility, use
+	// LevelWarning.
+	LevelWaring = LevelWarning
 )
 
 // Severity tags.
@@ -40,6 +57,7 @@ const (
 	LstdFlags     = Ldate | Ltime
 	Ldisable      = 0
 
+	tagTrace   = "TRACE: "
 	tagDebug   = "DEBUG: "
 	tagInfo    = "INFO : "
 	tagWarning = "WARN : "
@@ -54,32 +72,99 @@ const (
 var (
 	logLock       sync.Mutex
 	defaultLogger *logger
-	levelMap      = map[Level]string{
-		LevelFatal:  "fatal",
-		LevelPanic:  "panic",
-		LevelError:  "error",
-		LevelWaring: "warning",
-		LevelInfo:   "info",
-		LevelDebug:  "debug",
+	// osExit is replaced in tests of Fatal.
+	osExit    = os.Exit
+	levelTags = map[Level]string{
+		LevelFatal:   tagFatal,
+		LevelPanic:   tagPanic,
+		LevelError:   tagError,
+		LevelWarning: tagWarning,
+		LevelInfo:    tagInfo,
+		LevelDebug:   tagDebug,
+		LevelTrace:   tagTrace,
+	}
+	levelMap = map[Level]string{
+		LevelFatal:   "fatal",
+		LevelPanic:   "panic",
+		LevelError:   "error",
+		LevelWarning: "warning",
+		LevelInfo:    "info",
+		LevelDebug:   "debug",
+		LevelTrace:   "trace",
 	}
 )
 
+// String returns the level name as used by formatters.
+func (lvl Level) String() string {
+	if name, ok := levelMap[lvl]; ok {
+		return name
+	}
+	if lvl == LevelOff {
+		return "off"
+	}
+
+	return fmt.Sprintf("level(%d)", uint8(lvl))
+}
+
 // LogFields for add context information
 type LogFields map[string]interface{}
 
 // A logger represents an active logging object. Multiple loggers can be used
 // simultaneously even if they are using the same same writers.
 type logger struct {
-	debugLog    *log.Logger
-	infoLog     *log.Logger
-	warningLog  *log.Logger
-	errorLog    *log.Logger
-	panicLog    *log.Logger
-	fatalLog    *log.Logger
+	// root is the logger this one was derived from, e.g. with Named or With
+	root        *logger
+	name        string
+	group       string
+	bound       LogFields
+	typed       []Field
+	typedCache  *typedEntry
+	dedup       *dedupRule
+	dedups      map[string]*dedupState
+	dedupSwept  time.Time
+	audit       *audit
+	outputs     []*output
+	secondary   []secondaryOutput
+	levelOut    map[Level][]io.Writer
+	outLevels   []outputLevel
+	caps        []*volumeCap
+	fieldMaps   []sinkFieldMap
+	mutableWith bool
+	systemLog   bool
+	noConsole   bool
+	stderrOnly  bool
+	sysRequired bool
+	sysPriority map[Level]int
+	sysFacility int
+	hooks       []Hook
+	holds       []*LegalHold
+	subscribers []*subscriber
+	rings       []*RingBuffer
+	named       atomic.Value // levelOverrides
+	enrichers   []Enricher
+	pipeline    []stage
+	stages      []customStage
+	extractors  []ContextExtractor
+	provenance  *fieldProvenance
+	summarizers []*Summarizer
+	limits      []*keyedLimit
+	levelLimits map[Level]*levelLimit
+	sampling    bool
+	sampleRate  float64
+	sampler     *tickSampler
+	banner      *banner
+	stacktrace  *stacktraceConfig
+	setupErrs   []error
+	setupWarns  []error
 	formatter   Formatter
 	closers     []io.Closer
+	onWriteErr  func(w io.Writer, err error)
+	latency     *LatencyMonitor
+	exitCode    int
+	emergencyFd uintptr
 	initialized bool
-	level       Level
+	closed      bool
+	level       uint32 // Level, see loadLevel
 	flags       int
 	fields      LogFields
 	ctx         context.Context
@@ -90,18 +175,20 @@ type LogOption func(*logger)
 
 // initialize resets defaultLogger.  Which allows tests to reset environment.
 func initialize() {
+	initLog := &output{formatter: StdFormatter{}, loggers: map[Level]*log.Logger{}}
+	for lvl, tag := range levelTags {
+		initLog.loggers[lvl] = log.New(os.Stderr, initText+tag, Ldate|Lmicroseconds|Lshortfile)
+	}
+
 	defaultLogger = &logger{
-		debugLog:   log.New(os.Stderr, initText+tagDebug, Ldate|Lmicroseconds|Lshortfile),
-		infoLog:    log.New(os.Stderr, initText+tagInfo, Ldate|Lmicroseconds|Lshortfile),
-		warningLog: log.New(os.Stderr, initText+tagWarning, Ldate|Lmicroseconds|Lshortfile),
-		errorLog:   log.New(os.Stderr, initText+tagError, Ldate|Lmicroseconds|Lshortfile),
-		panicLog:   log.New(os.Stderr, initText+tagPanic, Ldate|Lmicroseconds|Lshortfile),
-		fatalLog:   log.New(os.Stderr, initText+tagFatal, Ldate|Lmicroseconds|Lshortfile),
-		formatter:  StdFormatter{},
-		fields:     LogFields{},
-		level:      LevelDefault,
-		flags:      LstdFlags,
-		ctx:        context.Background(),
+		outputs:     []*output{initLog},
+		formatter:   StdFormatter{},
+		fields:      LogFields{},
+		level:       uint32(LevelDefault),
+		flags:       LstdFlags,
+		exitCode:    1,
+		emergencyFd: os.Stderr.Fd(),
+		ctx:         context.Background(),
 	}
 }
 
@@ -117,87 +204,207 @@ func init() {
 // If the logFile passed in also satisfies io.Closer, logFile.Close will be called
 // when closing the logger.
 func new(name string, systemLog bool, logFile io.Writer, opts ...LogOption) *logger {
-	var dl, il, wl, el, pl io.Writer
+	l, _ := newLogger(name, systemLog, false, logFile, opts...)
+	return l
+}
+
+// newLogger creates the logger. When the system log was required with
+// WithSystemLog and can not be set up, it fails if strict is set, otherwise
+// it reports the failure and uses the remaining outputs.
+func newLogger(name string, systemLog, strict bool, logFile io.Writer, opts ...LogOption) (*logger, error) {
+	var sys map[Level]io.Writer
 	var syslogErr error
-	dLogs, iLogs, wLogs, eLogs, pLogs := []io.Writer{}, []io.Writer{}, []io.Writer{}, []io.Writer{}, []io.Writer{}
+	tLogs, dLogs, iLogs, wLogs, eLogs, pLogs, fLogs := []io.Writer{}, []io.Writer{}, []io.Writer{}, []io.Writer{}, []io.Writer{}, []io.Writer{}, []io.Writer{}
+
+	l := logger{
+		formatter:   StdFormatter{},
+		flags:       LstdFlags,
+		fields:      LogFields{},
+		level:       uint32(LevelDefault),
+		exitCode:    1,
+		emergencyFd: os.Stderr.Fd(),
+		systemLog:   systemLog,
+		sysFacility: -1,
+	}
+
+	for _, opt := range opts {
+		opt(&l)
+	}
+	if err := l.buildPipeline(); err != nil {
+		l.setupErrs = append(l.setupErrs, err)
+	}
 
-	if systemLog {
-		dl, il, wl, el, pl, syslogErr = setup(name)
+	if l.systemLog {
+		if name == "" {
+			name = filepath.Base(os.Args[0])
+		}
+		w, err := setup(name, l.sysPriority, l.sysFacility)
+		if err != nil {
+			if l.sysRequired {
+				err = fmt.Errorf("log: system log required: %w", err)
+				if strict {
+					return nil, err
+				}
+			}
+			syslogErr = err
+		} else {
+			sys = w
+		}
 	}
 
 	if logFile != nil {
+		tLogs = append(tLogs, logFile)
 		dLogs = append(dLogs, logFile)
 		iLogs = append(iLogs, logFile)
 		wLogs = append(wLogs, logFile)
 		eLogs = append(eLogs, logFile)
 		pLogs = append(pLogs, logFile)
+		fLogs = append(fLogs, logFile)
 	}
 
-	if dl != nil {
-		dLogs = append(dLogs, il)
-	}
-	if il != nil {
-		iLogs = append(iLogs, il)
-	}
-	if wl != nil {
-		wLogs = append(wLogs, wl)
-	}
-	if el != nil {
-		eLogs = append(eLogs, el)
-	}
-	if pl != nil {
-		pLogs = append(pLogs, pl)
-	}
-
-	l := logger{
-		formatter: StdFormatter{},
-		flags:     LstdFlags,
-		fields:    LogFields{},
-		level:     LevelDefault,
+	if sys != nil {
+		tLogs = append(tLogs, sys[LevelTrace])
+		dLogs = append(dLogs, sys[LevelDebug])
+		iLogs = append(iLogs, sys[LevelInfo])
+		wLogs = append(wLogs, sys[LevelWarning])
+		eLogs = append(eLogs, sys[LevelError])
+		pLogs = append(pLogs, sys[LevelPanic])
+		fLogs = append(fLogs, sys[LevelFatal])
 	}
 
-	for _, opt := range opts {
-		opt(&l)
+	tLogs = append(tLogs, l.levelOut[LevelTrace]...)
+	dLogs = append(dLogs, l.levelOut[LevelDebug]...)
+	iLogs = append(iLogs, l.levelOut[LevelInfo]...)
+	wLogs = append(wLogs, l.levelOut[LevelWarning]...)
+	eLogs = append(eLogs, l.levelOut[LevelError]...)
+	pLogs = append(pLogs, l.levelOut[LevelPanic]...)
+	fLogs = append(fLogs, l.levelOut[LevelFatal]...)
+
+	cfgErr := Config{
+		Name:      name,
+		SystemLog: l.systemLog,
+		NoConsole: l.noConsole,
+		Output:    logFile,
+		Formatter: l.formatter,
+		Level:     l.loadLevel(),
+		Flags:     l.flags,
+	}.Validate()
+
+	var stdout, stderr io.Writer
+	if !l.noConsole {
+		stdout, stderr = os.Stdout, consoleWriter{os.Stderr}
+		if _, ok := l.formatter.(ColorizedStdFormatter); ok {
+			stdout, stderr = console(os.Stdout), console(os.Stderr)
+		}
+		if l.stderrOnly {
+			stdout = stderr
+		}
+		// Windows services don't have stdout/stderr. Writes will fail, so try them last.
+		tLogs = append(tLogs, stdout)
+		dLogs = append(dLogs, stdout)
+		iLogs = append(iLogs, stdout)
+		wLogs = append(wLogs, stdout)
+		eLogs = append(eLogs, stderr)
+		pLogs = append(pLogs, stderr)
+		fLogs = append(fLogs, stderr)
 	}
 
-	// Windows services don't have stdout/stderr. Writes will fail, so try them last.
-	dLogs = append(dLogs, os.Stdout)
-	iLogs = append(iLogs, os.Stdout)
-	wLogs = append(wLogs, os.Stdout)
-	eLogs = append(eLogs, os.Stderr)
-	pLogs = append(pLogs, os.Stderr)
-
-	prefixDebug, prefixInfo, prefixWaring, prefixError, prefixPanic, prefixFatal := tagDebug, tagInfo, tagWarning, tagError, tagPanic, tagFatal
 	if l.formatter.HasFlags() {
 		l.flags = l.formatter.Flags()
 	}
-	if l.formatter.HasPrefixes() {
-		prefixes := l.formatter.Prefixes()
-		prefixDebug = prefixes[LevelDebug]
-		prefixInfo = prefixes[LevelInfo]
-		prefixWaring = prefixes[LevelWaring]
-		prefixError = prefixes[LevelError]
-		prefixPanic = prefixes[LevelPanic]
-		prefixFatal = prefixes[LevelFatal]
-	}
 
-	l.debugLog = log.New(io.MultiWriter(dLogs...), prefixDebug, l.flags)
-	l.infoLog = log.New(io.MultiWriter(iLogs...), prefixInfo, l.flags)
-	l.warningLog = log.New(io.MultiWriter(wLogs...), prefixWaring, l.flags)
-	l.errorLog = log.New(io.MultiWriter(eLogs...), prefixError, l.flags)
-	l.panicLog = log.New(io.MultiWriter(pLogs...), prefixPanic, l.flags)
-	l.fatalLog = log.New(io.MultiWriter(eLogs...), prefixFatal, l.flags)
+	// WithLevelForOutput refers to the console by its file and to the system
+	// log by SystemLogOutput.
+	outputKey := func(w io.Writer) io.Writer {
+		switch w {
+		case nil:
+			return nil
+		case stderr:
+			return os.Stderr
+		case stdout:
+			return os.Stdout
+		}
+		for _, sw := range sys {
+			if w == sw {
+				return SystemLogOutput
+			}
+		}
+		return w
+	}
 
-	for _, w := range []io.Writer{logFile, il, wl, el, pl} {
-		if c, ok := w.(io.Closer); ok && c != nil {
-			l.closers = append(l.closers, c)
+	writers := map[Level][]io.Writer{
+		LevelTrace:   tLogs,
+		LevelDebug:   dLogs,
+		LevelInfo:    iLogs,
+		LevelWarning: wLogs,
+		LevelError:   eLogs,
+		LevelPanic:   pLogs,
+		LevelFatal:   fLogs,
+	}
+	// levels added with RegisterLevel write to the writers of their
+	// severity, but to the system log with their own priority
+	for lvl, base := range customBase {
+		if sys[lvl] == nil {
+			continue
 		}
+		ws := make([]io.Writer, len(writers[base]))
+		for i, w := range writers[base] {
+			if w == sys[base] {
+				w = sys[lvl]
+			}
+			ws[i] = w
+		}
+		writers[lvl] = ws
+	}
+	fanouts := make(map[Level]io.Writer, len(writers))
+	// writers with a field map get an output of their own
+	mapped := map[io.Writer]map[Level]io.Writer{}
+	for lvl, ws := range writers {
+		allowed := ws[:0:0]
+		for _, w := range ws {
+			key := outputKey(w)
+			if !l.outputAllows(key, lvl) {
+				continue
+			}
+			if l.fieldMapFor(key) != nil {
+				if mapped[key] == nil {
+					mapped[key] = map[Level]io.Writer{}
+				}
+				mapped[key][lvl] = newFanout(l.onWriteErr, l.latency, l.capped(key, w, lvl))
+				continue
+			}
+			allowed = append(allowed, l.capped(key, w, lvl))
+		}
+		fanouts[lvl] = newFanout(l.onWriteErr, l.latency, allowed...)
+	}
+	l.outputs = append(l.outputs, newOutput(l.formatter, l.flags, fanouts))
+	for key, out := range mapped {
+		o := newOutput(l.formatter, l.flags, out)
+		o.rename = l.fieldMapFor(key)
+		l.outputs = append(l.outputs, o)
+	}
+
+	for _, so := range l.secondary {
+		l.outputs = append(l.outputs, l.sinkOutput(so.w, so.formatter))
+		l.addCloser(so.w)
 	}
 
+	l.addCloser(logFile)
+	l.closers = append(l.closers, systemLogClosers(sys)...)
+
 	l.initialized = true
 
 	if syslogErr != nil {
-		l.Error(syslogErr)
+		l.diagnostic(LevelError, "Failed to set up the system log", LogFields{"error": syslogErr.Error()})
+	}
+	if cfgErr != nil {
+		l.Error(cfgErr)
+	}
+	for _, err := range l.setupErrs {
+		l.Error(err)
+	}
+	for _, err := range l.setupWarns {
+		l.Warning(err)
 	}
 
 	logLock.Lock()
@@ -206,7 +413,7 @@ func new(name string, systemLog bool, logFile io.Writer, opts ...LogOption) *log
 		defaultLogger = &l
 	}
 
-	return &l
+	return &l, nil
 }
 
 // NewSyslogLogger with logging to system log
@@ -214,9 +421,10 @@ func NewSyslogLogger(name string, opts ...LogOption) Logger {
 	return new(name, true, nil, opts...)
 }
 
-// NewStdLogger standard console logging
+// NewStdLogger standard console logging. The level is read from LOG_LEVEL,
+// options given override it.
 func NewStdLogger(opts ...LogOption) Logger {
-	return new("", false, nil, opts...)
+	return new("", false, nil, append([]LogOption{WithLevelFromEnv("LOG_LEVEL")}, opts...)...)
 }
 
 // NewJsonLogger with json formatter
@@ -245,6 +453,46 @@ func WithFormatter(f Formatter) LogOption {
 	}
 }
 
+// WithLevel sets the initial logger level
+func WithLevel(lvl Level) LogOption {
+	return func(l *logger) {
+		l.storeLevel(lvl)
+	}
+}
+
+// WithLevelFromEnv sets the initial level from the environment variable,
+// e.g. WithLevelFromEnv("LOG_LEVEL") with LOG_LEVEL=debug. An unset or empty
+// variable keeps the level, a value ParseLevel does not accept sets
+// LevelDefault and is logged as a warning.
+func WithLevelFromEnv(name string) LogOption {
+	return func(l *logger) {
+		v := os.Getenv(name)
+		if v == "" {
+			return
+		}
+		lvl, err := ParseLevel(v)
+		if err != nil {
+			lvl = LevelDefault
+			l.setupWarns = append(l.setupWarns, fmt.Errorf("log: %s: %w, using %s", name, err, lvl))
+		}
+		l.storeLevel(lvl)
+	}
+}
+
+// WithFatalExitCode sets the exit code of Fatal and Fatalf, 1 by default.
+func WithFatalExitCode(code int) LogOption {
+	return func(l *logger) {
+		l.exitCode = code
+	}
+}
+
+// WithFlags sets the initial output flags, formatters with own flags override them
+func WithFlags(flag int) LogOption {
+	return func(l *logger) {
+		l.flags = flag
+	}
+}
+
 func (l LogFields) Add(newFields LogFields) LogFields {
 	if len(l) == 0 {
 		return newFields
@@ -261,95 +509,330 @@ func (l LogFields) Add(newFields LogFields) LogFields {
 		resultFields[field] = value
 	}
 
+	if order := mergeOrder(l.order(), newFields.order()); order != nil {
+		resultFields[fieldOrderKey] = order
+	}
+
 	return resultFields
 }
 
+// clone returns a shallow copy of the fields.
+func (l LogFields) clone() LogFields {
+	c := make(LogFields, len(l))
+	for field, value := range l {
+		c[field] = value
+	}
+
+	return c
+}
+
 func (l LogFields) MarshalJSON() ([]byte, error) {
-	var b []byte
-	buf := bytes.NewBuffer(b)
-	buf.WriteRune('{')
+	return l.appendJSON(nil)
+}
 
-	data := [][]interface{}{}
-	if v, ok := l["time"]; ok {
-		data = append(data, []interface{}{"time", v})
-		delete(l, "time")
-	}
+// appendJSON appends the fields as a JSON object to b. The time, level and
+// msg keys come first, the others follow in the order of Keys. Times are
+// rendered with TimeFieldLayout and errors as their message.
+func (l LogFields) appendJSON(b []byte) ([]byte, error) {
+	return l.appendTypedJSON(b, nil)
+}
+
+// appendTypedJSON is appendJSON with the typed fields following the time,
+// level and msg keys. Their keys must not be in l.
+func (l LogFields) appendTypedJSON(b []byte, typed []Field) ([]byte, error) {
+	b = append(b, '{')
+	first := true
+	appendField := func(key string, val interface{}) error {
+		if !first {
+			b = append(b, ',')
+		}
+		first = false
+
+		km, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		b = append(b, km...)
+		b = append(b, ':')
+		switch v := val.(type) {
+		case int:
+			b = strconv.AppendInt(b, int64(v), 10)
+			return nil
+		case bool:
+			b = strconv.AppendBool(b, v)
+			return nil
+		case time.Duration:
+			b = strconv.AppendInt(b, int64(v), 10)
+			return nil
+		case time.Time:
+			val = v.Format(TimeFieldLayout)
+		case json.Marshaler:
+			// errors marshaling themselves
+		case error:
+			val = v.Error()
+		}
+		vm, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		b = append(b, vm...)
 
-	if v, ok := l["level"]; ok {
-		data = append(data, []interface{}{"level", v})
-		delete(l, "level")
+		return nil
 	}
 
-	if v, ok := l["msg"]; ok {
-		data = append(data, []interface{}{"msg", v})
-		delete(l, "msg")
+	for _, key := range []string{"time", "level", "msg"} {
+		if v, ok := l[key]; ok {
+			if err := appendField(key, v); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	for key, val := range l {
-		data = append(data, []interface{}{key, val})
+	for _, f := range typed {
+		if f.Type == ErrorType {
+			if err := appendField(f.Key, f.Iface); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !first {
+			b = append(b, ',')
+		}
+		first = false
+		b = append(appendJSONString(b, f.Key), ':')
+		b = f.appendJSON(b)
 	}
 
-	for i, d := range data {
-		km, err := json.Marshal(d[0])
-		if err != nil {
-			return nil, err
+	for _, key := range l.Keys() {
+		if key == "time" || key == "level" || key == "msg" {
+			continue
 		}
-		buf.Write(km)
-		buf.WriteRune(':')
-		vm, err := json.Marshal(d[1])
-		if err != nil {
+		if err := appendField(key, l[key]); err != nil {
 			return nil, err
 		}
-		buf.Write(vm)
-		if i != len(data)-1 {
-			buf.WriteRune(',')
-		}
 	}
-	buf.WriteRune('}')
-	return buf.Bytes(), nil
+
+	return append(b, '}'), nil
+}
+
+// wants reports whether an entry of the level is used: written, or kept by
+// a ring buffer or legal hold. Logging methods return early otherwise,
+// without formatting the message.
+func (l *logger) wants(s Level) bool {
+	return l.passes(s) || len(l.rings) > 0 || len(l.holds) > 0
+}
+
+// passes reports whether entries of the level pass the logger level.
+func (l *logger) passes(s Level) bool {
+	lvl := l.effectiveLevel()
+	return lvl != LevelOff && lvl >= s.Severity()
+}
+
+// Enabled reports whether entries of the level pass the logger level, so
+// callers can skip building expensive messages and fields.
+func (l *logger) Enabled(lvl Level) bool {
+	return l.passes(lvl)
+}
+
+// DebugEnabled reports whether debug entries pass the logger level.
+func (l *logger) DebugEnabled() bool {
+	return l.Enabled(LevelDebug)
 }
 
 func (l *logger) clear() {
 	logLock.Lock()
 	defer logLock.Unlock()
 	l.fields = LogFields{}
+	if l.provenance != nil {
+		l.provenance.reset()
+	}
 }
 
-func (l *logger) bindContextFields() {
+func (l *logger) output(s Level, depth int, msg string) {
+	// skip output as well
+	l.outputCall(nil, nil, s, depth+1, msg)
+}
+
+// outputCall logs the entry of a logging call with the fields and the
+// sampling decision of ctx, the context passed to a *Ctx method, and the
+// fields passed to the call, e.g. the keys and values of Infow. They are
+// kept with the entry, not with the logger, which goroutines share.
+func (l *logger) outputCall(ctx context.Context, callFields LogFields, s Level, depth int, msg string) {
+	defer l.clear()
+
+	if l.passes(s) {
+		logLock.Lock()
+		defer logLock.Unlock()
+		if l.top().closed {
+			return
+		}
+		if l.provenance != nil {
+			defer l.provenance.reset()
+		}
+		all, typed := l.callFields(ctx, callFields)
+		var fields LogFields
+		var order []string
+		if typed != nil {
+			fields, order = typed.entry, typed.order
+		} else {
+			fields, order = all.unordered()
+		}
+		e := Entry{Time: clock(), Level: s, Message: msg, Fields: fields}
+		if !l.process(&e, l.callDecision(ctx)) {
+			l.recordRings(e)
+			return
+		}
+		// stages replacing the fields clone them first
+		if typed != nil && (!typed.fast || !sameFields(e.Fields, typed.entry)) {
+			typed = nil
+		}
+		fields = nil
+		for _, o := range l.outputs {
+			if typed != nil && o.writeTyped(s, l.top().flags, l.typed, typed.rest, e.Message) {
+				continue
+			}
+			if fields == nil {
+				fields = e.Fields.ordered(order)
+			}
+			o.write(s, depth, l.top().flags, fields, e.Message)
+		}
+		l.writeProvenance(e)
+		l.fireHooks(e)
+		l.noticeCaps()
+		l.publish(e)
+		l.recordRings(e)
+	} else if len(l.rings) > 0 || len(l.holds) > 0 {
+		logLock.Lock()
+		defer logLock.Unlock()
+		if l.top().closed {
+			return
+		}
+		fields, _ := l.callFields(ctx, callFields)
+		e := l.newEntry(s, msg, fields)
+		l.hold(e)
+		l.recordRings(e)
+	}
+}
+
+// callFields returns the fields of the entry of a logging call: those of
+// the logger, of the context set with WithContextFields, of ctx and of the
+// call. For a logger with typed fields and no other fields for the entry
+// it returns them typed as well. It is called with logLock held.
+func (l *logger) callFields(ctx context.Context, callFields LogFields) (LogFields, *typedEntry) {
+	fields := l.entryFields()
+	var typed *typedEntry
+	if len(l.typed) > 0 && len(l.fields) == 0 {
+		typed = l.typedFields()
+	}
+	for _, c := range []context.Context{l.ctx, ctx} {
+		if v := l.contextFields(c); len(v) > 0 {
+			if l.provenance != nil {
+				l.provenance.record(v, "context")
+			}
+			fields = fields.Add(v)
+			typed = nil
+		}
+	}
+	if len(callFields) > 0 {
+		if l.provenance != nil {
+			l.provenance.record(callFields, "keys and values")
+		}
+		fields = fields.Add(callFields)
+		typed = nil
+	}
+
+	return fields, typed
+}
+
+// sameFields reports whether a and b are the same map.
+func sameFields(a, b LogFields) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// callDecision returns the sampling decision of ctx or of the context set
+// with WithContextFields, nil when there is none.
+func (l *logger) callDecision(ctx context.Context) *SamplingDecision {
+	if !l.sampling {
+		return nil
+	}
+	for _, c := range []context.Context{ctx, l.ctx} {
+		if d, ok := SamplingFromContext(c); ok {
+			return &d
+		}
+	}
+
+	return nil
+}
+
+// Raw writes a preformatted line to the writers of the level, without
+// formatter, prefix and flags. Hooks and subscribers receive it as an entry
+// with the line as message and no fields.
+func (l *logger) Raw(s Level, line []byte) {
+	if !l.passes(s) {
+		return
+	}
+
 	logLock.Lock()
 	defer logLock.Unlock()
+	if l.top().closed {
+		return
+	}
 
-	if l.ctx != nil {
-		if v, ok := l.ctx.Value(keyContextFields).(LogFields); ok {
-			l.With(v)
-		}
+	for _, o := range l.outputs {
+		o.writeRaw(s, line)
 	}
+	e := Entry{Time: clock(), Level: s, Message: strings.TrimSuffix(string(line), "\n"), Fields: LogFields{}}
+	l.fireHooks(e)
+	l.noticeCaps()
+	l.publish(e)
+	l.recordRings(e)
 }
 
-func (l *logger) output(s Level, depth int, txt string) {
-	defer l.clear()
+// recordRings adds the entry to the ring buffers and dumps them when the
+// process is about to stop.
+func (l *logger) recordRings(e Entry) {
+	if len(l.rings) == 0 {
+		return
+	}
 
-	if l.level >= s {
-		logLock.Lock()
-		defer logLock.Unlock()
-		switch s {
-		case LevelDebug:
-			l.debugLog.Output(3+depth, txt)
-		case LevelInfo:
-			l.infoLog.Output(3+depth, txt)
-		case LevelWaring:
-			l.warningLog.Output(3+depth, txt)
-		case LevelError:
-			l.errorLog.Output(3+depth, txt)
-		case LevelPanic:
-			l.panicLog.Output(3+depth, txt)
-		case LevelFatal:
-			l.fatalLog.Output(3+depth, txt)
+	e.Fields = e.Fields.clone()
+	for _, r := range l.rings {
+		r.record(e)
+		if e.Level.Severity() <= LevelPanic {
+			r.crashed()
 		}
 	}
 }
 
+// diagnostic logs an entry on behalf of the logger itself, e.g. a state
+// change of a background sender or a line read from a child process. It
+// does not use the fields added with With and is dropped once the logger is
+// closed.
+func (l *logger) diagnostic(s Level, msg string, fields LogFields) {
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	if l.closed || !l.passes(s) {
+		return
+	}
+
+	e := Entry{Time: clock(), Level: s, Message: msg, Fields: fields.clone()}
+	delete(e.Fields, fieldOrderKey)
+	for _, en := range l.enrichers {
+		en.Enrich(&e)
+	}
+	for _, o := range l.outputs {
+		o.write(s, 0, l.flags, e.Fields, e.Message)
+	}
+	l.fireHooks(e)
+	l.noticeCaps()
+	l.publish(e)
+	l.recordRings(e)
+}
+
 type Logger interface {
+	Trace(v ...interface{})
+	Tracef(format string, v ...interface{})
 	Debug(v ...interface{})
 	Debugf(format string, v ...interface{})
 	Info(v ...interface{})
@@ -358,115 +841,223 @@ type Logger interface {
 	Warningf(format string, v ...interface{})
 	Fatal(v ...interface{})
 	Fatalf(format string, v ...interface{})
+	FatalCode(code int, v ...interface{})
 	Error(v ...interface{})
 	Errorf(format string, v ...interface{})
 	Panic(v ...interface{})
 	Panicf(format string, v ...interface{})
+	Print(v ...interface{})
+	Printf(format string, v ...interface{})
+	Println(v ...interface{})
+	io.Writer
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Fatalw(msg string, keysAndValues ...interface{})
+	Raw(lvl Level, line []byte)
+	Log(lvl Level, v ...interface{})
+	Emergency(msg string)
+	Audit(event string, fields LogFields) error
+	Banner(app, version string, extra LogFields)
+	TraceCtx(ctx context.Context, v ...interface{})
+	TracefCtx(ctx context.Context, format string, v ...interface{})
+	DebugCtx(ctx context.Context, v ...interface{})
+	DebugfCtx(ctx context.Context, format string, v ...interface{})
+	InfoCtx(ctx context.Context, v ...interface{})
+	InfofCtx(ctx context.Context, format string, v ...interface{})
+	WarningCtx(ctx context.Context, v ...interface{})
+	WarningfCtx(ctx context.Context, format string, v ...interface{})
+	ErrorCtx(ctx context.Context, v ...interface{})
+	ErrorfCtx(ctx context.Context, format string, v ...interface{})
 	SetLevel(lvl Level)
+	Enabled(lvl Level) bool
+	DebugEnabled() bool
+	SetNamedLevel(name string, lvl Level)
+	ResetNamedLevel(name string)
+	EffectiveLevel(name string) (Level, string)
 	SetFlags(flag int)
 	With(fields LogFields) Logger
+	WithFields(fields ...Field) Logger
+	WithError(err error) Logger
 	WithContextFields(ctx context.Context, fields LogFields) Logger
+	Subscribe(filter func(Entry) bool) (<-chan Entry, func())
+	AddOutput(w io.Writer)
+	Named(name string) Logger
+	WithGroup(name string) Logger
+	Once(key string) Logger
+	Every(window time.Duration) Logger
+	Progress(name string, total int) *ProgressTracker
+	Timer(name string, warnAfter ...time.Duration) (done func())
+	Flush(ctx context.Context) error
 	Close()
+	CloseContext(ctx context.Context) error
 }
 
 // Close closes all the underlying log writers, which will flush any cached logs.
 // Any errors from closing the underlying log writers will be printed to stderr.
 // Once Close is called, all future calls to the logger will panic.
 func (l *logger) Close() {
+	l = l.top()
 	logLock.Lock()
 	defer logLock.Unlock()
 
 	if !l.initialized {
 		return
 	}
+	l.closed = true
 
+	l.flushSummaries()
+	l.flushRateLimits()
+	l.flushDedups()
+	l.shutdownBanner()
 	for _, c := range l.closers {
 		if err := c.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to close log %v: %v\n", c, err)
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to close log %v: %v\n", c, err)
 		}
 	}
+	l.unsubscribeAll()
+}
+
+func (l *logger) unsubscribeAll() {
+	for len(l.subscribers) > 0 {
+		l.unsubscribe(l.subscribers[0])
+	}
+}
+
+// Trace logs with the Trace severity, below Debug.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) Trace(v ...interface{}) {
+	if !l.wants(LevelTrace) {
+		l.clear()
+		return
+	}
+	l.output(LevelTrace, 0, fmt.Sprint(v...))
+}
+
+// Tracef logs with the Trace severity, below Debug.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) Tracef(format string, v ...interface{}) {
+	if !l.wants(LevelTrace) {
+		l.clear()
+		return
+	}
+	l.output(LevelTrace, 0, fmt.Sprintf(format, v...))
 }
 
 // Debug logs with the Debug severity.
 // Arguments are handled in the manner of fmt.Print.
 func (l *logger) Debug(v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelDebug, 0, string(l.formatter.Output(l.flags, levelMap[LevelDebug], l.fields, fmt.Sprint(v...))))
+	if !l.wants(LevelDebug) {
+		l.clear()
+		return
+	}
+	l.output(LevelDebug, 0, fmt.Sprint(v...))
 }
 
 // Debugf logs with the Debug severity.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *logger) Debugf(format string, v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelDebug, 0, string(l.formatter.Output(l.flags, levelMap[LevelDebug], l.fields, fmt.Sprintf(format, v...))))
+	if !l.wants(LevelDebug) {
+		l.clear()
+		return
+	}
+	l.output(LevelDebug, 0, fmt.Sprintf(format, v...))
 }
 
 // Info logs with the Info severity.
 // Arguments are handled in the manner of fmt.Print.
 func (l *logger) Info(v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelInfo, 0, string(l.formatter.Output(l.flags, levelMap[LevelInfo], l.fields, fmt.Sprint(v...))))
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.output(LevelInfo, 0, fmt.Sprint(v...))
 }
 
 // Infof logs with the Info severity.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *logger) Infof(format string, v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelInfo, 0, string(l.formatter.Output(l.flags, levelMap[LevelInfo], l.fields, fmt.Sprintf(format, v...))))
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.output(LevelInfo, 0, fmt.Sprintf(format, v...))
 }
 
 // Warning logs with the Warning severity.
 // Arguments are handled in the manner of fmt.Print.
 func (l *logger) Warning(v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelWaring, 0, string(l.formatter.Output(l.flags, levelMap[LevelWaring], l.fields, fmt.Sprint(v...))))
+	if !l.wants(LevelWarning) {
+		l.clear()
+		return
+	}
+	l.output(LevelWarning, 0, fmt.Sprint(v...))
 }
 
 // Warningf logs with the Warning severity.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *logger) Warningf(format string, v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelWaring, 0, string(l.formatter.Output(l.flags, levelMap[LevelWaring], l.fields, fmt.Sprintf(format, v...))))
+	if !l.wants(LevelWarning) {
+		l.clear()
+		return
+	}
+	l.output(LevelWarning, 0, fmt.Sprintf(format, v...))
 }
 
-// Fatal logs with the Fatal severity, and ends with os.Exit(1).
+// Fatal logs with the Fatal severity, and ends with os.Exit using the code
+// set with WithFatalExitCode, 1 by default.
 // Arguments are handled in the manner of fmt.Print.
 func (l *logger) Fatal(v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelFatal, 0, string(l.formatter.Output(l.flags, levelMap[LevelFatal], l.fields, fmt.Sprint(v...))))
-	l.Close()
-	os.Exit(1)
+	l.fatal(l.exitCode, fmt.Sprint(v...))
 }
 
-// Fatalf logs with the Fatal severity, and ends with os.Exit(1).
+// Fatalf logs with the Fatal severity, and ends with os.Exit using the code
+// set with WithFatalExitCode, 1 by default.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *logger) Fatalf(format string, v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelFatal, 0, string(l.formatter.Output(l.flags, levelMap[LevelFatal], l.fields, fmt.Sprintf(format, v...))))
+	l.fatal(l.exitCode, fmt.Sprintf(format, v...))
+}
+
+// FatalCode logs with the Fatal severity, and ends with os.Exit(code), so
+// supervisors can tell failure classes apart.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) FatalCode(code int, v ...interface{}) {
+	l.fatal(code, fmt.Sprint(v...))
+}
+
+func (l *logger) fatal(code int, msg string) {
+	// skip fatal as well
+	l.output(LevelFatal, 1, msg)
 	l.Close()
-	os.Exit(1)
+	osExit(code)
 }
 
 // Error logs with the ERROR severity.
 // Arguments are handled in the manner of fmt.Print.
 func (l *logger) Error(v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelError, 0, string(l.formatter.Output(l.flags, levelMap[LevelError], l.fields, fmt.Sprint(v...))))
+	if !l.wants(LevelError) {
+		l.clear()
+		return
+	}
+	l.output(LevelError, 0, fmt.Sprint(v...))
 }
 
 // Errorf logs with the Error severity.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *logger) Errorf(format string, v ...interface{}) {
-	l.bindContextFields()
-	l.output(LevelError, 0, string(l.formatter.Output(l.flags, levelMap[LevelError], l.fields, fmt.Sprintf(format, v...))))
+	if !l.wants(LevelError) {
+		l.clear()
+		return
+	}
+	l.output(LevelError, 0, fmt.Sprintf(format, v...))
 }
 
 // Panic logs with the Panic severity.
 // Arguments are handled in the manner of fmt.Print.
 func (l *logger) Panic(v ...interface{}) {
-	l.bindContextFields()
 	msg := fmt.Sprint(v...)
-	l.output(LevelPanic, 0, string(l.formatter.Output(l.flags, levelMap[LevelPanic], l.fields, msg)))
+	l.output(LevelPanic, 0, msg)
 	l.Close()
 	panic(msg)
 }
@@ -474,137 +1065,278 @@ func (l *logger) Panic(v ...interface{}) {
 // Panicf logs with the Panic severity.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *logger) Panicf(format string, v ...interface{}) {
-	l.bindContextFields()
 	msg := fmt.Sprintf(format, v...)
-	l.output(LevelPanic, 0, string(l.formatter.Output(l.flags, levelMap[LevelPanic], l.fields, msg)))
+	l.output(LevelPanic, 0, msg)
 	l.Close()
 	panic(msg)
 }
 
+// AddOutput writes all further entries to w as well, rendered with the
+// logger formatter. If w is an io.Closer it is closed together with the
+// logger.
+func (l *logger) AddOutput(w io.Writer) {
+	l = l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	if l.fieldMapFor(w) != nil {
+		l.outputs = append(l.outputs, l.sinkOutput(w, l.formatter))
+	} else if len(l.outputs) > 0 {
+		l.outputs[0].addWriter(func(lvl Level) io.Writer {
+			if !l.outputAllows(w, lvl) {
+				return nil
+			}
+			return l.capped(w, w, lvl)
+		})
+	}
+	l.addCloser(w)
+}
+
 // SetLevel sets the logger verbosity level for verbose info logging.
 func (l *logger) SetLevel(lvl Level) {
-	l.level = lvl
+	if l.name != "" {
+		l.top().SetNamedLevel(l.name, lvl)
+		return
+	}
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	l.top().storeLevel(lvl)
 }
 
 func (l *logger) SetFlags(flag int) {
-	if !l.formatter.HasFlags() {
-		l.debugLog.SetFlags(flag)
-		l.infoLog.SetFlags(flag)
-		l.warningLog.SetFlags(flag)
-		l.errorLog.SetFlags(flag)
-		l.panicLog.SetFlags(flag)
-		l.fatalLog.SetFlags(flag)
+	l = l.top()
+	for _, o := range l.outputs {
+		o.setFlags(flag)
 	}
 
 	l.flags = flag
 }
 
-// With sets context fields
+// With returns a logger adding the fields to all its entries, on top of
+// those of l, e.g. a logger of a request:
+//
+//	reqLog := logger.With(log.LogFields{"request_id": id})
+//
+// l is not changed. The logger shares the outputs and the state of l, its
+// context is the one of l. With WithMutableWith the fields are added to l
+// instead, for its next entry only.
 func (l *logger) With(fields LogFields) Logger {
-	l.fields = l.fields.Add(fields)
+	return l.with(fields)
+}
 
-	return l
+// WithFields is With for typed fields, e.g.
+//
+//	logger.WithFields(log.String("path", r.URL.Path), log.Int("status", 200), log.Err(err))
+//
+// The fields are rendered in the given order. The logger keeps them typed,
+// formatters like JsonFormatter render them without allocating.
+func (l *logger) WithFields(fields ...Field) Logger {
+	return l.withFields(fields)
 }
 
-// With uses the default logger and store global fields from context
+// with implements With, it is called by With and by the function of the
+// same name, so the caller of With is two frames up.
+func (l *logger) with(fields LogFields) Logger {
+	if l.top().mutableWith {
+		l.addFields(l.grouped(fields), func() string { return callerSource(4) })
+		return l
+	}
+
+	fields = l.grouped(fields)
+	c := l.derive()
+	c.ctx = l.ctx
+	c.bind(fields)
+	if l.provenance != nil {
+		c.provenance = l.provenance.bind(fields, callerSource(2))
+	}
+
+	return c
+}
+
+// withFields implements WithFields like with implements With.
+func (l *logger) withFields(fields []Field) Logger {
+	if l.top().mutableWith {
+		l.addFields(l.grouped(fieldsOf(fields)), func() string { return callerSource(4) })
+		return l
+	}
+
+	c := l.derive()
+	c.ctx = l.ctx
+	c.typed = addTyped(l.typed, fields, l.group)
+	if l.provenance != nil {
+		c.provenance = l.provenance.bind(l.grouped(fieldsOf(fields)), callerSource(2))
+	}
+
+	return c
+}
+
+// WithContextFields adds the fields of ctx and fields to the following
+// entries of the logger.
 func (l *logger) WithContextFields(ctx context.Context, fields LogFields) Logger {
-	l.ctx = context.WithValue(ctx, keyContextFields, fields)
+	l.ctx = ContextWithFields(ctx, fields)
 	return l
 }
 
+// Enabled reports whether entries of the level pass the level of the
+// default logger.
+func Enabled(lvl Level) bool {
+	return defaultLogger.Enabled(lvl)
+}
+
+// DebugEnabled reports whether debug entries pass the level of the default
+// logger.
+func DebugEnabled() bool {
+	return defaultLogger.DebugEnabled()
+}
+
 // SetFlags sets the output flags for the logger.
 func SetFlags(flag int) {
 	defaultLogger.SetFlags(flag)
 }
 
+// Raw writes a preformatted line with the default logger.
+func Raw(lvl Level, line []byte) {
+	defaultLogger.Raw(lvl, line)
+}
+
+// AddOutput adds w to the outputs of the default logger.
+func AddOutput(w io.Writer) {
+	defaultLogger.AddOutput(w)
+}
+
 // SetLevel sets the verbosity level for verbose info logging in the
 // default logger.
 func SetLevel(lvl Level) {
 	defaultLogger.SetLevel(lvl)
 }
 
+// Trace uses the default logger, logs with Trace severity.
+// Arguments are handled in the manner of fmt.Print.
+func Trace(v ...interface{}) {
+	if !defaultLogger.wants(LevelTrace) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelTrace, 0, fmt.Sprint(v...))
+}
+
+// Tracef uses the default logger, logs with Trace severity.
+// Arguments are handled in the manner of fmt.Printf.
+func Tracef(format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelTrace) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelTrace, 0, fmt.Sprintf(format, v...))
+}
+
 // Debug uses the default logger, logs with Debug severity.
 // Arguments are handled in the manner of fmt.Print.
 func Debug(v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelDebug, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelDebug], defaultLogger.fields, fmt.Sprint(v...))))
+	if !defaultLogger.wants(LevelDebug) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelDebug, 0, fmt.Sprint(v...))
 }
 
 // Debugf uses the default logger, logs with Debug severity.
 // Arguments are handled in the manner of fmt.Printf.
 func Debugf(format string, v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelDebug, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelDebug], defaultLogger.fields, fmt.Sprintf(format, v...))))
+	if !defaultLogger.wants(LevelDebug) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelDebug, 0, fmt.Sprintf(format, v...))
 }
 
 // Info uses the default logger and logs with the Info severity.
 // Arguments are handled in the manner of fmt.Print.
 func Info(v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelInfo, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelInfo], defaultLogger.fields, fmt.Sprint(v...))))
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelInfo, 0, fmt.Sprint(v...))
 }
 
 // Infof uses the default logger and logs with the Info severity.
 // Arguments are handled in the manner of fmt.Printf.
 func Infof(format string, v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelInfo, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelInfo], defaultLogger.fields, fmt.Sprintf(format, v...))))
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelInfo, 0, fmt.Sprintf(format, v...))
 }
 
 // Warning uses the default logger and logs with the Warning severity.
 // Arguments are handled in the manner of fmt.Print.
 func Warning(v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelWaring, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelWaring], defaultLogger.fields, fmt.Sprint(v...))))
+	if !defaultLogger.wants(LevelWarning) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelWarning, 0, fmt.Sprint(v...))
 }
 
 // Warningf uses the default logger and logs with the Warning severity.
 // Arguments are handled in the manner of fmt.Printf.
 func Warningf(format string, v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelWaring, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelWaring], defaultLogger.fields, fmt.Sprintf(format, v...))))
+	if !defaultLogger.wants(LevelWarning) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelWarning, 0, fmt.Sprintf(format, v...))
 }
 
 // Fatal uses the default logger, logs with the Fatal severity,
-// and ends with os.Exit(1).
+// and ends with os.Exit using the code set with WithFatalExitCode.
 // Arguments are handled in the manner of fmt.Print.
 func Fatal(v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelFatal, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelFatal], defaultLogger.fields, fmt.Sprint(v...))))
-	defaultLogger.Close()
-	os.Exit(1)
+	defaultLogger.fatal(defaultLogger.exitCode, fmt.Sprint(v...))
 }
 
 // Fatalf uses the default logger, logs with the Fatal severity,
-// and ends with os.Exit(1).
+// and ends with os.Exit using the code set with WithFatalExitCode.
 // Arguments are handled in the manner of fmt.Printf.
 func Fatalf(format string, v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelFatal, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelFatal], defaultLogger.fields, fmt.Sprintf(format, v...))))
-	defaultLogger.Close()
-	os.Exit(1)
+	defaultLogger.fatal(defaultLogger.exitCode, fmt.Sprintf(format, v...))
+}
+
+// FatalCode uses the default logger, logs with the Fatal severity,
+// and ends with os.Exit(code).
+// Arguments are handled in the manner of fmt.Print.
+func FatalCode(code int, v ...interface{}) {
+	defaultLogger.fatal(code, fmt.Sprint(v...))
 }
 
 // Error uses the default logger and logs with the Error severity.
 // Arguments are handled in the manner of fmt.Print.
 func Error(v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelError, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelError], defaultLogger.fields, fmt.Sprint(v...))))
+	if !defaultLogger.wants(LevelError) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelError, 0, fmt.Sprint(v...))
 }
 
 // Errorf uses the default logger and logs with the Error severity.
 // Arguments are handled in the manner of fmt.Printf.
 func Errorf(format string, v ...interface{}) {
-	defaultLogger.bindContextFields()
-	defaultLogger.output(LevelError, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelError], defaultLogger.fields, fmt.Sprintf(format, v...))))
+	if !defaultLogger.wants(LevelError) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelError, 0, fmt.Sprintf(format, v...))
 }
 
 // Panic uses the default logger and logs with the Panic severity.
 // Arguments are handled in the manner of fmt.Print.
 func Panic(v ...interface{}) {
-	defaultLogger.bindContextFields()
 	msg := fmt.Sprint(v...)
-	defaultLogger.output(LevelPanic, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelPanic], defaultLogger.fields, msg)))
+	defaultLogger.output(LevelPanic, 0, msg)
 	defaultLogger.Close()
 	panic(msg)
 }
@@ -612,21 +1344,27 @@ func Panic(v ...interface{}) {
 // Panicf uses the default logger and logs with the Panic severity.
 // Arguments are handled in the manner of fmt.Printf.
 func Panicf(format string, v ...interface{}) {
-	defaultLogger.bindContextFields()
 	msg := fmt.Sprintf(format, v...)
-	defaultLogger.output(LevelPanic, 0, string(defaultLogger.formatter.Output(defaultLogger.flags, levelMap[LevelPanic], defaultLogger.fields, msg)))
+	defaultLogger.output(LevelPanic, 0, msg)
 	defaultLogger.Close()
 	panic(msg)
 }
 
-// With uses the default logger and store context fields for log
+// With returns a logger of the default logger adding the fields to its
+// entries, see Logger.With.
 func With(fields LogFields) Logger {
-	defaultLogger.With(fields)
-	return defaultLogger
+	return defaultLogger.with(fields)
+}
+
+// WithFields returns a logger of the default logger adding the typed
+// fields to its entries, see Logger.WithFields.
+func WithFields(fields ...Field) Logger {
+	return defaultLogger.withFields(fields)
 }
 
-// With uses the default logger and store global fields from context
+// WithContextFields uses the default logger and adds the fields of ctx and
+// fields to its following entries.
 func WithContextFields(ctx context.Context, fields LogFields) Logger {
-	defaultLogger.ctx = context.WithValue(ctx, keyContextFields, fields)
+	defaultLogger.ctx = ContextWithFields(ctx, fields)
 	return defaultLogger
 }
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+func systemLogAvailable() (bool, string) {
+	return false, fmt.Sprintf("system log is not supported on %s", runtime.GOOS)
+}
+
+func setup(src string, priority map[Level]int, facility int) (map[Level]io.Writer, error) {
+	return nil, fmt.Errorf("system log is not supported on %s", runtime.GOOS)
+}
@@ -1,32 +1,100 @@
+//go:build linux || darwin || freebsd
 // +build linux darwin freebsd
 
 package log
 
 import (
+	"io"
 	"log/syslog"
 )
 
-func setup(src string) (*syslog.Writer, *syslog.Writer, *syslog.Writer, *syslog.Writer, *syslog.Writer, error) {
-	const facility = syslog.LOG_USER
-	dl, err := syslog.New(facility|syslog.LOG_DEBUG, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+// syslogPriority maps levels to syslog severities by default.
+var syslogPriority = map[Level]syslog.Priority{
+	LevelTrace:   syslog.LOG_DEBUG,
+	LevelDebug:   syslog.LOG_NOTICE,
+	LevelInfo:    syslog.LOG_NOTICE,
+	LevelWarning: syslog.LOG_WARNING,
+	LevelError:   syslog.LOG_ERR,
+	LevelPanic:   syslog.LOG_CRIT,
+	LevelFatal:   syslog.LOG_ERR,
+}
+
+// WithSeverityMapping sets the syslog severities of levels, e.g.
+// map[Level]syslog.Priority{LevelInfo: syslog.LOG_INFO}. Levels not in the
+// mapping keep their default: trace goes to LOG_DEBUG, debug and info to
+// LOG_NOTICE, warning to LOG_WARNING, error and fatal to LOG_ERR and panic
+// to LOG_CRIT. Levels added with RegisterLevel before the logger is created
+// can be mapped as well, by default they use the priority given to
+// RegisterLevel. Levels added later go to the severity of their base level.
+func WithSeverityMapping(m map[Level]syslog.Priority) LogOption {
+	return func(l *logger) {
+		if l.sysPriority == nil {
+			l.sysPriority = map[Level]int{}
+		}
+		for lvl, pri := range m {
+			l.sysPriority[lvl] = int(pri & 0x07)
+		}
 	}
-	il, err := syslog.New(facility|syslog.LOG_NOTICE, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+}
+
+// WithSyslogFacility sets the syslog facility, e.g. syslog.LOG_LOCAL0,
+// instead of LOG_USER.
+func WithSyslogFacility(facility syslog.Priority) LogOption {
+	return func(l *logger) {
+		l.sysFacility = int(facility &^ 0x07)
 	}
-	wl, err := syslog.New(facility|syslog.LOG_WARNING, src)
+}
+
+func systemLogAvailable() (bool, string) {
+	w, err := syslog.Dial("", "", syslog.LOG_USER|syslog.LOG_DEBUG, "")
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return false, "syslog: " + err.Error()
 	}
-	el, err := syslog.New(facility|syslog.LOG_ERR, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+	w.Close()
+
+	return true, "syslog"
+}
+
+// syslogDial connects to syslog, tests replace it.
+var syslogDial = func(priority syslog.Priority, tag string) (io.WriteCloser, error) {
+	return syslog.New(priority, tag)
+}
+
+// setup connects to syslog once for every severity used by the levels,
+// those added with RegisterLevel use their own syslog priority.
+func setup(src string, priority map[Level]int, facility int) (map[Level]io.Writer, error) {
+	fac := syslog.LOG_USER
+	if facility >= 0 {
+		fac = syslog.Priority(facility)
 	}
-	pl, err := syslog.New(facility|syslog.LOG_CRIT, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+
+	levels := make(map[Level]syslog.Priority, len(syslogPriority)+len(customBase))
+	for lvl, pri := range syslogPriority {
+		levels[lvl] = pri
+	}
+	for lvl := range customBase {
+		levels[lvl] = syslog.Priority(syslogSeverity[lvl])
 	}
-	return dl, il, wl, el, pl, nil
+
+	conns := map[syslog.Priority]io.WriteCloser{}
+	writers := make(map[Level]io.Writer, len(levels))
+	for lvl, pri := range levels {
+		if p, ok := priority[lvl]; ok {
+			pri = syslog.Priority(p)
+		}
+		w, ok := conns[pri]
+		if !ok {
+			var err error
+			if w, err = syslogDial(fac|pri, src); err != nil {
+				for _, c := range conns {
+					c.Close()
+				}
+				return nil, err
+			}
+			conns[pri] = w
+		}
+		writers[lvl] = w
+	}
+
+	return writers, nil
 }
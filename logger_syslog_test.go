@@ -0,0 +1,78 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/syslog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSyslog struct {
+	bytes.Buffer
+}
+
+func (w *fakeSyslog) Close() error {
+	return nil
+}
+
+// fakeSyslogDial makes setup connect to fake writers, keyed by priority,
+// until the returned function restores syslogDial.
+func fakeSyslogDial() (map[syslog.Priority]*fakeSyslog, func()) {
+	conns := map[syslog.Priority]*fakeSyslog{}
+	dial := syslogDial
+	syslogDial = func(priority syslog.Priority, tag string) (io.WriteCloser, error) {
+		w := &fakeSyslog{}
+		conns[priority] = w
+		return w, nil
+	}
+
+	return conns, func() { syslogDial = dial }
+}
+
+func TestSeverityMapping(t *testing.T) {
+	var l logger
+	WithSeverityMapping(map[Level]syslog.Priority{LevelInfo: syslog.LOG_INFO, LevelWarning: syslog.LOG_LOCAL1 | syslog.LOG_ERR})(&l)
+	WithSyslogFacility(syslog.LOG_LOCAL3 | syslog.LOG_DEBUG)(&l)
+	assert.Equal(t, map[Level]int{LevelInfo: int(syslog.LOG_INFO), LevelWarning: int(syslog.LOG_ERR)}, l.sysPriority)
+	assert.Equal(t, int(syslog.LOG_LOCAL3), l.sysFacility)
+
+	conns, restore := fakeSyslogDial()
+	defer restore()
+	sys, err := setup("log-test", l.sysPriority, l.sysFacility)
+	assert.NoError(t, err)
+	assert.Same(t, conns[syslog.LOG_LOCAL3|syslog.LOG_DEBUG], sys[LevelTrace])
+	assert.Same(t, conns[syslog.LOG_LOCAL3|syslog.LOG_NOTICE], sys[LevelDebug])
+	assert.Same(t, conns[syslog.LOG_LOCAL3|syslog.LOG_INFO], sys[LevelInfo])
+	assert.Same(t, sys[LevelWarning], sys[LevelError])
+	assert.Len(t, systemLogClosers(sys), 5)
+}
+
+func TestSyslogCustomLevel(t *testing.T) {
+	alert := Level(103)
+	if _, ok := levelMap[alert]; !ok {
+		assert.NoError(t, RegisterLevel(103, "alert", "ALERT: ", 1))
+	}
+
+	conns, restore := fakeSyslogDial()
+	defer restore()
+
+	l := New(nil, WithoutStdout(), WithFlags(Ldisable), WithSystemLog(true))
+	l.Log(alert, "disk full")
+	l.Error("failed")
+	l.Close()
+
+	assert.Equal(t, "ALERT: disk full\n", conns[syslog.LOG_USER|syslog.LOG_ALERT].String())
+	assert.Equal(t, "ERROR: failed\n", conns[syslog.LOG_USER|syslog.LOG_ERR].String())
+
+	l = New(nil, WithoutStdout(), WithFlags(Ldisable), WithSystemLog(true),
+		WithSeverityMapping(map[Level]syslog.Priority{alert: syslog.LOG_EMERG}))
+	l.Log(alert, "disk gone")
+	l.Close()
+
+	assert.Equal(t, "ALERT: disk gone\n", conns[syslog.LOG_USER|syslog.LOG_EMERG].String())
+}
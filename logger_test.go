@@ -3,6 +3,7 @@ package log
 import (
 	"bufio"
 	"bytes"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"strings"
@@ -136,3 +137,243 @@ func TestLogWithContextFields(t *testing.T) {
 		assert.Contains(t, line, "bool=true int=7 second=2 string=test struct={aa} check field")
 	}
 }
+
+func TestSecondaryOutput(t *testing.T) {
+	var text, js bytes.Buffer
+	l := New(&text, WithSecondaryOutput(&js, JsonFormatter{}))
+	l.SetFlags(Lshortfile)
+
+	l.With(LogFields{"a": 1}).Info("dual")
+
+	assert.Regexp(t, `^INFO : logger_test.go:\d+: a=1 dual\n$`, text.String())
+	assert.Regexp(t, `^{"level":"info","msg":"dual",`, js.String())
+	assert.Regexp(t, `"file":"logger_test.go:\d+"`, js.String())
+	assert.Contains(t, js.String(), `"a":1`)
+}
+
+func TestLevelOutput(t *testing.T) {
+	var all, debug, errs bytes.Buffer
+	l := New(&all, WithLevel(LevelDebug), WithLevelOutput(LevelDebug, &debug), WithLevelOutput(LevelError, &errs))
+	l.SetFlags(Ldisable)
+
+	l.Debug("trace")
+	l.Info("started")
+	l.Error("failed")
+
+	assert.Equal(t, "DEBUG: trace\nINFO : started\nERROR: failed\n", all.String())
+	assert.Equal(t, "DEBUG: trace\n", debug.String())
+	assert.Equal(t, "ERROR: failed\n", errs.String())
+}
+
+type closeCounter struct {
+	bytes.Buffer
+	closes int
+}
+
+func (w *closeCounter) Close() error {
+	w.closes++
+	return nil
+}
+
+func TestLevelOutputClosedOnce(t *testing.T) {
+	out := &closeCounter{}
+	l := New(nil, WithoutStdout(), WithLevelOutput(LevelError, out), WithLevelOutput(LevelFatal, out))
+	l.Close()
+
+	assert.Equal(t, 1, out.closes)
+}
+
+func TestOutputsClosedOnce(t *testing.T) {
+	out := &closeCounter{}
+	l := New(out, WithoutStdout(), WithOutput(out), WithLevelOutput(LevelError, out), WithSecondaryOutput(out, JsonFormatter{}))
+	l.AddOutput(out)
+	l.Close()
+
+	assert.Equal(t, 1, out.closes)
+}
+
+func TestLevelForOutput(t *testing.T) {
+	var file, warnings, js, added bytes.Buffer
+	l := New(&file, WithoutStdout(), WithLevel(LevelDebug), WithFlags(Ldisable),
+		WithOutput(&warnings), WithLevelForOutput(&warnings, LevelWarning),
+		WithSecondaryOutput(&js, JsonFormatter{}), WithLevelForOutput(&js, LevelError),
+		WithLevelForOutput(&added, LevelInfo))
+	l.AddOutput(&added)
+
+	l.Debug("details")
+	l.Info("started")
+	l.Warning("slow")
+	l.Error("failed")
+
+	assert.Equal(t, "DEBUG: details\nINFO : started\nWARN : slow\nERROR: failed\n", file.String())
+	assert.Equal(t, "WARN : slow\nERROR: failed\n", warnings.String())
+	assert.Equal(t, 1, strings.Count(js.String(), "\n"))
+	assert.Contains(t, js.String(), `"msg":"failed"`)
+	assert.Equal(t, "INFO : started\nWARN : slow\nERROR: failed\n", added.String())
+}
+
+func TestTrace(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithLevel(LevelDebug), WithFlags(Ldisable))
+
+	l.Trace("hidden")
+	l.Debug("shown")
+	l.SetLevel(LevelTrace)
+	l.Tracef("step %d", 1)
+
+	assert.Equal(t, "DEBUG: shown\nTRACE: step 1\n", out.String())
+	assert.Equal(t, "trace", LevelTrace.String())
+}
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *closeRecorder) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestPanic(t *testing.T) {
+	out := &closeRecorder{}
+	l := New(out, WithoutStdout(), WithFlags(Ldisable))
+
+	assert.PanicsWithValue(t, "boom 42", func() { l.With(LogFields{"a": 1}).Panicf("boom %d", 42) })
+	assert.Equal(t, "PANIC: a=1 boom 42\n", out.String())
+	assert.True(t, out.closed, "writers are closed before panicking")
+
+	out = &closeRecorder{}
+	l = New(out, WithoutStdout(), WithFlags(Ldisable))
+	assert.PanicsWithValue(t, "boom", func() { l.Panic("boom") })
+	assert.Equal(t, "PANIC: boom\n", out.String())
+}
+
+func TestOutputs(t *testing.T) {
+	var a, b, c bytes.Buffer
+	l := New(nil, WithOutput(&a), WithOutput(&b))
+	l.SetFlags(Ldisable)
+
+	l.Info("first")
+	l.AddOutput(&c)
+	l.Error("second")
+
+	assert.Equal(t, "INFO : first\nERROR: second\n", a.String())
+	assert.Equal(t, a.String(), b.String())
+	assert.Equal(t, "ERROR: second\n", c.String())
+}
+
+func TestWithoutStdout(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	console, err := ioutil.TempFile(t.TempDir(), "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer console.Close()
+	os.Stdout, os.Stderr = console, console
+
+	var file bytes.Buffer
+	l := New(&file, WithoutStdout())
+	l.SetFlags(Ldisable)
+	l.Info("quiet")
+	l.Error("quiet")
+
+	New(&bytes.Buffer{}, WithConsole(true)).Info("loud")
+
+	written, _ := ioutil.ReadFile(console.Name())
+	assert.Equal(t, "INFO : quiet\nERROR: quiet\n", file.String())
+	assert.Contains(t, string(written), "loud")
+	assert.NotContains(t, string(written), "quiet")
+}
+
+func TestStderrOnly(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	dir := t.TempDir()
+	out, err := ioutil.TempFile(dir, "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	errs, err := ioutil.TempFile(dir, "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer errs.Close()
+	os.Stdout, os.Stderr = out, errs
+
+	l := New(nil, WithStderrOnly(), WithFlags(Ldisable))
+	l.Info("info")
+	l.Error("error")
+
+	written, _ := ioutil.ReadFile(out.Name())
+	assert.Empty(t, written)
+	written, _ = ioutil.ReadFile(errs.Name())
+	assert.Equal(t, "INFO : info\nERROR: error\n", string(written))
+}
+
+func TestRaw(t *testing.T) {
+	var out, js bytes.Buffer
+	var hooked []Entry
+	l := New(&out, WithoutStdout(), WithSecondaryOutput(&js, JsonFormatter{}), WithHook(HookFunc(func(e Entry) error {
+		hooked = append(hooked, e)
+		return nil
+	})))
+
+	l.Raw(LevelInfo, []byte("[lua] script loaded\n"))
+	l.Raw(LevelWarning, []byte("[lua] slow tick"))
+	l.Raw(LevelDebug, []byte("[lua] filtered"))
+
+	assert.Equal(t, "[lua] script loaded\n[lua] slow tick\n", out.String())
+	assert.Equal(t, out.String(), js.String())
+	if assert.Len(t, hooked, 2) {
+		assert.Equal(t, "[lua] script loaded", hooked[0].Message)
+		assert.Equal(t, LevelWarning, hooked[1].Level)
+	}
+}
+
+type countingStringer struct {
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "expensive"
+}
+
+func TestEnabled(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithLevel(LevelInfo))
+
+	assert.True(t, l.Enabled(LevelInfo))
+	assert.False(t, l.Enabled(LevelDebug))
+	assert.False(t, l.DebugEnabled())
+
+	s := &countingStringer{}
+	l.With(LogFields{"a": 1}).Debugf("%v", s)
+	l.Info("next")
+	assert.Equal(t, 0, s.calls, "filtered messages are not formatted")
+	assert.Equal(t, "INFO : next\n", out.String(), "fields of filtered entries are dropped")
+
+	l.SetLevel(LevelDebug)
+	assert.True(t, l.DebugEnabled())
+	assert.False(t, NewNopLogger().Enabled(LevelFatal))
+}
+
+func TestFatalExitCode(t *testing.T) {
+	var codes []int
+	osExit = func(code int) { codes = append(codes, code) }
+	defer func() { osExit = os.Exit }()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Lshortfile), WithFatalExitCode(3))
+	l.Fatal("config missing")
+	// Fatal closes the logger, the next one logs the second failure
+	l = New(&out, WithoutStdout(), WithFlags(Lshortfile))
+	l.FatalCode(75, "database unavailable")
+
+	assert.Equal(t, []int{3, 75}, codes)
+	assert.Contains(t, out.String(), "logger_test.go:")
+	assert.Contains(t, out.String(), "database unavailable")
+}
@@ -2,36 +2,91 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
+const eventLogKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application`
+
+// systemLogAvailable checks if new event log sources can be registered,
+// which requires administrative permissions. Sources registered earlier
+// can still be used without them.
+func systemLogAvailable() (bool, string) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, eventLogKey, registry.CREATE_SUB_KEY)
+	if err != nil {
+		return false, fmt.Sprintf("event log: can not register sources: %v", err)
+	}
+	k.Close()
+
+	return true, "event log"
+}
+
+// EventType is the type of an event log entry.
+type EventType uint32
+
+// Event log entry types.
+const (
+	EventInfo    = EventType(eventlog.Info)
+	EventWarning = EventType(eventlog.Warning)
+	EventError   = EventType(eventlog.Error)
+)
+
+// eventType maps levels to event log entry types by default.
+var eventType = map[Level]EventType{
+	LevelTrace:   EventInfo,
+	LevelDebug:   EventInfo,
+	LevelInfo:    EventInfo,
+	LevelWarning: EventWarning,
+	LevelError:   EventError,
+	LevelPanic:   EventError,
+	LevelFatal:   EventError,
+}
+
+// WithEventLogMapping sets the event log entry types of levels, e.g.
+// map[Level]EventType{LevelWarning: EventError}. Levels not in the mapping
+// keep their default: trace, debug and info are EventInfo, warning is
+// EventWarning and the more severe levels are EventError. Levels added with
+// RegisterLevel before the logger is created can be mapped as well, by
+// default they use the type of their base level.
+func WithEventLogMapping(m map[Level]EventType) LogOption {
+	return func(l *logger) {
+		if l.sysPriority == nil {
+			l.sysPriority = map[Level]int{}
+		}
+		for lvl, typ := range m {
+			l.sysPriority[lvl] = int(typ)
+		}
+	}
+}
+
 type writer struct {
-	pri severity
+	typ EventType
 	src string
 	el  *eventlog.Log
 }
 
 // Write sends a log message to the Event Log.
 func (w *writer) Write(b []byte) (int, error) {
-	switch w.pri {
-	case LevelDebug, LevelInfo:
+	switch w.typ {
+	case EventInfo:
 		return len(b), w.el.Info(1, string(b))
-	case LevelWarning:
+	case EventWarning:
 		return len(b), w.el.Warning(3, string(b))
-	case LevelError, LevelPanic, LevelFatal:
+	case EventError:
 		return len(b), w.el.Error(2, string(b))
 	}
-	return 0, fmt.Errorf("unrecognized severity: %v", w.pri)
+	return 0, fmt.Errorf("unrecognized event type: %v", w.typ)
 }
 
 func (w *writer) Close() error {
 	return w.el.Close()
 }
 
-func newW(pri severity, src string) (*writer, error) {
+func newW(typ EventType, src string) (*writer, error) {
 	// Continue if we receive "registry key already exists" or if we get
 	// ERROR_ACCESS_DENIED so that we can log without administrative permissions
 	// for pre-existing eventlog sources.
@@ -45,32 +100,42 @@ func newW(pri severity, src string) (*writer, error) {
 		return nil, err
 	}
 	return &writer{
-		pri: pri,
+		typ: typ,
 		src: src,
 		el:  el,
 	}, nil
 }
 
-func setup(src string) (*writer, *writer, *writer, *writer, *writer, error) {
-	debugL, err := newW(LevelDebug, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+// setup opens the event log once for every entry type used by the levels,
+// including those added with RegisterLevel. The facility does not apply.
+func setup(src string, priority map[Level]int, facility int) (map[Level]io.Writer, error) {
+	levels := make(map[Level]EventType, len(eventType)+len(customBase))
+	for lvl, typ := range eventType {
+		levels[lvl] = typ
 	}
-	infoL, err := newW(LevelInfo, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+	for lvl, base := range customBase {
+		levels[lvl] = eventType[base]
 	}
-	warningL, err := newW(LevelWarning, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
-	}
-	errL, err := newW(LevelError, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
-	}
-	panicL, err := newW(LevelPanic, src)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+
+	opened := map[EventType]*writer{}
+	writers := make(map[Level]io.Writer, len(levels))
+	for lvl, typ := range levels {
+		if t, ok := priority[lvl]; ok {
+			typ = EventType(t)
+		}
+		w, ok := opened[typ]
+		if !ok {
+			var err error
+			if w, err = newW(typ, src); err != nil {
+				for _, o := range opened {
+					o.Close()
+				}
+				return nil, err
+			}
+			opened[typ] = w
+		}
+		writers[lvl] = w
 	}
-	return debugL, infoL, warningL, errL, panicL, nil
+
+	return writers, nil
 }
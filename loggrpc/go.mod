@@ -0,0 +1,20 @@
+module github.com/bialas1993/log/loggrpc
+
+go 1.21
+
+require (
+	github.com/bialas1993/log v0.0.0-20261016150051-f247064be79f
+	google.golang.org/grpc v1.65.0
+)
+
+require (
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
+	google.golang.org/protobuf v1.34.1 // indirect
+)
+
+// builds against the repository root, the module is used from a checkout
+// until it is released with a tagged root version, see Modules in README.md
+replace github.com/bialas1993/log => ../
@@ -0,0 +1,114 @@
+// Package loggrpc provides gRPC server interceptors logging through
+// github.com/bialas1993/log loggers. It is a separate module, so only
+// programs using it depend on gRPC.
+//
+//	srv := grpc.NewServer(
+//		grpc.UnaryInterceptor(loggrpc.UnaryServerInterceptor(logger)),
+//		grpc.StreamInterceptor(loggrpc.StreamServerInterceptor(logger)),
+//	)
+package loggrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/bialas1993/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Fields added to the entries of a call.
+const (
+	MethodField   = "grpc.method"
+	CodeField     = "grpc.code"
+	DurationField = "grpc.duration"
+	PeerField     = "peer.address"
+)
+
+// CodeLevel returns the level of the entry logged for a call finished with
+// code: client mistakes are logged at the Info level, conditions worth a
+// look at the Warning level and server faults at the Error level.
+func CodeLevel(code codes.Code) log.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return log.LevelInfo
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return log.LevelWarning
+	}
+
+	return log.LevelError
+}
+
+// UnaryServerInterceptor logs every unary call with its method, code,
+// duration and peer. The handler gets a context carrying l and the method
+// and peer fields, so entries logged with
+//
+//	log.FromContext(ctx).InfoCtx(ctx, "loading order")
+//
+// can be matched with the call.
+func UnaryServerInterceptor(l log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		fields := callFields(ctx, info.FullMethod)
+
+		resp, err := handler(callContext(ctx, l, fields), req)
+		finish(l, fields, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs every stream once it ends, as
+// UnaryServerInterceptor does for unary calls. The context of the stream
+// carries l and the fields of the call.
+func StreamServerInterceptor(l log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		fields := callFields(ss.Context(), info.FullMethod)
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: callContext(ss.Context(), l, fields)})
+		finish(l, fields, start, err)
+
+		return err
+	}
+}
+
+// serverStream replaces the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func callFields(ctx context.Context, method string) log.LogFields {
+	fields := log.LogFields{MethodField: method}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[PeerField] = p.Addr.String()
+	}
+
+	return fields
+}
+
+func callContext(ctx context.Context, l log.Logger, fields log.LogFields) context.Context {
+	return log.IntoContext(log.ContextWithFields(ctx, fields), l)
+}
+
+func finish(l log.Logger, fields log.LogFields, start time.Time, err error) {
+	code := status.Code(err)
+	result := log.LogFields{
+		CodeField:     code.String(),
+		DurationField: time.Since(start).String(),
+	}
+	if err != nil {
+		result = result.Add(log.ErrFields(err))
+	}
+
+	l.With(fields.Add(result)).Log(CodeLevel(code), "finished call")
+}
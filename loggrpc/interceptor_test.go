@@ -0,0 +1,107 @@
+package loggrpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bialas1993/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var out bytes.Buffer
+	l := log.New(&out, log.WithoutStdout(), log.WithFlags(0))
+	intercept := UnaryServerInterceptor(l)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 5300}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}
+
+	_, err := intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		log.FromContext(ctx).InfoCtx(ctx, "loading order")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "db down")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("got %v, want the handler error", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %q", out.String())
+	}
+	for _, want := range []string{"INFO : ", "grpc.method=/orders.Orders/Get", "peer.address=10.0.0.7:5300", "loading order"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("handler entry %q misses %q", lines[0], want)
+		}
+	}
+	for _, want := range []string{"INFO : ", "grpc.code=OK", "grpc.duration=", "finished call"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("call entry %q misses %q", lines[1], want)
+		}
+	}
+	for _, want := range []string{"ERROR: ", "grpc.code=Internal", "error.kind=internal", "peer.address=10.0.0.7:5300"} {
+		if !strings.Contains(lines[2], want) {
+			t.Errorf("failed call entry %q misses %q", lines[2], want)
+		}
+	}
+}
+
+type fakeStream struct {
+	ctx context.Context
+}
+
+func (s fakeStream) SetHeader(metadata.MD) error  { return nil }
+func (s fakeStream) SendHeader(metadata.MD) error { return nil }
+func (s fakeStream) SetTrailer(metadata.MD)       {}
+func (s fakeStream) Context() context.Context     { return s.ctx }
+func (s fakeStream) SendMsg(interface{}) error    { return nil }
+func (s fakeStream) RecvMsg(interface{}) error    { return nil }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var out bytes.Buffer
+	l := log.New(&out, log.WithoutStdout(), log.WithFlags(0))
+	intercept := StreamServerInterceptor(l)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/orders.Orders/Watch", IsServerStream: true}
+	err := intercept(nil, fakeStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		log.FromContext(ss.Context()).DebugCtx(ss.Context(), "hidden")
+		return status.Error(codes.Unavailable, "draining")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("got %v, want the handler error", err)
+	}
+
+	got := strings.TrimSpace(out.String())
+	for _, want := range []string{"WARN : ", "grpc.method=/orders.Orders/Watch", "grpc.code=Unavailable", "finished call"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("entry %q misses %q", got, want)
+		}
+	}
+}
+
+func TestCodeLevel(t *testing.T) {
+	for code, want := range map[codes.Code]log.Level{
+		codes.OK:               log.LevelInfo,
+		codes.NotFound:         log.LevelInfo,
+		codes.DeadlineExceeded: log.LevelWarning,
+		codes.Internal:         log.LevelError,
+		codes.Unknown:          log.LevelError,
+		codes.DataLoss:         log.LevelError,
+	} {
+		if got := CodeLevel(code); got != want {
+			t.Errorf("CodeLevel(%v) = %v, want %v", code, got, want)
+		}
+	}
+}
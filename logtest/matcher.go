@@ -0,0 +1,135 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bialas1993/log"
+)
+
+// TestingT is the subset of testing.T used by the assertion helpers, it is
+// also satisfied by testify's assert.TestingT implementations.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type helper interface {
+	Helper()
+}
+
+// EntryMatcher matches recorded entries by level, message and fields. It
+// implements the Gomega matcher interface, so it can be used as
+// Expect(recorder).To(logtest.HaveEntry(log.LevelError, "failed", nil)).
+type EntryMatcher struct {
+	Level   log.Level
+	Message string
+	Fields  log.LogFields
+}
+
+// HaveEntry returns a matcher for entries with the level, a message
+// containing substr and all of the given fields.
+func HaveEntry(lvl log.Level, substr string, fields log.LogFields) *EntryMatcher {
+	return &EntryMatcher{Level: lvl, Message: substr, Fields: fields}
+}
+
+func (m *EntryMatcher) filter(e Entries) Entries {
+	e = e.WithLevel(m.Level).WithMessage(m.Message)
+	for key, value := range m.Fields {
+		e = e.WithField(key, value)
+	}
+
+	return e
+}
+
+// Match accepts a *Recorder, Entries or []log.Entry.
+func (m *EntryMatcher) Match(actual interface{}) (bool, error) {
+	entries, err := toEntries(actual)
+	if err != nil {
+		return false, err
+	}
+
+	return m.filter(entries).Count() > 0, nil
+}
+
+func (m *EntryMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %s\nto be logged, got:\n%s", m, describe(actual))
+}
+
+func (m *EntryMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %s\nnot to be logged, got:\n%s", m, describe(actual))
+}
+
+func (m *EntryMatcher) String() string {
+	return fmt.Sprintf("%s entry containing %q with fields %v", m.Level, m.Message, m.Fields)
+}
+
+// AssertEntry fails the test unless actual contains an entry matching
+// HaveEntry(lvl, substr, fields).
+func AssertEntry(t TestingT, actual interface{}, lvl log.Level, substr string, fields log.LogFields) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+
+	m := HaveEntry(lvl, substr, fields)
+	ok, err := m.Match(actual)
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	if !ok {
+		t.Errorf("%s", m.FailureMessage(actual))
+	}
+
+	return ok
+}
+
+// AssertNoEntry fails the test if actual contains an entry matching
+// HaveEntry(lvl, substr, fields).
+func AssertNoEntry(t TestingT, actual interface{}, lvl log.Level, substr string, fields log.LogFields) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+
+	m := HaveEntry(lvl, substr, fields)
+	ok, err := m.Match(actual)
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	if ok {
+		t.Errorf("%s", m.NegatedFailureMessage(actual))
+	}
+
+	return !ok
+}
+
+func toEntries(actual interface{}) (Entries, error) {
+	switch a := actual.(type) {
+	case *Recorder:
+		return a.Entries(), nil
+	case Entries:
+		return a, nil
+	case []log.Entry:
+		return a, nil
+	}
+
+	return nil, fmt.Errorf("logtest: expected *Recorder or Entries, got %T", actual)
+}
+
+func describe(actual interface{}) string {
+	entries, err := toEntries(actual)
+	if err != nil {
+		return err.Error()
+	}
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		fields := make([]string, 0, len(e.Fields))
+		for _, k := range e.Fields.Keys() {
+			fields = append(fields, fmt.Sprintf("%s:%v", k, e.Fields[k]))
+		}
+		lines[i] = fmt.Sprintf("\t%s: %s map[%s]", e.Level, e.Message, strings.Join(fields, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,70 @@
+package logtest
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/bialas1993/log"
+)
+
+// Entries is a list of recorded entries. Filters return a new list so they
+// can be chained, e.g. r.Entries().WithLevel(log.LevelError).WithField("user_id", 42).Count().
+type Entries []log.Entry
+
+// Filter returns entries for which match returns true.
+func (e Entries) Filter(match func(log.Entry) bool) Entries {
+	var res Entries
+	for _, entry := range e {
+		if match(entry) {
+			res = append(res, entry)
+		}
+	}
+
+	return res
+}
+
+// WithLevel returns entries logged with the given level.
+func (e Entries) WithLevel(lvl log.Level) Entries {
+	return e.Filter(func(entry log.Entry) bool {
+		return entry.Level == lvl
+	})
+}
+
+// WithMessage returns entries whose message contains substr.
+func (e Entries) WithMessage(substr string) Entries {
+	return e.Filter(func(entry log.Entry) bool {
+		return strings.Contains(entry.Message, substr)
+	})
+}
+
+// WithField returns entries having the field set to value. Values are
+// compared with reflect.DeepEqual, so types must match.
+func (e Entries) WithField(key string, value interface{}) Entries {
+	return e.Filter(func(entry log.Entry) bool {
+		v, ok := entry.Fields[key]
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// HasField returns entries having the field set to any value.
+func (e Entries) HasField(key string) Entries {
+	return e.Filter(func(entry log.Entry) bool {
+		_, ok := entry.Fields[key]
+		return ok
+	})
+}
+
+// Count returns the number of entries.
+func (e Entries) Count() int {
+	return len(e)
+}
+
+// Messages returns the messages of the entries.
+func (e Entries) Messages() []string {
+	msgs := make([]string, len(e))
+	for i, entry := range e {
+		msgs[i] = entry.Message
+	}
+
+	return msgs
+}
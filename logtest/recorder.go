@@ -0,0 +1,76 @@
+// Package logtest records log entries in memory so tests can assert on what
+// was logged without parsing formatted output.
+package logtest
+
+import (
+	"io/ioutil"
+	"sync"
+
+	"github.com/bialas1993/log"
+)
+
+// Recorder is a Logger which keeps every entry it logs.
+type Recorder struct {
+	log.Logger
+
+	mu      sync.Mutex
+	entries Entries
+}
+
+// NewRecorder creates a recorder using the test profile, so it logs at
+// LevelDebug, without writing to the console. Options are applied to the underlying logger.
+func NewRecorder(opts ...log.LogOption) *Recorder {
+	r := &Recorder{}
+	r.Logger = log.New(ioutil.Discard, append([]log.LogOption{
+		log.Profile(log.ProfileTest),
+		log.WithoutStdout(),
+		log.WithHook(log.HookFunc(r.record)),
+	}, opts...)...)
+
+	return r
+}
+
+func (r *Recorder) record(e log.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+
+	return nil
+}
+
+// Entries returns a copy of the recorded entries.
+func (r *Recorder) Entries() Entries {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append(Entries(nil), r.entries...)
+}
+
+// Reset drops all recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// LastEntry returns the most recent entry, false when nothing was logged.
+func (r *Recorder) LastEntry() (log.Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) == 0 {
+		return log.Entry{}, false
+	}
+
+	return r.entries[len(r.entries)-1], true
+}
+
+// AssertContains fails the test unless an entry with the level and a message
+// containing substr was recorded.
+func (r *Recorder) AssertContains(t TestingT, lvl log.Level, substr string) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+
+	return AssertEntry(t, r, lvl, substr, nil)
+}
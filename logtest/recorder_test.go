@@ -0,0 +1,74 @@
+package logtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bialas1993/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	failures []string
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRecorderQuery(t *testing.T) {
+	r := NewRecorder()
+	r.With(log.LogFields{"user_id": 42}).Error("payment failed")
+	r.With(log.LogFields{"user_id": 7}).Error("payment failed")
+	r.With(log.LogFields{"user_id": 42}).Info("payment retried")
+	r.Debug("done")
+
+	assert.Equal(t, 4, r.Entries().Count())
+	assert.Equal(t, 1, r.Entries().WithLevel(log.LevelError).WithField("user_id", 42).Count())
+	assert.Equal(t, 2, r.Entries().WithMessage("payment").WithField("user_id", 42).Count())
+	assert.Equal(t, []string{"done"}, r.Entries().WithLevel(log.LevelDebug).Messages())
+
+	r.Reset()
+	assert.Zero(t, r.Entries().Count())
+}
+
+func TestMatchers(t *testing.T) {
+	r := NewRecorder()
+	r.With(log.LogFields{"order": "A1"}).Warning("slow checkout")
+
+	AssertEntry(t, r, log.LevelWarning, "checkout", log.LogFields{"order": "A1"})
+	AssertNoEntry(t, r, log.LevelError, "", nil)
+
+	ok, err := HaveEntry(log.LevelWarning, "slow", nil).Match(r.Entries())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ft := &fakeT{}
+	assert.False(t, AssertEntry(ft, r, log.LevelError, "checkout", nil))
+	if assert.Len(t, ft.failures, 1) {
+		assert.Contains(t, ft.failures[0], "warning: slow checkout map[order:A1]")
+	}
+
+	_, err = HaveEntry(log.LevelError, "", nil).Match("text")
+	assert.Error(t, err)
+}
+
+func TestLastEntry(t *testing.T) {
+	r := NewRecorder()
+	_, ok := r.LastEntry()
+	assert.False(t, ok)
+
+	r.Info("first")
+	r.With(log.LogFields{"n": 2}).Warning("second")
+
+	e, ok := r.LastEntry()
+	assert.True(t, ok)
+	assert.Equal(t, "second", e.Message)
+	assert.Equal(t, log.LevelWarning, e.Level)
+	assert.Equal(t, log.LogFields{"n": 2}, e.Fields)
+
+	ft := &fakeT{}
+	assert.True(t, r.AssertContains(ft, log.LevelInfo, "fir"))
+	assert.False(t, r.AssertContains(ft, log.LevelError, "first"))
+	assert.Len(t, ft.failures, 1)
+}
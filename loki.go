@@ -0,0 +1,170 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LokiConfig configures a LokiSink.
+type LokiConfig struct {
+	// URL of the push API, e.g. http://loki:3100/loki/api/v1/push.
+	URL string
+	// Labels added to every stream, e.g. {"job": "api"}.
+	Labels map[string]string
+	// LabelFields lists fields promoted to stream labels, other fields are
+	// kept in the line. The level is always a label.
+	LabelFields []string
+	// Formatter renders the line, StdFormatter when nil.
+	Formatter Formatter
+	// Headers added to every request, e.g. X-Scope-OrgID or Authorization.
+	Headers map[string]string
+	// BatchSize and BatchWait control how often entries are pushed.
+	BatchSize int
+	BatchWait time.Duration
+	// MaxBuffer is the number of entries waiting to be pushed, more entries
+	// are dropped.
+	MaxBuffer int
+	Backoff   Backoff
+	Client    *http.Client
+}
+
+// LokiSink is a hook pushing entries to Grafana Loki. Use it with WithHook,
+// it is flushed and stopped when the logger is closed.
+type LokiSink struct {
+	cfg     LokiConfig
+	labels  map[string]bool
+	batcher *batcher
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// NewLokiSink creates the sink and starts its background sender.
+func NewLokiSink(cfg LokiConfig) *LokiSink {
+	if cfg.Formatter == nil {
+		cfg.Formatter = StdFormatter{}
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BatchWait <= 0 {
+		cfg.BatchWait = time.Second
+	}
+	if cfg.MaxBuffer <= 0 {
+		cfg.MaxBuffer = 10000
+	}
+	if cfg.Backoff == (Backoff{}) {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	s := &LokiSink{cfg: cfg, labels: map[string]bool{}}
+	for _, f := range cfg.LabelFields {
+		s.labels[f] = true
+	}
+	s.batcher = newBatcher("loki", cfg.BatchSize, cfg.MaxBuffer, cfg.BatchWait, s.push)
+
+	return s
+}
+
+// Fire queues the entry for the next push.
+func (s *LokiSink) Fire(e Entry) error {
+	s.batcher.add(e)
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+// or the sink was closed.
+func (s *LokiSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.batcher.dropped)
+}
+
+// Close pushes the buffered entries and stops the sender.
+func (s *LokiSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// CloseContext stops the sender like Close, giving up when ctx is done.
+func (s *LokiSink) CloseContext(ctx context.Context) error {
+	return s.batcher.closeContext(ctx)
+}
+
+// Flush pushes the entries queued so far, giving up when ctx is done.
+func (s *LokiSink) Flush(ctx context.Context) error {
+	return s.batcher.flush(ctx)
+}
+
+func (s *LokiSink) push(entries []Entry) error {
+	body, err := json.Marshal(s.request(entries))
+	if err != nil {
+		return err
+	}
+
+	return s.cfg.Backoff.retry(func() error {
+		return post(s.cfg.Client, s.cfg.URL, "application/json", s.cfg.Headers, body)
+	})
+}
+
+// request groups entries into streams by their labels.
+func (s *LokiSink) request(entries []Entry) lokiPush {
+	streams := map[string]*lokiStream{}
+	var req lokiPush
+
+	for _, e := range entries {
+		labels := map[string]string{"level": e.Level.String()}
+		for k, v := range s.cfg.Labels {
+			labels[k] = v
+		}
+		rest := LogFields{}
+		for k, v := range e.Fields {
+			if s.labels[k] {
+				labels[k] = formatValue(v)
+			} else {
+				rest[k] = v
+			}
+		}
+
+		key := labelsKey(labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			req.Streams = append(req.Streams, stream)
+		}
+
+		line := s.cfg.Formatter.Output(Ldisable, e.Level.String(), rest, e.Message)
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), line})
+	}
+
+	return req
+}
+
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "=" + strconv.Quote(labels[k]) + ",")
+	}
+
+	return b.String()
+}
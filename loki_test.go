@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLokiSink(t *testing.T) {
+	var mu sync.Mutex
+	var pushes []lokiPush
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var p lokiPush
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		pushes = append(pushes, p)
+	}))
+	defer srv.Close()
+
+	sink := NewLokiSink(LokiConfig{
+		URL:         srv.URL,
+		Labels:      map[string]string{"job": "api"},
+		LabelFields: []string{"service"},
+		Headers:     map[string]string{"X-Scope-OrgID": "tenant"},
+		BatchWait:   time.Hour,
+		Backoff:     Backoff{MaxRetries: 1, Min: time.Millisecond, Max: time.Millisecond},
+	})
+	l := New(&bytes.Buffer{}, WithHook(sink))
+	l.With(LogFields{"service": "billing", "user": 1}).Error("charge failed")
+	l.With(LogFields{"service": "billing"}).Error("refund failed")
+	l.Info("started")
+	l.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, calls)
+	if assert.Len(t, pushes, 1) && assert.Len(t, pushes[0].Streams, 2) {
+		s := pushes[0].Streams[0]
+		assert.Equal(t, map[string]string{"job": "api", "level": "error", "service": "billing"}, s.Stream)
+		assert.Len(t, s.Values, 2)
+		assert.Equal(t, "user=1 charge failed", s.Values[0][1])
+		assert.Equal(t, map[string]string{"job": "api", "level": "info"}, pushes[0].Streams[1].Stream)
+	}
+}
@@ -0,0 +1,43 @@
+package log
+
+// Middleware changes entries in flight. It calls next with the entry, the
+// same or a changed one, to pass it on, or returns without calling next to
+// drop it:
+//
+//	rename := func(e *log.Entry, next func(*log.Entry)) {
+//		if v, ok := e.Fields["service"]; ok {
+//			e.Fields["svc"] = v
+//			delete(e.Fields, "service")
+//		}
+//		next(e)
+//	}
+//
+// Middleware runs with the logger lock held and must not log through the
+// same logger.
+type Middleware func(e *Entry, next func(*Entry))
+
+// Use adds middleware to the logger, run in the order given as a stage
+// after the built-in ones, see WithStage.
+func Use(mw ...Middleware) LogOption {
+	return WithStage(chain(mw))
+}
+
+// chain returns a stage running the middleware, it drops the entry when
+// one of them doesn't call next.
+func chain(mw []Middleware) Stage {
+	return StageFunc(func(e *Entry) bool {
+		passed := false
+		var call func(i int, cur *Entry)
+		call = func(i int, cur *Entry) {
+			if i == len(mw) {
+				*e = *cur
+				passed = true
+				return
+			}
+			mw[i](cur, func(next *Entry) { call(i+1, next) })
+		}
+		call(0, e)
+
+		return passed
+	})
+}
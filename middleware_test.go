@@ -0,0 +1,51 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUse(t *testing.T) {
+	host := func(e *Entry, next func(*Entry)) {
+		e.Fields["host"] = "web-1"
+		next(e)
+	}
+	quiet := func(e *Entry, next func(*Entry)) {
+		if !strings.HasPrefix(e.Message, "heartbeat") {
+			next(e)
+		}
+	}
+	rename := func(e *Entry, next func(*Entry)) {
+		if v, ok := e.Fields["service"]; ok {
+			e.Fields["svc"] = v
+			delete(e.Fields, "service")
+		}
+		next(e)
+	}
+	replace := func(e *Entry, next func(*Entry)) {
+		c := *e
+		c.Message = strings.ToUpper(c.Message)
+		next(&c)
+	}
+
+	var out bytes.Buffer
+	var hooked []Entry
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), Use(host, quiet), Use(rename, replace),
+		WithHook(HookFunc(func(e Entry) error {
+			hooked = append(hooked, e)
+			return nil
+		})))
+
+	fields := LogFields{"service": "billing"}
+	l.With(fields).Info("started")
+	l.Info("heartbeat 1")
+
+	assert.Equal(t, "INFO : host=web-1 svc=billing STARTED\n", out.String())
+	if assert.Len(t, hooked, 1) {
+		assert.Equal(t, LogFields{"host": "web-1", "svc": "billing"}, hooked[0].Fields)
+	}
+	assert.Equal(t, LogFields{"service": "billing"}, fields, "the fields passed to With are unchanged")
+}
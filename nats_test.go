@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type natsMsg struct {
+	subject, payload string
+}
+
+// fakeNATS accepts a single client and acknowledges publishes with a reply
+// subject as JetStream does.
+func fakeNATS(t *testing.T) (string, func() []natsMsg) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	var msgs []natsMsg
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		var sid string
+		for seq := 1; ; {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			args := strings.Fields(line)
+			switch args[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "SUB":
+				sid = args[2]
+			case "PUB":
+				n, _ := strconv.Atoi(args[len(args)-1])
+				payload := make([]byte, n+2)
+				io.ReadFull(r, payload)
+				mu.Lock()
+				msgs = append(msgs, natsMsg{args[1], string(payload[:n])})
+				mu.Unlock()
+				if len(args) == 4 {
+					ack := fmt.Sprintf(`{"stream":"LOGS","seq":%d}`, seq)
+					fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", args[2], sid, len(ack), ack)
+					seq++
+				}
+			}
+		}
+	}()
+
+	return ln.Addr().String(), func() []natsMsg {
+		ln.Close()
+		<-done
+		mu.Lock()
+		defer mu.Unlock()
+		return msgs
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	addr, received := fakeNATS(t)
+
+	sink := NewNATSSink(NATSConfig{URL: "nats://token@" + addr, Service: "api.v2", JetStream: true, BatchSize: 2})
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithHook(sink))
+	l.With(LogFields{"user": "ann"}).Info("one")
+	l.Error("two")
+	l.Close()
+
+	msgs := received()
+	if assert.Len(t, msgs, 2) {
+		assert.Equal(t, "logs.api_v2.info", msgs[0].subject)
+		assert.Contains(t, msgs[0].payload, `"level":"info","msg":"one","user":"ann"}`)
+		assert.Equal(t, "logs.api_v2.error", msgs[1].subject)
+	}
+	assert.Zero(t, sink.Dropped())
+}
@@ -0,0 +1,244 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// DropPolicy decides which messages are discarded when the buffer of a
+// disconnected NetworkWriter is full.
+type DropPolicy uint8
+
+const (
+	// DropNewest discards incoming messages.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest buffered messages.
+	DropOldest
+)
+
+// NetworkConfig configures a NetworkWriter.
+type NetworkConfig struct {
+	// BufferSize is the number of messages kept while disconnected.
+	BufferSize int
+	DropPolicy DropPolicy
+	// DialTimeout of a single connection attempt.
+	DialTimeout time.Duration
+	// WriteTimeout of a single message, a stalled peer doesn't block
+	// logging longer. The connection is dropped and the message buffered.
+	WriteTimeout time.Duration
+	// ReconnectInterval is the initial wait between connection attempts, it
+	// doubles after each failure up to MaxReconnectInterval.
+	ReconnectInterval    time.Duration
+	MaxReconnectInterval time.Duration
+}
+
+// DefaultNetworkConfig is used by NewNetworkLogger.
+var DefaultNetworkConfig = NetworkConfig{
+	BufferSize:           1000,
+	DropPolicy:           DropOldest,
+	DialTimeout:          5 * time.Second,
+	WriteTimeout:         time.Second,
+	ReconnectInterval:    100 * time.Millisecond,
+	MaxReconnectInterval: 30 * time.Second,
+}
+
+var errWriterClosed = errors.New("log: writer closed")
+
+// NetworkWriter writes messages to a TCP or UDP endpoint. While the
+// connection is down messages are buffered in memory and the writer keeps
+// reconnecting in the background, so Write blocks on the network at most
+// for the write timeout.
+type NetworkWriter struct {
+	network string
+	addr    string
+	cfg     NetworkConfig
+	dial    func(timeout time.Duration) (io.WriteCloser, error)
+
+	mu           sync.Mutex
+	conn         io.WriteCloser
+	buf          [][]byte
+	dropped      int
+	reconnecting bool
+	closed       bool
+	done         chan struct{}
+}
+
+// NewNetworkWriter creates a writer for the address and starts connecting.
+func NewNetworkWriter(network, addr string, cfg NetworkConfig) *NetworkWriter {
+	return newNetworkWriter(network, addr, cfg, func(timeout time.Duration) (io.WriteCloser, error) {
+		return net.DialTimeout(network, addr, timeout)
+	})
+}
+
+// NewSocketWriter creates a writer for a unix domain socket, or a named
+// pipe such as \\.\pipe\collector on Windows, and starts connecting. It
+// buffers and reconnects as a NetworkWriter.
+func NewSocketWriter(path string, cfg NetworkConfig) *NetworkWriter {
+	return newNetworkWriter("unix", path, cfg, func(timeout time.Duration) (io.WriteCloser, error) {
+		return dialSocket(path, timeout)
+	})
+}
+
+func newNetworkWriter(network, addr string, cfg NetworkConfig, dial func(time.Duration) (io.WriteCloser, error)) *NetworkWriter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultNetworkConfig.BufferSize
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = DefaultNetworkConfig.WriteTimeout
+	}
+	if cfg.ReconnectInterval <= 0 {
+		cfg.ReconnectInterval = DefaultNetworkConfig.ReconnectInterval
+	}
+	if cfg.MaxReconnectInterval < cfg.ReconnectInterval {
+		cfg.MaxReconnectInterval = cfg.ReconnectInterval
+	}
+
+	w := &NetworkWriter{network: network, addr: addr, cfg: cfg, dial: dial, done: make(chan struct{})}
+	w.mu.Lock()
+	w.reconnect()
+	w.mu.Unlock()
+
+	return w
+}
+
+// NewNetworkLogger creates a logger shipping lines to a TCP or UDP endpoint,
+// e.g. a central rsyslog, using DefaultNetworkConfig.
+func NewNetworkLogger(network, addr string, opts ...LogOption) Logger {
+	return New(NewNetworkWriter(network, addr, DefaultNetworkConfig), opts...)
+}
+
+// NewSocketLogger creates a logger writing lines to a unix domain socket or
+// Windows named pipe of a sidecar collector, using DefaultNetworkConfig.
+func NewSocketLogger(path string, opts ...LogOption) Logger {
+	return New(NewSocketWriter(path, DefaultNetworkConfig), opts...)
+}
+
+// Write sends p or buffers it when the connection is down.
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errWriterClosed
+	}
+
+	msg := append([]byte(nil), p...)
+	if w.conn != nil {
+		if err := w.send(w.conn, msg); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	w.enqueue(msg)
+	w.reconnect()
+
+	return len(p), nil
+}
+
+// send writes msg to conn within the write timeout, if conn supports
+// deadlines as net.Conn does.
+func (w *NetworkWriter) send(conn io.Writer, msg []byte) error {
+	if d, ok := conn.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		d.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	}
+	_, err := conn.Write(msg)
+
+	return err
+}
+
+func (w *NetworkWriter) enqueue(msg []byte) {
+	if len(w.buf) < w.cfg.BufferSize {
+		w.buf = append(w.buf, msg)
+		return
+	}
+
+	w.dropped++
+	if w.cfg.DropPolicy == DropOldest {
+		w.buf = append(w.buf[1:], msg)
+	}
+}
+
+// reconnect starts the reconnect loop unless it is running, w.mu must be held.
+func (w *NetworkWriter) reconnect() {
+	if w.reconnecting || w.closed {
+		return
+	}
+	w.reconnecting = true
+	go w.reconnectLoop()
+}
+
+func (w *NetworkWriter) reconnectLoop() {
+	wait := w.cfg.ReconnectInterval
+	for {
+		conn, err := w.dial(w.cfg.DialTimeout)
+		if err == nil && w.connected(conn) {
+			return
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-w.done:
+			return
+		}
+		if wait *= 2; wait > w.cfg.MaxReconnectInterval {
+			wait = w.cfg.MaxReconnectInterval
+		}
+	}
+}
+
+// connected flushes the buffer to a new connection and reports whether it
+// is still usable.
+func (w *NetworkWriter) connected(conn io.WriteCloser) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		conn.Close()
+		return true
+	}
+
+	for len(w.buf) > 0 {
+		if err := w.send(conn, w.buf[0]); err != nil {
+			conn.Close()
+			return false
+		}
+		w.buf = w.buf[1:]
+	}
+
+	w.conn = conn
+	w.reconnecting = false
+
+	return true
+}
+
+// Dropped returns the number of messages discarded because the buffer was full.
+func (w *NetworkWriter) Dropped() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.dropped
+}
+
+// Close stops reconnecting and closes the connection. Messages still
+// buffered are lost.
+func (w *NetworkWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+
+	if w.conn == nil {
+		return nil
+	}
+
+	return w.conn.Close()
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := NewNetworkWriter("tcp", addr, NetworkConfig{BufferSize: 2, ReconnectInterval: time.Millisecond})
+	defer w.Close()
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		n, err := w.Write([]byte(line))
+		assert.NoError(t, err)
+		assert.Equal(t, len(line), n)
+	}
+	assert.Equal(t, 1, w.Dropped())
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skip("address reused by another process:", err)
+	}
+	defer ln.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"one\n", "two\n"} {
+		line, err := r.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, want, line)
+	}
+
+	w.Write([]byte("four\n"))
+	line, err := r.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "four\n", line)
+}
+
+func TestNetworkWriterStalledPeer(t *testing.T) {
+	cfg := NetworkConfig{BufferSize: 10, WriteTimeout: 20 * time.Millisecond, ReconnectInterval: time.Hour}
+	w := newNetworkWriter("pipe", "peer", cfg, func(time.Duration) (io.WriteCloser, error) {
+		// nobody reads the other end
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+	defer w.Close()
+	assert.Eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.conn != nil
+	}, time.Second, time.Millisecond)
+
+	start := time.Now()
+	n, err := w.Write([]byte("stalled\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	assert.Equal(t, [][]byte{[]byte("stalled\n")}, w.buf)
+}
+
+func TestSocketLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.sock")
+	l := NewSocketLogger(path, WithoutStdout())
+	l.SetFlags(Ldisable)
+	l.Info("buffered")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO : buffered\n", line)
+	l.Close()
+}
@@ -0,0 +1,206 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// nopLogger discards everything without formatting it.
+type nopLogger struct{}
+
+var closedEntries = func() chan Entry {
+	ch := make(chan Entry)
+	close(ch)
+	return ch
+}()
+
+// NewNopLogger returns a Logger discarding all entries, for use as the
+// default dependency of libraries and in benchmarks. Its methods don't
+// format the arguments nor allocate. Fatal and Panic still end the program
+// and panic, so control flow is the same as with a real logger.
+func NewNopLogger() Logger {
+	return nopLogger{}
+}
+
+// Nop returns the logger of NewNopLogger, libraries accepting a Logger can
+// default to it instead of checking for nil.
+func Nop() Logger {
+	return nopLogger{}
+}
+
+// discardLogger discards everything like nopLogger, but reports the levels
+// up to its level as enabled.
+type discardLogger struct {
+	nopLogger
+	level uint32
+}
+
+// Discard returns a Logger discarding all entries like Nop, whose Enabled
+// and DebugEnabled report entries of lvl and more severe levels as logged.
+// Benchmarks use it to measure the cost of the code building entries
+// behind such checks, without the cost of the logger.
+func Discard(lvl Level) Logger {
+	return &discardLogger{level: uint32(lvl)}
+}
+
+func (d *discardLogger) SetLevel(lvl Level) {
+	atomic.StoreUint32(&d.level, uint32(lvl))
+}
+
+func (d *discardLogger) Enabled(lvl Level) bool {
+	level := Level(atomic.LoadUint32(&d.level))
+	return level != LevelOff && level >= lvl.Severity()
+}
+
+func (d *discardLogger) DebugEnabled() bool {
+	return d.Enabled(LevelDebug)
+}
+
+func (d *discardLogger) EffectiveLevel(name string) (Level, string) {
+	return Level(atomic.LoadUint32(&d.level)), ""
+}
+
+func (d *discardLogger) With(fields LogFields) Logger {
+	return d
+}
+
+func (d *discardLogger) WithFields(fields ...Field) Logger {
+	return d
+}
+
+func (d *discardLogger) WithError(err error) Logger {
+	return d
+}
+
+func (d *discardLogger) Named(name string) Logger {
+	return d
+}
+
+func (d *discardLogger) WithGroup(name string) Logger {
+	return d
+}
+
+func (d *discardLogger) Once(key string) Logger {
+	return d
+}
+
+func (d *discardLogger) Every(window time.Duration) Logger {
+	return d
+}
+
+func (d *discardLogger) WithContextFields(ctx context.Context, fields LogFields) Logger {
+	return d
+}
+
+func (d *discardLogger) Progress(name string, total int) *ProgressTracker {
+	return newProgress(d, name, total)
+}
+
+func (nopLogger) Trace(v ...interface{})                                           {}
+func (nopLogger) Tracef(format string, v ...interface{})                           {}
+func (nopLogger) Debug(v ...interface{})                                           {}
+func (nopLogger) Debugf(format string, v ...interface{})                           {}
+func (nopLogger) Info(v ...interface{})                                            {}
+func (nopLogger) Infof(format string, v ...interface{})                            {}
+func (nopLogger) Warning(v ...interface{})                                         {}
+func (nopLogger) Warningf(format string, v ...interface{})                         {}
+func (nopLogger) Error(v ...interface{})                                           {}
+func (nopLogger) Errorf(format string, v ...interface{})                           {}
+func (nopLogger) Raw(lvl Level, line []byte)                                       {}
+func (nopLogger) Banner(app, version string, extra LogFields)                      {}
+func (nopLogger) Emergency(msg string)                                             {}
+func (nopLogger) Audit(event string, fields LogFields) error                       { return nil }
+func (nopLogger) Log(lvl Level, v ...interface{})                                  {}
+func (nopLogger) Print(v ...interface{})                                           {}
+func (nopLogger) Printf(format string, v ...interface{})                           {}
+func (nopLogger) Println(v ...interface{})                                         {}
+func (nopLogger) Write(p []byte) (int, error)                                      { return len(p), nil }
+func (nopLogger) Debugw(msg string, keysAndValues ...interface{})                  {}
+func (nopLogger) Infow(msg string, keysAndValues ...interface{})                   {}
+func (nopLogger) Warnw(msg string, keysAndValues ...interface{})                   {}
+func (nopLogger) Errorw(msg string, keysAndValues ...interface{})                  {}
+func (nopLogger) TraceCtx(ctx context.Context, v ...interface{})                   {}
+func (nopLogger) TracefCtx(ctx context.Context, format string, v ...interface{})   {}
+func (nopLogger) DebugCtx(ctx context.Context, v ...interface{})                   {}
+func (nopLogger) DebugfCtx(ctx context.Context, format string, v ...interface{})   {}
+func (nopLogger) InfoCtx(ctx context.Context, v ...interface{})                    {}
+func (nopLogger) InfofCtx(ctx context.Context, format string, v ...interface{})    {}
+func (nopLogger) WarningCtx(ctx context.Context, v ...interface{})                 {}
+func (nopLogger) WarningfCtx(ctx context.Context, format string, v ...interface{}) {}
+func (nopLogger) ErrorCtx(ctx context.Context, v ...interface{})                   {}
+func (nopLogger) ErrorfCtx(ctx context.Context, format string, v ...interface{})   {}
+func (nopLogger) SetLevel(lvl Level)                                               {}
+func (nopLogger) Enabled(lvl Level) bool                                           { return false }
+func (nopLogger) DebugEnabled() bool                                               { return false }
+func (nopLogger) SetNamedLevel(name string, lvl Level)                             {}
+func (nopLogger) ResetNamedLevel(name string)                                      {}
+func (nopLogger) SetFlags(flag int)                                                {}
+func (nopLogger) AddOutput(w io.Writer)                                            {}
+func (nopLogger) Close()                                                           {}
+func (nopLogger) Flush(ctx context.Context) error                                  { return nil }
+func (nopLogger) CloseContext(ctx context.Context) error                           { return nil }
+func (n nopLogger) With(fields LogFields) Logger                                   { return n }
+func (n nopLogger) WithFields(fields ...Field) Logger                              { return n }
+func (n nopLogger) WithError(err error) Logger                                     { return n }
+func (n nopLogger) EffectiveLevel(name string) (Level, string)                     { return LevelFatal, "" }
+
+func (n nopLogger) Named(name string) Logger {
+	return n
+}
+
+func (n nopLogger) WithGroup(name string) Logger {
+	return n
+}
+
+func (n nopLogger) Once(key string) Logger {
+	return n
+}
+
+func (n nopLogger) Every(window time.Duration) Logger {
+	return n
+}
+
+func (n nopLogger) WithContextFields(ctx context.Context, fields LogFields) Logger {
+	return n
+}
+
+func (n nopLogger) Progress(name string, total int) *ProgressTracker {
+	return newProgress(n, name, total)
+}
+
+func (nopLogger) Timer(name string, warnAfter ...time.Duration) (done func()) {
+	return func() {}
+}
+
+// Subscribe returns a closed channel, nothing is ever logged.
+func (nopLogger) Subscribe(filter func(Entry) bool) (<-chan Entry, func()) {
+	return closedEntries, func() {}
+}
+
+func (nopLogger) Fatal(v ...interface{}) {
+	os.Exit(1)
+}
+
+func (nopLogger) Fatalf(format string, v ...interface{}) {
+	os.Exit(1)
+}
+
+func (nopLogger) FatalCode(code int, v ...interface{}) {
+	os.Exit(code)
+}
+
+func (nopLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	os.Exit(1)
+}
+
+func (nopLogger) Panic(v ...interface{}) {
+	panic(fmt.Sprint(v...))
+}
+
+func (nopLogger) Panicf(format string, v ...interface{}) {
+	panic(fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNopLogger(t *testing.T) {
+	l := NewNopLogger()
+	allocs := testing.AllocsPerRun(100, func() {
+		l.With(LogFields{}).Infof("user %s", "ann")
+		l.Error("failed")
+		l.Raw(LevelInfo, nil)
+	})
+	assert.Zero(t, allocs)
+
+	entries, cancel := l.Subscribe(nil)
+	cancel()
+	_, ok := <-entries
+	assert.False(t, ok)
+
+	assert.PanicsWithValue(t, "boom 1", func() { l.Panicf("boom %d", 1) })
+}
+
+func TestDiscard(t *testing.T) {
+	assert.Equal(t, NewNopLogger(), Nop())
+
+	l := Discard(LevelInfo)
+	assert.True(t, l.Enabled(LevelWarning))
+	assert.True(t, l.Enabled(LevelInfo))
+	assert.False(t, l.DebugEnabled())
+	assert.Equal(t, l, l.With(LogFields{"a": 1}))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.With(nil).Infof("user %s", "ann")
+	})
+	assert.Zero(t, allocs)
+
+	l.SetLevel(LevelDebug)
+	assert.True(t, l.DebugEnabled())
+	l.SetLevel(LevelOff)
+	assert.False(t, l.Enabled(LevelFatal))
+}
@@ -0,0 +1,8 @@
+//go:build !race
+// +build !race
+
+package log
+
+// raceEnabled reports whether the tests run with the race detector, which
+// allocates on its own.
+const raceEnabled = false
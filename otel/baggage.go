@@ -0,0 +1,50 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/bialas1993/log"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BaggagePrefix is the usual prefix of the fields of baggage members.
+const BaggagePrefix = "baggage."
+
+// ExtractBaggage returns a log.ContextExtractor adding the members of the
+// baggage in ctx as fields named with the prefix, e.g. baggage.tenant=acme
+// with BaggagePrefix.
+func ExtractBaggage(prefix string) log.ContextExtractor {
+	return func(ctx context.Context) log.LogFields {
+		members := baggage.FromContext(ctx).Members()
+		if len(members) == 0 {
+			return nil
+		}
+
+		fields := make(log.LogFields, len(members))
+		for _, m := range members {
+			fields[prefix+m.Key()] = m.Value()
+		}
+
+		return fields
+	}
+}
+
+// WithBaggageFields adds the members of the baggage in the context to the
+// entries logged with the *Ctx methods or after WithContextFields, as
+// fields named with the prefix.
+func WithBaggageFields(prefix string) log.LogOption {
+	return log.WithContextExtractor(ExtractBaggage(prefix))
+}
+
+// ContextWithBaggageHeader returns a context carrying the members of a W3C
+// baggage header, e.g. the baggage header of an incoming request in a
+// service not using an OpenTelemetry propagator. An invalid header leaves
+// ctx unchanged and is returned as the error.
+func ContextWithBaggageHeader(ctx context.Context, header string) (context.Context, error) {
+	b, err := baggage.Parse(header)
+	if err != nil {
+		return ctx, err
+	}
+
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
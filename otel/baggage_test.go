@@ -0,0 +1,44 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bialas1993/log"
+)
+
+func TestWithBaggageFields(t *testing.T) {
+	ctx, err := ContextWithBaggageHeader(context.Background(), "tenant=acme,plan=gold%20tier;ttl=60")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	l := log.New(&out, log.WithoutStdout(), log.WithFlags(0), WithBaggageFields(BaggagePrefix))
+	l.InfoCtx(ctx, "checkout")
+	l.InfoCtx(context.Background(), "no baggage")
+
+	want := "INFO : baggage.plan=\"gold tier\" baggage.tenant=acme checkout\nINFO : no baggage\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := ContextWithBaggageHeader(ctx, "=broken"); err == nil {
+		t.Error("invalid header accepted")
+	}
+	if got := strings.Join(sortedKeys(ExtractBaggage("b_")(ctx)), ","); got != "b_plan,b_tenant" {
+		t.Errorf("got fields %s", got)
+	}
+}
+
+func sortedKeys(f log.LogFields) []string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
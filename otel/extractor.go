@@ -0,0 +1,40 @@
+// Package otel adds the IDs of the active OpenTelemetry span to log entries.
+// It is a separate module, so only programs using it depend on the
+// OpenTelemetry API.
+//
+//	logger := log.NewJsonLogger(otel.WithTraceFields())
+//	logger.InfoCtx(ctx, "order placed") // adds trace_id and span_id
+package otel
+
+import (
+	"context"
+
+	"github.com/bialas1993/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Fields added for the active span.
+const (
+	TraceIDField = "trace_id"
+	SpanIDField  = "span_id"
+)
+
+// Extract returns the trace_id and span_id of the span in ctx, nothing
+// when there is no valid span. It is a log.ContextExtractor.
+func Extract(ctx context.Context) log.LogFields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return log.LogFields{
+		TraceIDField: sc.TraceID().String(),
+		SpanIDField:  sc.SpanID().String(),
+	}
+}
+
+// WithTraceFields adds the trace_id and span_id of the active span to the
+// entries logged with the *Ctx methods or after WithContextFields.
+func WithTraceFields() log.LogOption {
+	return log.WithContextExtractor(Extract)
+}
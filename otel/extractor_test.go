@@ -0,0 +1,36 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bialas1993/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTraceFields(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	var out bytes.Buffer
+	l := log.New(&out, log.WithoutStdout(), log.WithFlags(0), WithTraceFields())
+	l.InfoCtx(ctx, "traced")
+	l.InfoCtx(context.Background(), "untraced")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %q", out.String())
+	}
+	if want := "INFO : span_id=00f067aa0ba902b7 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 traced"; strings.TrimSpace(lines[0]) != want {
+		t.Errorf("got %q, want %q", lines[0], want)
+	}
+	if want := "INFO : untraced"; strings.TrimSpace(lines[1]) != want {
+		t.Errorf("got %q, want %q", lines[1], want)
+	}
+}
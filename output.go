@@ -0,0 +1,400 @@
+package log
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// output is a single destination of a logger. Every output renders entries
+// with its own formatter, so one logger can write e.g. colorized text to the
+// console and JSON to a file.
+type output struct {
+	formatter Formatter
+	loggers   map[Level]*log.Logger
+	// rename are the field names of WithSinkFieldMap.
+	rename map[string]string
+	// buf is reused by write, outputs are used with logLock held.
+	buf []byte
+}
+
+// secondaryOutput is an output requested with WithSecondaryOutput, it is
+// created by new once the logger flags are known.
+type secondaryOutput struct {
+	w         io.Writer
+	formatter Formatter
+}
+
+func newOutput(f Formatter, flags int, writers map[Level]io.Writer) *output {
+	if f.HasFlags() {
+		flags = f.Flags()
+	}
+
+	o := &output{formatter: f, loggers: make(map[Level]*log.Logger, len(writers))}
+	for lvl, w := range writers {
+		o.loggers[lvl] = log.New(w, prefix(f, lvl), flags)
+	}
+
+	return o
+}
+
+// prefix returns the prefix of the level, a level added with RegisterLevel
+// without prefix in the formatter gets the one of its severity.
+func prefix(f Formatter, lvl Level) string {
+	if !f.HasPrefixes() {
+		return levelTags[lvl]
+	}
+
+	prefixes := f.Prefixes()
+	if p, ok := prefixes[lvl]; ok {
+		return p
+	}
+
+	return prefixes[lvl.Severity()]
+}
+
+// logger returns the logger of the level. Levels added with RegisterLevel
+// after the output was created get a logger writing to the writer of their
+// severity.
+func (o *output) logger(s Level) (*log.Logger, bool) {
+	if lg, ok := o.loggers[s]; ok || s.Severity() == s {
+		return lg, ok
+	}
+
+	base, ok := o.loggers[s.Severity()]
+	if !ok {
+		return nil, false
+	}
+	lg := log.New(base.Writer(), prefix(o.formatter, s), base.Flags())
+	o.loggers[s] = lg
+
+	return lg, true
+}
+
+// write renders the entry and writes it to the writer of the level. When the
+// level has neither prefix nor flags log.Logger adds nothing, so the entry is
+// written directly: AppendFormatter renders into the reused buffer and lines
+// ending with a newline go to io.StringWriter without a copy.
+func (o *output) write(s Level, depth, flags int, fields LogFields, msg string) {
+	lg, ok := o.logger(s)
+	if !ok {
+		return
+	}
+	if o.rename != nil {
+		fields = renameFields(fields, o.rename)
+	}
+
+	if lg.Flags() != 0 || lg.Prefix() != "" {
+		if replaying() {
+			// log.Logger reads the real clock, render its header instead
+			o.buf = appendLogHeader(o.buf[:0], lg.Prefix(), lg.Flags())
+			o.buf = append(o.buf, o.formatter.Output(flags, levelMap[s], fields, msg)...)
+			if len(o.buf) == 0 || o.buf[len(o.buf)-1] != '\n' {
+				o.buf = append(o.buf, '\n')
+			}
+			lg.Writer().Write(o.buf)
+			return
+		}
+
+		// skip write, logger.output and the logging method
+		lg.Output(4+depth, o.formatter.Output(flags, levelMap[s], fields, msg))
+		return
+	}
+
+	w := lg.Writer()
+	if af, ok := o.formatter.(AppendFormatter); ok {
+		o.buf = af.AppendOutput(o.buf[:0], flags, levelMap[s], fields, msg)
+	} else {
+		line := o.formatter.Output(flags, levelMap[s], fields, msg)
+		if sw, ok := w.(io.StringWriter); ok && strings.HasSuffix(line, "\n") {
+			sw.WriteString(line)
+			return
+		}
+		o.buf = append(o.buf[:0], line...)
+	}
+
+	if len(o.buf) == 0 || o.buf[len(o.buf)-1] != '\n' {
+		o.buf = append(o.buf, '\n')
+	}
+	w.Write(o.buf)
+}
+
+// writeTyped writes the entry with the typed fields rendered by the
+// formatter as they are, fields holding the others. It reports false when
+// the output needs all fields as LogFields, e.g. to rename them or for
+// log.Logger to add its prefix or flags.
+func (o *output) writeTyped(s Level, flags int, typed []Field, fields LogFields, msg string) bool {
+	ta, ok := o.formatter.(typedAppender)
+	if !ok || o.rename != nil {
+		return false
+	}
+	lg, ok := o.logger(s)
+	if !ok {
+		return true
+	}
+	if lg.Flags() != 0 || lg.Prefix() != "" {
+		return false
+	}
+
+	o.buf = ta.appendTyped(o.buf[:0], flags, levelMap[s], typed, fields, msg)
+	if len(o.buf) == 0 || o.buf[len(o.buf)-1] != '\n' {
+		o.buf = append(o.buf, '\n')
+	}
+	lg.Writer().Write(o.buf)
+
+	return true
+}
+
+// writeRaw writes the line as it is to the writer of the level, adding a
+// missing newline.
+func (o *output) writeRaw(s Level, line []byte) {
+	lg, ok := o.logger(s)
+	if !ok {
+		return
+	}
+
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		lg.Writer().Write(line)
+		return
+	}
+	o.buf = append(append(o.buf[:0], line...), '\n')
+	lg.Writer().Write(o.buf)
+}
+
+// levelWriters returns writers sending every level to w.
+func levelWriters(w io.Writer) map[Level]io.Writer {
+	writers := make(map[Level]io.Writer, len(levelTags))
+	for lvl := range levelTags {
+		writers[lvl] = w
+	}
+
+	return writers
+}
+
+// setFlags updates flags of the output unless its formatter manages them.
+func (o *output) setFlags(flag int) {
+	if o.formatter.HasFlags() {
+		return
+	}
+
+	for _, l := range o.loggers {
+		l.SetFlags(flag)
+	}
+}
+
+// WithSecondaryOutput writes all entries to w rendered with formatter f,
+// independently of the logger formatter. If w is an io.Closer it is closed
+// together with the logger.
+func WithSecondaryOutput(w io.Writer, f Formatter) LogOption {
+	return func(l *logger) {
+		l.secondary = append(l.secondary, secondaryOutput{w: w, formatter: f})
+	}
+}
+
+// sinkOutput creates an output writing the levels allowed for w to w,
+// rendered with f.
+func (l *logger) sinkOutput(w io.Writer, f Formatter) *output {
+	out := map[Level]io.Writer{}
+	for lvl := range levelTags {
+		if l.outputAllows(w, lvl) {
+			out[lvl] = newFanout(l.onWriteErr, l.latency, l.capped(w, w, lvl))
+		}
+	}
+	o := newOutput(f, l.flags, out)
+	o.rename = l.fieldMapFor(w)
+
+	return o
+}
+
+// WithLevelOutput writes entries of the level to w besides the other
+// outputs, e.g. WithLevelOutput(LevelDebug, debugFile). It can be used
+// several times, also for the same level. If w is an io.Closer it is closed
+// together with the logger.
+func WithLevelOutput(lvl Level, w io.Writer) LogOption {
+	return func(l *logger) {
+		if l.levelOut == nil {
+			l.levelOut = map[Level][]io.Writer{}
+		}
+		l.levelOut[lvl] = append(l.levelOut[lvl], w)
+		l.addCloser(w)
+	}
+}
+
+// WithOutput writes entries of all levels to w besides the other outputs,
+// rendered with the logger formatter. It can be used several times. If w is
+// an io.Closer it is closed together with the logger.
+func WithOutput(w io.Writer) LogOption {
+	return func(l *logger) {
+		if l.levelOut == nil {
+			l.levelOut = map[Level][]io.Writer{}
+		}
+		for lvl := range levelTags {
+			l.levelOut[lvl] = append(l.levelOut[lvl], w)
+		}
+		l.addCloser(w)
+	}
+}
+
+// addWriter makes the output write every level to the writer returned by
+// writerFor as well, levels without writer are skipped.
+func (o *output) addWriter(writerFor func(Level) io.Writer) {
+	for lvl, lg := range o.loggers {
+		w := writerFor(lvl)
+		if w == nil {
+			continue
+		}
+		if f, ok := lg.Writer().(*fanout); ok {
+			lg.SetOutput(f.with(w))
+		} else {
+			lg.SetOutput(newFanout(nil, nil, lg.Writer(), w))
+		}
+	}
+}
+
+// outputLevel is the least severe level written to an output, set with
+// WithLevelForOutput.
+type outputLevel struct {
+	w   io.Writer
+	lvl Level
+}
+
+type systemLogOutput struct{}
+
+func (systemLogOutput) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// SystemLogOutput stands for the system log in WithLevelForOutput, its
+// writers are created by the logger.
+var SystemLogOutput io.Writer = systemLogOutput{}
+
+// WithLevelForOutput limits w to entries of lvl and more severe levels, e.g.
+// debug entries go to a file while only warnings and errors reach syslog:
+//
+//	NewSyslogLogger("api", WithLevel(LevelDebug), WithOutput(file), WithLevelForOutput(SystemLogOutput, LevelWarning))
+//
+// w is a writer passed to New, WithOutput, WithLevelOutput,
+// WithSecondaryOutput or AddOutput, os.Stdout or os.Stderr for the console,
+// or SystemLogOutput. The logger level gates all outputs first, so it has to
+// be as verbose as the most verbose output. If w is given several times the
+// last level wins.
+func WithLevelForOutput(w io.Writer, lvl Level) LogOption {
+	return func(l *logger) {
+		l.outLevels = append(l.outLevels, outputLevel{w: w, lvl: lvl})
+	}
+}
+
+// outputAllows reports whether entries of lvl are written to w.
+func (l *logger) outputAllows(w io.Writer, lvl Level) bool {
+	// comparing interfaces holding the same uncomparable type panics
+	if w == nil || !reflect.TypeOf(w).Comparable() {
+		return true
+	}
+
+	allowed := true
+	for _, ol := range l.outLevels {
+		if ol.w == w {
+			allowed = lvl.Severity() <= ol.lvl.Severity()
+		}
+	}
+
+	return allowed
+}
+
+// addCloser closes w together with the logger if it is an io.Closer, once
+// however many outputs it is used for.
+func (l *logger) addCloser(w interface{}) {
+	c, ok := w.(io.Closer)
+	if !ok || c == nil {
+		return
+	}
+	// comparing interfaces holding the same uncomparable type panics
+	if reflect.TypeOf(c).Comparable() {
+		for _, added := range l.closers {
+			if added == c {
+				return
+			}
+		}
+	}
+
+	l.closers = append(l.closers, c)
+}
+
+// WithConsole enables or disables writing to stdout (debug, info and
+// warning) and stderr (more severe levels). The console is enabled by
+// default.
+func WithConsole(enabled bool) LogOption {
+	return func(l *logger) {
+		l.noConsole = !enabled
+	}
+}
+
+// WithStderrOnly writes all levels of the console to stderr, keeping stdout
+// for the output of the program. Container platforms collect both.
+func WithStderrOnly() LogOption {
+	return func(l *logger) {
+		l.stderrOnly = true
+	}
+}
+
+// WithoutStdout disables the console, so the logger writes only to its
+// file, the system log and other outputs.
+func WithoutStdout() LogOption {
+	return WithConsole(false)
+}
+
+// appendLogHeader renders the prefix, time and caller as log.Logger does,
+// using the replay clock and caller.
+func appendLogHeader(b []byte, prefix string, flags int) []byte {
+	if flags&Lmsgprefix == 0 {
+		b = append(b, prefix...)
+	}
+
+	if flags&(Ldate|Ltime|Lmicroseconds) != 0 {
+		t := clock()
+		if flags&LUTC != 0 {
+			t = t.UTC()
+		}
+		if flags&Ldate != 0 {
+			year, month, day := t.Date()
+			b = append(b, itoa(year, 4)...)
+			b = append(b, '/')
+			b = append(b, itoa(int(month), 2)...)
+			b = append(b, '/')
+			b = append(b, itoa(day, 2)...)
+			b = append(b, ' ')
+		}
+		if flags&(Ltime|Lmicroseconds) != 0 {
+			hour, min, sec := t.Clock()
+			b = append(b, itoa(hour, 2)...)
+			b = append(b, ':')
+			b = append(b, itoa(min, 2)...)
+			b = append(b, ':')
+			b = append(b, itoa(sec, 2)...)
+			if flags&Lmicroseconds != 0 {
+				b = append(b, '.')
+				b = append(b, itoa(t.Nanosecond()/1e3, 6)...)
+			}
+			b = append(b, ' ')
+		}
+	}
+
+	if flags&(Lshortfile|Llongfile) != 0 {
+		file, line := caller()
+		if flags&Lshortfile != 0 {
+			file = filepath.Base(file)
+		}
+		b = append(b, file...)
+		b = append(b, ':')
+		b = append(b, itoa(line, -1)...)
+		b = append(b, ": "...)
+	}
+
+	if flags&Lmsgprefix != 0 {
+		b = append(b, prefix...)
+	}
+
+	return b
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// instanceState is the content of the state file of WithPersistentID.
+type instanceState struct {
+	ID         string    `json:"id"`
+	Restarts   int       `json:"restarts"`
+	FirstStart time.Time `json:"first_start"`
+}
+
+// instances caches the state of every state file used by the process, so a
+// run counts as one restart however many loggers share the file.
+var instances struct {
+	sync.Mutex
+	states map[string]instance
+}
+
+type instance struct {
+	st  instanceState
+	err error
+}
+
+// WithPersistentID adds the fields logger_id, restart_count and first_start
+// to every entry, fields set on the entry win. They are kept in the state
+// file at path: the first run creates it with a random ID, every later run
+// increments the restart counter once, so a crash-looping instance shows up with
+// the same ID and a growing count. When the file can't be read or written
+// the error is logged and the fields describe this run only.
+func WithPersistentID(path string) LogOption {
+	return func(l *logger) {
+		st, err := processInstanceState(path)
+		if err != nil {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: persistent ID %s: %w", path, err))
+		}
+		fields := LogFields{
+			"logger_id":     st.ID,
+			"restart_count": st.Restarts,
+			"first_start":   st.FirstStart.Format(time.RFC3339),
+		}
+		WithEnricher(EnricherFunc(func(e *Entry) {
+			for k, v := range fields {
+				if _, ok := e.Fields[k]; !ok {
+					e.Fields[k] = v
+				}
+			}
+		}))(l)
+	}
+}
+
+// processInstanceState loads the state of path on its first use in the
+// process and returns the same state afterwards.
+func processInstanceState(path string) (instanceState, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+
+	instances.Lock()
+	defer instances.Unlock()
+
+	i, ok := instances.states[key]
+	if !ok {
+		i.st, i.err = loadInstanceState(path)
+		if instances.states == nil {
+			instances.states = map[string]instance{}
+		}
+		instances.states[key] = i
+	}
+
+	return i.st, i.err
+}
+
+// loadInstanceState reads the state, counts this run as a restart and
+// writes it back. A missing or corrupt file starts a new state.
+func loadInstanceState(path string) (instanceState, error) {
+	var st instanceState
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil && json.Unmarshal(b, &st) == nil && st.ID != "":
+		st.Restarts++
+	case err != nil && !os.IsNotExist(err):
+		st = newInstanceState()
+		return st, err
+	default:
+		st = newInstanceState()
+	}
+
+	b, err = json.Marshal(st)
+	if err != nil {
+		return st, err
+	}
+
+	// write a temporary file first, so a crash never leaves a truncated one
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(b, '\n'), 0644); err != nil {
+		return st, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return st, err
+	}
+
+	return st, nil
+}
+
+func newInstanceState() instanceState {
+	id := make([]byte, 8)
+	randomBytes(id)
+
+	return instanceState{ID: hex.EncodeToString(id), FirstStart: clock().UTC().Truncate(time.Second)}
+}
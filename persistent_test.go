@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance.json")
+
+	defer func() { instances.states = nil }()
+
+	var ids []string
+	for run := 0; run < 3; run++ {
+		// every run is a new process
+		instances.states = nil
+
+		var out bytes.Buffer
+		l := New(&out, WithoutStdout(), WithFlags(0), WithPersistentID(path))
+		l.Info("started")
+		l.Close()
+		// more loggers of the same process don't count as restarts
+		l = New(&out, WithoutStdout(), WithFlags(0), WithPersistentID(path))
+		l.Info("started again")
+		l.Close()
+
+		assert.Equal(t, 2, strings.Count(out.String(), "restart_count="+strconv.Itoa(run)+" "))
+		ids = append(ids, regexp.MustCompile(`logger_id=(\w+)`).FindStringSubmatch(out.String())[1])
+	}
+	assert.Len(t, ids[0], 16)
+	assert.Equal(t, ids[0], ids[2])
+
+	// a corrupt file starts over
+	instances.states = nil
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithPersistentID(path))
+	l.Info("started")
+	l.Close()
+	assert.Contains(t, out.String(), "restart_count=0")
+	assert.NotContains(t, out.String(), "logger_id="+ids[0])
+}
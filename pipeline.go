@@ -0,0 +1,146 @@
+package log
+
+import "fmt"
+
+// Stage is a step of the pipeline an entry passes through before it is
+// written. It may change the entry, returning false drops it: the entry is
+// not written, only ring buffers still record it. Stages run with the
+// logger lock held and must not log through the same logger.
+type Stage interface {
+	Process(e *Entry) bool
+}
+
+// StageFunc adapts a function to the Stage interface.
+type StageFunc func(e *Entry) bool
+
+func (f StageFunc) Process(e *Entry) bool {
+	return f(e)
+}
+
+// StageName names a built-in stage of the pipeline.
+type StageName string
+
+// The built-in stages in the order entries pass them. Entries dropped by a
+// stage don't reach the following ones.
+const (
+	// StageEnrich adds the fields of PushFields, runs the enrichers, e.g.
+	// a FieldEncryptor redacting fields, and adds the stacktrace.
+	StageEnrich StageName = "enrich"
+	// StageHold copies entries to the legal holds.
+	StageHold StageName = "hold"
+	// StageSample drops the entries not sampled, see WithSampleRate and
+	// WithSampling, and the repeats suppressed by Once and Every.
+	StageSample StageName = "sample"
+	// StageLimit drops entries over the rate limits, see WithRateLimit
+	// and WithKeyedRateLimit.
+	StageLimit StageName = "limit"
+	// StageSummarize folds repeated entries into summaries, see
+	// WithSummarizer.
+	StageSummarize StageName = "summarize"
+)
+
+// stage is a stage of the pipeline of a logger. Loggers derived with Named
+// share the pipeline, so stages get the logger the entry was logged with.
+type stage struct {
+	name    StageName
+	process func(l *logger, e *Entry, d *SamplingDecision) bool
+}
+
+// customStage is a stage added with WithStage or WithStageAfter.
+type customStage struct {
+	after StageName
+	stage Stage
+}
+
+// WithStage adds a stage after the built-in ones, right before entries are
+// formatted and written to the outputs, hooks and routes. Stages added
+// later run later.
+func WithStage(s Stage) LogOption {
+	return WithStageAfter(StageSummarize, s)
+}
+
+// WithStageAfter adds a stage right after the built-in stage name, e.g. a
+// stage redacting fields which enrichers added:
+//
+//	log.WithStageAfter(log.StageEnrich, log.StageFunc(redactTokens))
+//
+// Stages added after the same stage run in the order they were added.
+func WithStageAfter(name StageName, s Stage) LogOption {
+	return func(l *logger) {
+		l.stages = append(l.stages, customStage{after: name, stage: s})
+	}
+}
+
+// buildPipeline orders the built-in stages and the custom ones, stages
+// added after an unknown stage are left out and reported. Custom stages get
+// their own copy of the fields, so changing them doesn't change the fields
+// of the logger.
+func (l *logger) buildPipeline() error {
+	builtin := []stage{
+		{StageEnrich, func(l *logger, e *Entry, d *SamplingDecision) bool {
+			l.enrich(e)
+			return true
+		}},
+		{StageHold, func(l *logger, e *Entry, d *SamplingDecision) bool {
+			l.hold(*e)
+			return true
+		}},
+		{StageSample, func(l *logger, e *Entry, d *SamplingDecision) bool {
+			return (!l.sampling || l.sampled(e, d)) && (l.sampler == nil || l.sampler.keep(e)) &&
+				(l.dedup == nil || l.deduplicated(e))
+		}},
+		{StageLimit, func(l *logger, e *Entry, d *SamplingDecision) bool {
+			return len(l.limits) == 0 && len(l.levelLimits) == 0 || !l.limited(*e)
+		}},
+		{StageSummarize, func(l *logger, e *Entry, d *SamplingDecision) bool {
+			return len(l.summarizers) == 0 || !l.summarize(*e)
+		}},
+	}
+
+	var err error
+	for _, cs := range l.stages {
+		if !hasStage(builtin, cs.after) {
+			err = fmt.Errorf("log: unknown pipeline stage %q", cs.after)
+		}
+	}
+
+	l.pipeline = l.pipeline[:0]
+	for _, s := range builtin {
+		l.pipeline = append(l.pipeline, s)
+		for _, cs := range l.stages {
+			if cs.after != s.name {
+				continue
+			}
+			process := cs.stage.Process
+			l.pipeline = append(l.pipeline, stage{name: s.name, process: func(_ *logger, e *Entry, _ *SamplingDecision) bool {
+				e.Fields = e.Fields.clone()
+				return process(e)
+			}})
+		}
+	}
+
+	return err
+}
+
+func hasStage(stages []stage, name StageName) bool {
+	for _, s := range stages {
+		if s.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// process passes the entry through the pipeline, it reports whether the
+// entry is to be written. d is the sampling decision of the context the
+// entry was logged with, nil when there is none.
+func (l *logger) process(e *Entry, d *SamplingDecision) bool {
+	for _, s := range l.pipeline {
+		if !s.process(l, e, d) {
+			return false
+		}
+	}
+
+	return true
+}
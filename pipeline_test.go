@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineStages(t *testing.T) {
+	var order []string
+	record := func(name string) Stage {
+		return StageFunc(func(e *Entry) bool {
+			order = append(order, name)
+			return true
+		})
+	}
+	redact := StageFunc(func(e *Entry) bool {
+		if _, ok := e.Fields["token"]; ok {
+			e.Fields["token"] = "[redacted]"
+		}
+		return true
+	})
+	dropHealth := StageFunc(func(e *Entry) bool {
+		return !strings.Contains(e.Message, "healthz")
+	})
+
+	var out bytes.Buffer
+	rings := NewRingBuffer(10)
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithRingBuffer(rings),
+		WithEnricher(EnricherFunc(func(e *Entry) { e.Fields["token"] = "secret" })),
+		WithStage(record("last")),
+		WithStage(dropHealth),
+		WithStageAfter(StageEnrich, redact),
+		WithStageAfter(StageEnrich, record("after enrich")))
+
+	l.Info("GET /orders")
+	l.Info("GET /healthz")
+
+	assert.Equal(t, "INFO : token=[redacted] GET /orders\n", out.String())
+	assert.Equal(t, []string{"after enrich", "last", "after enrich", "last"}, order)
+	assert.Len(t, rings.Entries(), 2, "dropped entries are still recorded")
+}
+
+func TestPipelineUnknownStage(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithStageAfter("route", StageFunc(func(e *Entry) bool { return false })))
+	l.Info("kept")
+
+	assert.Equal(t, "ERROR: log: unknown pipeline stage \"route\"\nINFO : kept\n", out.String())
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Profile names accepted by Profile.
+const (
+	ProfileProduction  = "production"
+	ProfileDevelopment = "development"
+	ProfileTest        = "test"
+)
+
+// Profile returns an option applying the defaults of an environment, so
+// services of one company log the same way:
+//
+//	production:  JSON, LevelInfo, sampled to 100 entries per message and
+//	             second and every 100th beyond, console on stderr only
+//	development: colorized text (plain when stdout is not a terminal),
+//	             LevelDebug, time and caller file
+//	test:        plain text, LevelDebug, no flags, see logtest.NewRecorder
+//
+// Options given after Profile override its defaults. An unknown name is
+// logged as an error once the logger is created.
+func Profile(name string) LogOption {
+	var opts []LogOption
+	switch name {
+	case ProfileProduction:
+		opts = []LogOption{
+			WithFormatter(JsonFormatter{}),
+			WithLevel(LevelInfo),
+			WithSampling(SamplingConfig{Initial: 100, Thereafter: 100, Tick: time.Second}),
+			WithStderrOnly(),
+		}
+	case ProfileDevelopment:
+		var f Formatter = StdFormatter{}
+		if isTerminal(os.Stdout) {
+			f = ColorizedStdFormatter{}
+		}
+		opts = []LogOption{WithFormatter(f), WithLevel(LevelDebug), WithFlags(Ltime | Lshortfile)}
+	case ProfileTest:
+		opts = []LogOption{WithFormatter(StdFormatter{}), WithLevel(LevelDebug), WithFlags(Ldisable)}
+	default:
+		return func(l *logger) {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: unknown profile %q, use %s, %s or %s",
+				name, ProfileProduction, ProfileDevelopment, ProfileTest))
+		}
+	}
+
+	return func(l *logger) {
+		for _, opt := range opts {
+			opt(l)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	for name, want := range map[string]logger{
+		ProfileProduction: {formatter: JsonFormatter{}, level: uint32(LevelInfo), flags: LstdFlags},
+		ProfileTest:       {formatter: StdFormatter{}, level: uint32(LevelDebug), flags: Ldisable},
+	} {
+		l := logger{flags: LstdFlags}
+		Profile(name)(&l)
+		assert.Equal(t, want.formatter, l.formatter, name)
+		assert.Equal(t, want.loadLevel(), l.loadLevel(), name)
+		assert.Equal(t, want.flags, l.flags, name)
+	}
+
+	var prod logger
+	Profile(ProfileProduction)(&prod)
+	assert.True(t, prod.stderrOnly)
+	if assert.NotNil(t, prod.sampler) {
+		assert.Equal(t, 100, prod.sampler.cfg.Initial)
+	}
+
+	var l logger
+	Profile(ProfileDevelopment)(&l)
+	assert.Equal(t, LevelDebug, l.loadLevel())
+	assert.NotZero(t, l.flags&Lshortfile)
+
+	var out bytes.Buffer
+	New(&out, Profile("staging"))
+	assert.Contains(t, out.String(), `unknown profile "staging"`)
+}
+
+func TestProfileOverride(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, Profile(ProfileProduction), WithLevel(LevelError))
+	l.Warning("dropped")
+	l.Error("kept")
+	assert.Equal(t, `{"level":"error","msg":"kept"}`+"\n", out.String())
+}
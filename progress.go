@@ -0,0 +1,98 @@
+package log
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// ProgressInterval is the minimum time between the progress entries of an
+// operation.
+var ProgressInterval = 10 * time.Second
+
+// ProgressTracker logs the progress of a long-running operation, see
+// Logger.Progress.
+type ProgressTracker struct {
+	l     Logger
+	name  string
+	total int
+	now   func() time.Time
+
+	mu       sync.Mutex
+	done     int
+	start    time.Time
+	last     time.Time
+	finished bool
+}
+
+func newProgress(l Logger, name string, total int) *ProgressTracker {
+	p := &ProgressTracker{l: l, name: name, total: total, now: clock}
+	p.start = p.now()
+	p.last = p.start
+
+	return p
+}
+
+// Progress returns a tracker for the operation name of total steps. Its Inc
+// logs Info entries with the fields progress, done, total, percent and eta
+// at most every ProgressInterval, and a summary with the elapsed time and
+// the rate once total is reached.
+func (l *logger) Progress(name string, total int) *ProgressTracker {
+	return newProgress(l, name, total)
+}
+
+// Progress returns a tracker for an operation logged by the default logger.
+func Progress(name string, total int) *ProgressTracker {
+	return defaultLogger.Progress(name, total)
+}
+
+// Inc records n more steps done.
+func (p *ProgressTracker) Inc(n int) {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+	p.done += n
+	now := p.now()
+	if p.total > 0 && p.done >= p.total {
+		p.mu.Unlock()
+		p.Done()
+		return
+	}
+	if now.Sub(p.last) < ProgressInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.last = now
+
+	fields := LogFields{"progress": p.name, "done": p.done, "total": p.total}
+	if p.total > 0 && p.done > 0 {
+		elapsed := now.Sub(p.start)
+		fields["percent"] = math.Round(float64(p.done)*1000/float64(p.total)) / 10
+		fields["eta"] = (time.Duration(float64(elapsed) * float64(p.total-p.done) / float64(p.done))).Round(time.Second).String()
+	}
+	p.mu.Unlock()
+
+	p.l.With(fields).Info(p.name + " in progress")
+}
+
+// Done logs the summary, it is called by Inc when the total is reached and
+// can be called earlier when the operation ends before. Later calls do
+// nothing.
+func (p *ProgressTracker) Done() {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+	p.finished = true
+	elapsed := p.now().Sub(p.start)
+	fields := LogFields{"progress": p.name, "done": p.done, "total": p.total, "elapsed": elapsed.Round(time.Millisecond).String()}
+	if elapsed > 0 {
+		fields["rate"] = math.Round(float64(p.done)*10/elapsed.Seconds()) / 10
+	}
+	p.mu.Unlock()
+
+	p.l.With(fields).Info(p.name + " finished")
+}
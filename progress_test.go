@@ -0,0 +1,32 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := l.Progress("import", 100)
+	p.now = func() time.Time { return now }
+	p.start, p.last = now, now
+
+	now = now.Add(time.Second)
+	p.Inc(10)
+	assert.Empty(t, out.String(), "throttled")
+
+	now = now.Add(9 * time.Second)
+	p.Inc(15)
+	now = now.Add(10 * time.Second)
+	p.Inc(75)
+	p.Inc(1)
+
+	assert.Equal(t, "INFO : done=25 eta=30s percent=25 progress=import total=100 import in progress\n"+
+		"INFO : done=100 elapsed=20s progress=import rate=5 total=100 import finished\n", out.String())
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// fieldProvenance records which With call, context or enricher set the
+// fields of the next entry.
+type fieldProvenance struct {
+	// keys are the traced fields, all when nil.
+	keys    map[string]bool
+	sources map[string][]string
+	// bound are the sources of the fields of a logger returned by With.
+	bound map[string][]string
+}
+
+// WithFieldProvenance is a debug mode recording where fields come from: the
+// file and line of the With call, the context of WithContextFields or the
+// type of the enricher. After every entry carrying one of the keys, or any
+// field when no keys are given, a meta entry "field provenance" with the
+// same level is written to the outputs. It has a field per traced key
+// listing its sources, later ones overriding earlier ones, e.g.
+// tenant_id="With at auth.go:42 > enricher *main.tenantEnricher", and the
+// field entry with the message of the entry.
+func WithFieldProvenance(keys ...string) LogOption {
+	return func(l *logger) {
+		p := &fieldProvenance{sources: map[string][]string{}}
+		if len(keys) > 0 {
+			p.keys = map[string]bool{}
+			for _, k := range keys {
+				p.keys[k] = true
+			}
+		}
+		l.provenance = p
+	}
+}
+
+func (p *fieldProvenance) record(fields LogFields, source string) {
+	for k := range fields {
+		if k != fieldOrderKey && (p.keys == nil || p.keys[k]) {
+			p.sources[k] = append(p.sources[k], source)
+		}
+	}
+}
+
+// bind returns the provenance of a logger returned by With, recording the
+// fields bound to it.
+func (p *fieldProvenance) bind(fields LogFields, source string) *fieldProvenance {
+	b := &fieldProvenance{keys: p.keys, bound: make(map[string][]string, len(p.bound))}
+	for k, sources := range p.bound {
+		b.bound[k] = sources
+	}
+	for k := range fields {
+		if k != fieldOrderKey && (p.keys == nil || p.keys[k]) {
+			b.bound[k] = append(b.bound[k][:len(b.bound[k]):len(b.bound[k])], source)
+		}
+	}
+	b.reset()
+
+	return b
+}
+
+// reset forgets the sources of the last entry, keeping those of the bound
+// fields.
+func (p *fieldProvenance) reset() {
+	p.sources = make(map[string][]string, len(p.bound))
+	for k, sources := range p.bound {
+		p.sources[k] = sources[:len(sources):len(sources)]
+	}
+}
+
+// recordEnricher records the fields the enricher added or changed.
+func (p *fieldProvenance) recordEnricher(en Enricher, before, after LogFields) {
+	source := fmt.Sprintf("enricher %T", en)
+	for k, v := range after {
+		if old, ok := before[k]; ok && reflect.DeepEqual(old, v) {
+			continue
+		}
+		if k != fieldOrderKey && (p.keys == nil || p.keys[k]) {
+			p.sources[k] = append(p.sources[k], source)
+		}
+	}
+}
+
+// callerSource describes the caller skip frames above callerSource.
+func callerSource(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "With"
+	}
+
+	return fmt.Sprintf("With at %s:%d", filepath.Base(file), line)
+}
+
+// addFields adds fields to the next entry, source describes where they come
+// from when provenance is recorded.
+func (l *logger) addFields(fields LogFields, source func() string) {
+	if l.provenance != nil {
+		l.provenance.record(fields, source())
+	}
+	l.fields = l.fields.Add(fields)
+}
+
+// writeProvenance writes the meta entry of e, called with logLock held.
+func (l *logger) writeProvenance(e Entry) {
+	if l.provenance == nil {
+		return
+	}
+
+	fields := LogFields{}
+	for k, sources := range l.provenance.sources {
+		if _, ok := e.Fields[k]; ok {
+			fields[k] = strings.Join(sources, " > ")
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	fields["entry"] = e.Message
+
+	for _, o := range l.outputs {
+		o.write(e.Level, 0, l.top().flags, fields, "field provenance")
+	}
+}
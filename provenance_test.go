@@ -0,0 +1,28 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldProvenance(t *testing.T) {
+	var out bytes.Buffer
+	tenant := EnricherFunc(func(e *Entry) { e.Fields["tenant_id"] = "t2" })
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithEnricher(tenant), WithFieldProvenance("tenant_id", "user"))
+
+	l.WithContextFields(context.Background(), LogFields{"user": "ann"})
+	req := l.With(LogFields{"tenant_id": "t1", "path": "/"})
+	req.Info("request")
+	l.Info("plain")
+	req.Info("again")
+
+	assert.Regexp(t, `^INFO : path=/ tenant_id=t2 user=ann request\n`+
+		`INFO : entry=request tenant_id="With at provenance_test.go:\d+ > enricher log.EnricherFunc" user=context field provenance\n`+
+		`INFO : tenant_id=t2 user=ann plain\n`+
+		`INFO : entry=plain tenant_id="enricher log.EnricherFunc" user=context field provenance\n`+
+		`INFO : path=/ tenant_id=t2 user=ann again\n`+
+		`INFO : entry=again tenant_id="With at provenance_test.go:\d+ > enricher log.EnricherFunc" user=context field provenance\n$`, out.String())
+}
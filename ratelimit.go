@@ -0,0 +1,239 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// tokenBucket allows bursts of up to burst entries, refilled at rate
+// entries per second.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{tokens: float64(burst), last: now}
+}
+
+// allow takes a token when one is left.
+func (b *tokenBucket) allow(now time.Time, rate float64, burst int) bool {
+	b.refill(now, rate, burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+	}
+	b.last = now
+}
+
+// keyedLimit is a rate limit per value of a field, see WithKeyedRateLimit.
+type keyedLimit struct {
+	field string
+	rate  float64
+	burst int
+	now   func() time.Time
+
+	// used with logLock held
+	buckets map[string]*tokenBucket
+	dropped map[string]int
+	swept   time.Time
+}
+
+// keyedSweepInterval is how often buckets of idle keys are removed.
+const keyedSweepInterval = time.Minute
+
+// WithKeyedRateLimit limits the entries of every value of the field, e.g.
+// WithKeyedRateLimit("tenant", 10, 100) lets each tenant log bursts of 100
+// entries and 10 entries a second on average, so one noisy tenant can't
+// crowd out the others. Entries without the field, panics and fatals are
+// not limited, legal holds and ring buffers still receive dropped entries.
+// When entries of a value pass again after some were dropped, a warning
+// with the field and the number of dropped entries is logged first.
+func WithKeyedRateLimit(field string, rate float64, burst int) LogOption {
+	return func(l *logger) {
+		if rate <= 0 || burst < 1 {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: rate limit of %s needs a positive rate and burst", field))
+			return
+		}
+		l.limits = append(l.limits, &keyedLimit{
+			field:   field,
+			rate:    rate,
+			burst:   burst,
+			now:     clock,
+			buckets: map[string]*tokenBucket{},
+			dropped: map[string]int{},
+		})
+	}
+}
+
+// allow reports whether the entry passes, notice is the warning about
+// entries dropped before it.
+func (k *keyedLimit) allow(e Entry) (ok bool, notice *Entry) {
+	v, found := e.Fields.lookup(k.field)
+	if !found || e.Level.Severity() <= LevelPanic {
+		return true, nil
+	}
+
+	now := k.now()
+	k.sweep(now)
+
+	key := fmt.Sprint(v)
+	b, found := k.buckets[key]
+	if !found {
+		b = newTokenBucket(k.burst, now)
+		k.buckets[key] = b
+	}
+	if !b.allow(now, k.rate, k.burst) {
+		k.dropped[key]++
+		return false, nil
+	}
+
+	if n := k.dropped[key]; n > 0 {
+		delete(k.dropped, key)
+		notice = &Entry{
+			Time:    now,
+			Level:   LevelWarning,
+			Message: "log entries dropped by rate limit",
+			Fields:  LogFields{k.field: v, "dropped": n},
+		}
+	}
+
+	return true, notice
+}
+
+// sweep removes the buckets of keys which are full again and have no
+// dropped entries to report, they behave like new buckets.
+func (k *keyedLimit) sweep(now time.Time) {
+	if now.Sub(k.swept) < keyedSweepInterval {
+		return
+	}
+	k.swept = now
+
+	for key, b := range k.buckets {
+		b.refill(now, k.rate, k.burst)
+		if b.tokens >= float64(k.burst) && k.dropped[key] == 0 {
+			delete(k.buckets, key)
+		}
+	}
+}
+
+// levelLimit is a rate limit of a level, see WithRateLimit.
+type levelLimit struct {
+	rate   float64
+	burst  int
+	bucket *tokenBucket
+	// dropped entries since the last report
+	dropped  int
+	reported time.Time
+}
+
+// rateLimitReport is how often the entries dropped by WithRateLimit are
+// reported.
+const rateLimitReport = 10 * time.Second
+
+// WithRateLimit limits the entries of the level to rate per second, e.g.
+// WithRateLimit(LevelDebug, 500), with bursts of up to a second's worth.
+// Excess entries are dropped and counted, a warning with the number of
+// dropped entries is logged at most every 10 seconds and when the logger is
+// closed. Panics and fatals are not limited, legal holds and ring buffers
+// still receive dropped entries. A level added with RegisterLevel gets a
+// limit of its own, without one it shares the limit of its severity.
+func WithRateLimit(lvl Level, rate float64) LogOption {
+	return func(l *logger) {
+		if rate <= 0 {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: rate limit of %s needs a positive rate", lvl))
+			return
+		}
+		burst := int(rate)
+		if burst < 1 {
+			burst = 1
+		}
+		if l.levelLimits == nil {
+			l.levelLimits = map[Level]*levelLimit{}
+		}
+		now := clock()
+		l.levelLimits[lvl] = &levelLimit{rate: rate, burst: burst, bucket: newTokenBucket(burst, now), reported: now}
+	}
+}
+
+// report returns the warning about the entries dropped since the last one,
+// nil when none were dropped or it is too early unless force is set.
+func (ll *levelLimit) report(lvl Level, now time.Time, force bool) *Entry {
+	if ll.dropped == 0 || (!force && now.Sub(ll.reported) < rateLimitReport) {
+		return nil
+	}
+	e := &Entry{
+		Time:    now,
+		Level:   LevelWarning,
+		Message: "log entries dropped by rate limit",
+		Fields:  LogFields{"limited_level": lvl.String(), "dropped": ll.dropped},
+	}
+	ll.dropped = 0
+	ll.reported = now
+
+	return e
+}
+
+// limitLevel applies the rate limit of the level of the entry, reports the
+// entries dropped by the level limits when due and reports whether the
+// entry is dropped.
+func (l *logger) limitLevel(e Entry) bool {
+	now := clock()
+	for lvl, ll := range l.levelLimits {
+		if notice := ll.report(lvl, now, false); notice != nil && l.passes(notice.Level) {
+			l.emit(*notice)
+		}
+	}
+
+	ll, ok := l.levelLimits[e.Level]
+	if !ok {
+		ll, ok = l.levelLimits[e.Level.Severity()]
+	}
+	if !ok || e.Level.Severity() <= LevelPanic || ll.bucket.allow(now, ll.rate, ll.burst) {
+		return false
+	}
+	ll.dropped++
+
+	return true
+}
+
+// flushRateLimits reports the entries dropped by the level limits, with
+// logLock held.
+func (l *logger) flushRateLimits() {
+	now := clock()
+	for lvl, ll := range l.levelLimits {
+		if notice := ll.report(lvl, now, true); notice != nil && l.passes(notice.Level) {
+			l.emit(*notice)
+		}
+	}
+}
+
+// limited applies the rate limits and reports whether the entry is
+// dropped. It is called with logLock held.
+func (l *logger) limited(e Entry) bool {
+	if len(l.levelLimits) > 0 && l.limitLevel(e) {
+		return true
+	}
+	for _, k := range l.limits {
+		ok, notice := k.allow(e)
+		if notice != nil && l.passes(notice.Level) {
+			l.emit(*notice)
+		}
+		if !ok {
+			return true
+		}
+	}
+
+	return false
+}
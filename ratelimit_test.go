@@ -0,0 +1,93 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedRateLimit(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithKeyedRateLimit("tenant", 1, 2)).(*logger)
+	now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	l.limits[0].now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		l.With(LogFields{"tenant": "noisy"}).Info("request")
+	}
+	l.With(LogFields{"tenant": "quiet"}).Info("request")
+	l.Info("no tenant")
+	now = now.Add(time.Second)
+	l.With(LogFields{"tenant": "noisy"}).Info("request")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"INFO : tenant=noisy request",
+		"INFO : tenant=noisy request",
+		"INFO : tenant=quiet request",
+		"INFO : no tenant",
+		"WARN : dropped=3 tenant=noisy log entries dropped by rate limit",
+		"INFO : tenant=noisy request",
+	}, trimLines(lines))
+
+	// idle keys are forgotten
+	now = now.Add(2 * keyedSweepInterval)
+	l.With(LogFields{"tenant": "quiet"}).Info("request")
+	assert.Len(t, l.limits[0].buckets, 1)
+}
+
+func trimLines(lines []string) []string {
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+func TestRateLimit(t *testing.T) {
+	defer Replay(ReplayConfig{})()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithLevel(LevelDebug), WithRateLimit(LevelDebug, 2)).(*logger)
+
+	for i := 0; i < 5; i++ {
+		l.Debugf("poll %d", i)
+	}
+	l.Info("not limited")
+	l.levelLimits[LevelDebug].reported = time.Time{} // report due
+	l.Info("next")
+	l.Debug("still limited")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"DEBUG: poll 0",
+		"DEBUG: poll 1",
+		"INFO : not limited",
+		"WARN : dropped=3 limited_level=debug log entries dropped by rate limit",
+		"INFO : next",
+		"WARN : dropped=1 limited_level=debug log entries dropped by rate limit",
+	}, trimLines(lines))
+}
+
+func TestRateLimitCustomLevel(t *testing.T) {
+	defer Replay(ReplayConfig{})()
+
+	notice := Level(100)
+	if _, ok := levelMap[notice]; !ok {
+		assert.NoError(t, RegisterLevel(100, "Notice", "NOTE : ", 5))
+		assert.NoError(t, RegisterLevel(101, "critical", "CRIT : ", 2))
+	}
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithRateLimit(LevelInfo, 1), WithRateLimit(notice, 2))
+	for i := 0; i < 3; i++ {
+		l.Log(notice, "note")
+		l.Info("info")
+	}
+
+	assert.Equal(t, 2, strings.Count(out.String(), "NOTE : note"))
+	assert.Equal(t, 1, strings.Count(out.String(), "INFO : info"))
+}
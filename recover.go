@@ -0,0 +1,56 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecoverAndLog recovers a panic of the calling goroutine and logs it with
+// l, it is meant to be deferred:
+//
+//	defer log.RecoverAndLog(logger)
+//
+// The entry has the Panic level and the fields panic and stacktrace, the
+// fields added with With, WithContextFields and PushFields are kept. Unlike
+// Panic it neither closes the logger nor panics, the goroutine returns
+// normally from the function deferring it.
+func RecoverAndLog(l Logger) {
+	if rec := recover(); rec != nil {
+		LogRecovered(l, rec)
+	}
+}
+
+// RecoverAndRepanic is RecoverAndLog panicking again with the recovered
+// value once it is logged, for panics which must still crash the process.
+func RecoverAndRepanic(l Logger) {
+	if rec := recover(); rec != nil {
+		LogRecovered(l, rec)
+		panic(rec)
+	}
+}
+
+// LogRecovered logs a value returned by recover like RecoverAndLog, for
+// deferred functions doing more than logging. An error value is added as the
+// error field as well, so error reporters receive it.
+func LogRecovered(l Logger, rec interface{}) {
+	fields := LogFields{
+		"panic":      fmt.Sprint(rec),
+		"stacktrace": panicStack(),
+	}
+	if err, ok := rec.(error); ok {
+		fields["error"] = err
+	}
+
+	l.With(fields).Log(LevelPanic, "recovered panic: ", rec)
+}
+
+// panicStack returns the stack of the panicking goroutine from the function
+// which panicked on.
+func panicStack() Stacktrace {
+	st := captureStack(32)
+	for len(st) > 0 && strings.HasPrefix(st[0].Function, "runtime.") {
+		st = st[1:]
+	}
+
+	return st
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	var entries []Entry
+	l := New(nil, WithoutStdout(), WithHook(HookFunc(func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})))
+	defer l.Close()
+
+	func() {
+		defer PushFields(LogFields{"job": "sync"})()
+		defer RecoverAndLog(l)
+		panic("boom")
+	}()
+
+	assert.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, LevelPanic, e.Level)
+	assert.Equal(t, "recovered panic: boom", e.Message)
+	assert.Equal(t, "boom", e.Fields["panic"])
+	assert.Equal(t, "sync", e.Fields["job"])
+	st := e.Fields["stacktrace"].(Stacktrace)
+	assert.Contains(t, st[0].Function, "TestRecoverAndLog.func")
+	assert.Equal(t, "recover_test.go", filepath.Base(st[0].File))
+
+	err := errors.New("broken")
+	assert.PanicsWithValue(t, err, func() {
+		defer RecoverAndRepanic(l)
+		panic(err)
+	})
+	assert.Len(t, entries, 2)
+	assert.Equal(t, err, entries[1].Fields["error"])
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"crypto/rand"
+	mrand "math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReplayConfig fixes the sources of nondeterminism used by the package.
+type ReplayConfig struct {
+	// Start is the first time returned by the clock, 2000-01-01 UTC when zero.
+	Start time.Time
+	// Step advances the clock on every reading, one millisecond when zero.
+	Step time.Duration
+	// Seed makes every step jitter by up to Step and seeds random ids, such
+	// as Sentry event ids. Runs with the same seed produce the same output.
+	Seed int64
+	// File and Line are reported as the caller, replay.go:1 when empty.
+	File string
+	Line int
+	// Hostname reported by sinks, "replay-host" when empty.
+	Hostname string
+	// Pid reported by sinks, 1 when zero.
+	Pid int
+}
+
+var replay struct {
+	sync.Mutex
+	on   bool
+	cfg  ReplayConfig
+	t    time.Time
+	rand *mrand.Rand
+}
+
+// Replay switches the package into a deterministic mode: the clock, the
+// caller, the hostname, the process id and random ids come from cfg, so
+// formatter and sink output is reproducible byte for byte, e.g. for golden
+// files. It affects all loggers until restore is called and is meant for
+// tests, which must not run in parallel with others using the package.
+func Replay(cfg ReplayConfig) (restore func()) {
+	if cfg.Start.IsZero() {
+		cfg.Start = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = time.Millisecond
+	}
+	if cfg.File == "" {
+		cfg.File, cfg.Line = "replay.go", 1
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname = "replay-host"
+	}
+	if cfg.Pid == 0 {
+		cfg.Pid = 1
+	}
+
+	replay.Lock()
+	defer replay.Unlock()
+	replay.on = true
+	replay.cfg = cfg
+	replay.t = cfg.Start
+	replay.rand = mrand.New(mrand.NewSource(cfg.Seed))
+
+	return func() {
+		replay.Lock()
+		defer replay.Unlock()
+		replay.on = false
+	}
+}
+
+func replaying() bool {
+	replay.Lock()
+	defer replay.Unlock()
+
+	return replay.on
+}
+
+// clock returns the current time, or the next replay time.
+func clock() time.Time {
+	replay.Lock()
+	defer replay.Unlock()
+
+	if !replay.on {
+		return time.Now()
+	}
+
+	t := replay.t
+	step := replay.cfg.Step
+	if replay.cfg.Seed != 0 {
+		step += time.Duration(replay.rand.Int63n(int64(replay.cfg.Step)))
+	}
+	replay.t = t.Add(step)
+
+	return t
+}
+
+func hostname() (string, error) {
+	replay.Lock()
+	defer replay.Unlock()
+
+	if replay.on {
+		return replay.cfg.Hostname, nil
+	}
+
+	return os.Hostname()
+}
+
+func pid() int {
+	replay.Lock()
+	defer replay.Unlock()
+
+	if replay.on {
+		return replay.cfg.Pid
+	}
+
+	return os.Getpid()
+}
+
+// replayCaller returns the fixed caller when replaying.
+func replayCaller() (string, int, bool) {
+	replay.Lock()
+	defer replay.Unlock()
+
+	return replay.cfg.File, replay.cfg.Line, replay.on
+}
+
+// randomBytes fills b from crypto/rand, or the seeded source when replaying.
+func randomBytes(b []byte) {
+	replay.Lock()
+	defer replay.Unlock()
+
+	if replay.on {
+		replay.rand.Read(b)
+		return
+	}
+
+	rand.Read(b)
+}
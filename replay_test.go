@@ -0,0 +1,43 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	run := func() string {
+		restore := Replay(ReplayConfig{Start: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC), Step: time.Second})
+		defer restore()
+
+		var out bytes.Buffer
+		l := New(&out, WithoutStdout(), WithSecondaryOutput(&out, JsonFormatter{}))
+		l.SetFlags(LstdFlags | Lshortfile | LUTC)
+		l.With(LogFields{"a": 1}).Info("first")
+		l.Warning("second")
+
+		return out.String()
+	}
+
+	first := run()
+	assert.Equal(t, first, run())
+	assert.Equal(t, "INFO : 2020/05/01 12:00:01 replay.go:1: a=1 first\n"+
+		`{"time":"2020/05/01 12:00:02","level":"info","msg":"first","a":1,"file":"replay.go:1"}`+"\n"+
+		"WARN : 2020/05/01 12:00:04 replay.go:1: second\n"+
+		`{"time":"2020/05/01 12:00:05","level":"warning","msg":"second","file":"replay.go:1"}`+"\n", first)
+
+	assert.False(t, replaying())
+}
+
+func TestReplaySeed(t *testing.T) {
+	ticks := func(seed int64) []time.Time {
+		defer Replay(ReplayConfig{Seed: seed})()
+		return []time.Time{clock(), clock(), clock()}
+	}
+
+	assert.Equal(t, ticks(7), ticks(7))
+	assert.NotEqual(t, ticks(7), ticks(8))
+}
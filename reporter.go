@@ -0,0 +1,159 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorReport is an Error, Fatal or Panic entry passed to an
+// ErrorReporter.
+type ErrorReport struct {
+	Entry
+	// Err is the error of the entry: the value of the field error when it
+	// holds an error, otherwise of the first field holding one, or nil.
+	Err error
+	// Stack is where the entry was logged, innermost first.
+	Stack Stacktrace
+}
+
+// ErrorReporter sends error reports to a service like Bugsnag or Rollbar.
+// Adapters only translate the report, queueing and retries are done by
+// the ErrorReportHook.
+type ErrorReporter interface {
+	Report(r ErrorReport) error
+}
+
+// PermanentError marks an error returned by an ErrorReporter as not worth
+// retrying, e.g. a rejected API key.
+func PermanentError(err error) error {
+	return permanentError{err}
+}
+
+// ErrorReporterFunc adapts a function to the ErrorReporter interface.
+type ErrorReporterFunc func(r ErrorReport) error
+
+func (f ErrorReporterFunc) Report(r ErrorReport) error {
+	return f(r)
+}
+
+// ErrorReportConfig configures an ErrorReportHook.
+type ErrorReportConfig struct {
+	// QueueSize bounds the number of error reports waiting to be sent,
+	// 100 by default. More reports are dropped.
+	QueueSize int
+	// Backoff of retrying failed reports, DefaultBackoff when zero.
+	Backoff Backoff
+	// StackDepth limits the frames of the stack, 32 by default.
+	StackDepth int
+}
+
+// ErrorReportHook passes Error, Fatal and Panic entries to an
+// ErrorReporter. Error reports are queued and sent in the background,
+// fatal and panic reports are sent before Fire returns as the process is
+// about to stop. Failed reports are retried. It is flushed and stopped
+// when the logger is closed.
+type ErrorReportHook struct {
+	reporter ErrorReporter
+	cfg      ErrorReportConfig
+	reports  chan ErrorReport
+	dropped  uint64
+	once     sync.Once
+	done     chan struct{}
+}
+
+// NewErrorReportHook starts the background sender of the reporter.
+func NewErrorReportHook(r ErrorReporter, cfg ErrorReportConfig) *ErrorReportHook {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Backoff == (Backoff{}) {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.StackDepth <= 0 {
+		cfg.StackDepth = 32
+	}
+
+	h := &ErrorReportHook{
+		reporter: r,
+		cfg:      cfg,
+		reports:  make(chan ErrorReport, cfg.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+
+	return h
+}
+
+// WithErrorReporter passes Error, Fatal and Panic entries to r, see
+// ErrorReportHook.
+func WithErrorReporter(r ErrorReporter) LogOption {
+	return WithHook(NewErrorReportHook(r, ErrorReportConfig{}))
+}
+
+// Fire reports the entry if it is an error or more severe.
+func (h *ErrorReportHook) Fire(e Entry) error {
+	if e.Level.Severity() > LevelError {
+		return nil
+	}
+
+	r := ErrorReport{Entry: e, Err: entryError(e.Fields), Stack: captureStack(h.cfg.StackDepth)}
+	if e.Level.Severity() < LevelError {
+		return h.send(r)
+	}
+
+	select {
+	case h.reports <- r:
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+
+	return nil
+}
+
+// entryError returns the error of the fields, see ErrorReport.Err.
+func entryError(fields LogFields) error {
+	if err, ok := fields["error"].(error); ok {
+		return err
+	}
+	for _, k := range fields.Keys() {
+		if err, ok := fields[k].(error); ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dropped returns the number of reports dropped because the queue was
+// full.
+func (h *ErrorReportHook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Close sends the queued reports and stops the sender.
+func (h *ErrorReportHook) Close() error {
+	h.once.Do(func() {
+		close(h.reports)
+	})
+	<-h.done
+
+	return nil
+}
+
+func (h *ErrorReportHook) run() {
+	defer close(h.done)
+
+	for r := range h.reports {
+		if err := h.send(r); err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to report log entry to %T: %v\n", h.reporter, err)
+		}
+	}
+}
+
+func (h *ErrorReportHook) send(r ErrorReport) error {
+	return h.cfg.Backoff.retry(func() error {
+		return h.reporter.Report(r)
+	})
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorReportHook(t *testing.T) {
+	var mu sync.Mutex
+	var reports []ErrorReport
+	attempts := 0
+	reporter := ErrorReporterFunc(func(r ErrorReport) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if attempts++; attempts == 1 {
+			return errors.New("unavailable")
+		}
+		if r.Message == "rejected" {
+			return PermanentError(errors.New("invalid key"))
+		}
+		reports = append(reports, r)
+		return nil
+	})
+
+	hook := NewErrorReportHook(reporter, ErrorReportConfig{Backoff: Backoff{MaxRetries: 2, Min: time.Millisecond, Max: time.Millisecond}})
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithHook(hook))
+
+	errDB := errors.New("connection reset")
+	l.With(LogFields{"error": errDB, "table": "users"}).Error("query failed")
+	l.Warning("not reported")
+	l.With(LogFields{"cause": errDB}).Error("other field")
+	l.Error("rejected")
+	l.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 4, attempts, "the first report is retried, permanent errors are not")
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "query failed", reports[0].Message)
+		assert.Equal(t, errDB, reports[0].Err)
+		assert.Equal(t, "users", reports[0].Fields["table"])
+		if assert.NotEmpty(t, reports[0].Stack) {
+			assert.True(t, strings.HasSuffix(reports[0].Stack[0].File, "reporter_test.go"), reports[0].Stack[0].File)
+		}
+		assert.Equal(t, errDB, reports[1].Err)
+	}
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Fields of retry attempts logged by the adapters of retry libraries.
+const (
+	RetryOperationField = "operation"
+	RetryAttemptField   = "attempt"
+	RetryWaitField      = "wait"
+	RetryLeftField      = "retries_left"
+)
+
+// BackoffNotify returns a notify function for github.com/cenkalti/backoff,
+// logging every failed attempt of the operation as a warning with the
+// fields operation, attempt, wait, error and error.kind:
+//
+//	backoff.RetryNotify(fetch, backoff.NewExponentialBackOff(), log.BackoffNotify(logger, "fetch feed"))
+func BackoffNotify(l Logger, operation string) func(error, time.Duration) {
+	var attempt int64
+	return func(err error, wait time.Duration) {
+		fields := ErrFields(err)
+		fields[RetryOperationField] = operation
+		fields[RetryAttemptField] = atomic.AddInt64(&attempt, 1)
+		fields[RetryWaitField] = wait.String()
+		l.With(fields).Warning("retrying after failure")
+	}
+}
+
+// LeveledLogger adapts a Logger to the leveled key/value logger interface
+// of github.com/hashicorp/go-retryablehttp, e.g.
+// client.Logger = log.NewLeveledLogger(logger). Keys and values become
+// fields, the keys of retry attempts are renamed to the fields used by
+// BackoffNotify and errors add error and error.kind.
+type LeveledLogger struct {
+	l Logger
+}
+
+// NewLeveledLogger creates the adapter.
+func NewLeveledLogger(l Logger) LeveledLogger {
+	return LeveledLogger{l: l}
+}
+
+// retryKeys maps keys of go-retryablehttp to the retry fields.
+var retryKeys = map[string]string{
+	"request":   RetryOperationField,
+	"timeout":   RetryWaitField,
+	"remaining": RetryLeftField,
+}
+
+// Error logs msg with the Error severity.
+func (a LeveledLogger) Error(msg string, keysAndValues ...interface{}) {
+	a.l.With(keyValueFields(keysAndValues)).Error(msg)
+}
+
+// Warn logs msg with the Warning severity.
+func (a LeveledLogger) Warn(msg string, keysAndValues ...interface{}) {
+	a.l.With(keyValueFields(keysAndValues)).Warning(msg)
+}
+
+// Info logs msg with the Info severity.
+func (a LeveledLogger) Info(msg string, keysAndValues ...interface{}) {
+	a.l.With(keyValueFields(keysAndValues)).Info(msg)
+}
+
+// Debug logs msg with the Debug severity.
+func (a LeveledLogger) Debug(msg string, keysAndValues ...interface{}) {
+	a.l.With(keyValueFields(keysAndValues)).Debug(msg)
+}
+
+// keyValueFields converts alternating keys and values to fields, a value
+// without key is kept under "extra".
+func keyValueFields(kv []interface{}) LogFields {
+	fields := LogFields{}
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields["extra"] = kv[i]
+			break
+		}
+
+		key := fmt.Sprint(kv[i])
+		if k, ok := retryKeys[key]; ok {
+			key = k
+		}
+		switch v := kv[i+1].(type) {
+		case error:
+			for k, ev := range ErrFields(v) {
+				fields[k] = ev
+			}
+		case time.Duration:
+			fields[key] = v.String()
+		default:
+			fields[key] = v
+		}
+	}
+
+	return fields
+}
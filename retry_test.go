@@ -0,0 +1,47 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffNotify(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0))
+
+	notify := BackoffNotify(l, "fetch feed")
+	notify(context.DeadlineExceeded, 500*time.Millisecond)
+	notify(context.DeadlineExceeded, time.Second)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, `WARN : attempt=1 error="context deadline exceeded" error.kind=timeout operation="fetch feed" wait=500ms retrying after failure`, lines[0])
+		assert.Contains(t, lines[1], "attempt=2")
+		assert.Contains(t, lines[1], "wait=1s")
+	}
+}
+
+func TestLeveledLogger(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithLevel(LevelDebug))
+
+	// the calls of go-retryablehttp
+	a := NewLeveledLogger(l)
+	a.Debug("performing request", "method", "GET", "url", "http://feed")
+	a.Debug("retrying request", "request", "GET http://feed", "timeout", 2*time.Second, "remaining", 3)
+	a.Error("request failed", "error", context.DeadlineExceeded, "method", "GET", "url", "http://feed")
+	a.Warn("odd", "key")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "DEBUG: method=GET url=http://feed performing request", lines[0])
+		assert.Equal(t, `DEBUG: operation="GET http://feed" retries_left=3 wait=2s retrying request`, lines[1])
+		assert.Equal(t, `ERROR: error="context deadline exceeded" error.kind=timeout method=GET url=http://feed request failed`, lines[2])
+		assert.Equal(t, "WARN : extra=key odd", lines[3])
+	}
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// RingBuffer keeps the last entries of a logger in memory, including levels
+// the logger does not write. A service can log at LevelInfo and still show
+// the debug entries which led to a crash.
+type RingBuffer struct {
+	mu        sync.Mutex
+	entries   []Entry
+	next      int
+	full      bool
+	formatter Formatter
+	crash     io.Writer
+}
+
+// NewRingBuffer creates a buffer for the last n entries. It dumps them to
+// stderr when a Fatal or Panic entry is logged, see DumpOnCrash.
+func NewRingBuffer(n int) *RingBuffer {
+	if n < 1 {
+		n = 1
+	}
+
+	return &RingBuffer{entries: make([]Entry, n), formatter: StdFormatter{}, crash: consoleWriter{os.Stderr}}
+}
+
+// WithRingBuffer records every entry of the logger in r, whatever the
+// logger level. Entries below the level are built only for the buffer, but
+// enrichers run for them as for written entries.
+func WithRingBuffer(r *RingBuffer) LogOption {
+	return func(l *logger) {
+		l.rings = append(l.rings, r)
+	}
+}
+
+// DumpOnCrash sets the writer receiving the entries when a Fatal or Panic
+// entry is logged, nil disables the dump.
+func (r *RingBuffer) DumpOnCrash(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.crash = w
+}
+
+func (r *RingBuffer) record(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = e
+	if r.next++; r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// Entries returns the buffered entries, oldest first.
+func (r *RingBuffer) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.snapshot()
+}
+
+func (r *RingBuffer) snapshot() []Entry {
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+
+	return append(append([]Entry(nil), r.entries[r.next:]...), r.entries[:r.next]...)
+}
+
+// DumpRecent writes the buffered entries to w, oldest first, one line each
+// with the time, the level tag and the text of the entry.
+func (r *RingBuffer) DumpRecent(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.dump(w)
+}
+
+func (r *RingBuffer) dump(w io.Writer) error {
+	entries := r.snapshot()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "--- last %d log entries ---\n", len(entries))
+	for _, e := range entries {
+		b.WriteString(e.Time.Format(time.RFC3339Nano) + " " + levelTags[e.Level])
+		b.WriteString(r.formatter.Output(Ldisable, e.Level.String(), e.Fields, e.Message) + "\n")
+	}
+	b.WriteString("--- end of log entries ---\n")
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// crashed dumps the entries to the crash writer.
+func (r *RingBuffer) crashed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.crash != nil {
+		r.dump(r.crash)
+	}
+}
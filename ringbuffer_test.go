@@ -0,0 +1,44 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBuffer(t *testing.T) {
+	rb := NewRingBuffer(3)
+	var crash bytes.Buffer
+	rb.DumpOnCrash(&crash)
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithRingBuffer(rb))
+	l.SetFlags(Ldisable)
+	l.Debug("one")
+	l.With(LogFields{"id": 7}).Debug("two")
+	l.Info("three")
+	l.Debug("four")
+
+	assert.Equal(t, "INFO : three\n", out.String())
+	var msgs []string
+	for _, e := range rb.Entries() {
+		msgs = append(msgs, e.Message)
+	}
+	assert.Equal(t, []string{"two", "three", "four"}, msgs)
+
+	var dump bytes.Buffer
+	assert.NoError(t, rb.DumpRecent(&dump))
+	lines := strings.Split(strings.TrimSuffix(dump.String(), "\n"), "\n")
+	if assert.Len(t, lines, 5) {
+		assert.Equal(t, "--- last 3 log entries ---", lines[0])
+		assert.True(t, strings.HasSuffix(lines[1], " DEBUG: id=7 two"), lines[1])
+		assert.True(t, strings.HasSuffix(lines[3], " DEBUG: four"), lines[3])
+	}
+	assert.Empty(t, crash.String())
+
+	assert.Panics(t, func() { l.Panic("boom") })
+	assert.Contains(t, crash.String(), " DEBUG: four\n")
+	assert.Contains(t, crash.String(), " PANIC: boom\n")
+}
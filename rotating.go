@@ -0,0 +1,295 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	megabyte         = 1024 * 1024
+)
+
+// RotationConfig controls when a RotatingFile is rotated and how long its
+// backups are kept. Zero values disable the corresponding limit.
+type RotationConfig struct {
+	// MaxSizeMB rotates the file before it grows beyond this size.
+	MaxSizeMB int
+	// Daily rotates the file on the first write after midnight (local time).
+	Daily bool
+	// MaxAge removes backups older than this.
+	MaxAge time.Duration
+	// MaxBackups is the number of backups to keep.
+	MaxBackups int
+	// Compress gzips backups after rotation.
+	Compress bool
+	// Header, when set, is written at the beginning of every new file.
+	Header func() string
+}
+
+// RotatingFile is an io.WriteCloser writing to a file which is rotated on
+// size or daily boundaries. Backups are named after the file with the
+// rotation time inserted before the extension, e.g. app-2021-05-01T10-00-00.000.log.
+type RotatingFile struct {
+	path string
+	cfg  RotationConfig
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	header int64
+	opened time.Time
+
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// NewRotatingFile opens or creates the file at path for appending.
+func NewRotatingFile(path string, cfg RotationConfig) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, cfg: cfg, now: clock}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewRotatingFileLogger creates a logger writing to a RotatingFile at path.
+func NewRotatingFileLogger(path string, cfg RotationConfig, opts ...LogOption) (Logger, error) {
+	f, err := NewRotatingFile(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(f, opts...), nil
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file = f
+	r.size = fi.Size()
+	r.header = 0
+	r.opened = fi.ModTime()
+	if r.size == 0 {
+		r.opened = r.now()
+		if r.cfg.Header != nil {
+			n, err := f.WriteString(r.cfg.Header())
+			r.size = int64(n)
+			r.header = r.size
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write writes p to the file, rotating it first when needed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.size == r.header {
+		return false
+	}
+	if r.cfg.MaxSizeMB > 0 && r.size+n > int64(r.cfg.MaxSizeMB)*megabyte {
+		return true
+	}
+	if r.cfg.Daily {
+		y1, m1, d1 := r.opened.Date()
+		y2, m2, d2 := r.now().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+
+	return false
+}
+
+// Rotate closes the current file, moves it to a backup and opens a new one.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rotate()
+}
+
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return err
+		}
+		r.file = nil
+	}
+
+	if err := os.Rename(r.path, r.backupName(r.now())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	r.wg.Add(1)
+	go r.mill(r.now())
+
+	return nil
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(r.path)
+	base := strings.TrimSuffix(r.path, ext)
+
+	return base + "-" + t.Format(backupTimeFormat) + ext
+}
+
+type backup struct {
+	path string
+	t    time.Time
+}
+
+// backups returns existing backups, newest first.
+func (r *RotatingFile) backups() ([]backup, error) {
+	ext := filepath.Ext(r.path)
+	prefix := filepath.Base(strings.TrimSuffix(r.path, ext)) + "-"
+
+	files, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return nil, err
+	}
+
+	var res []backup
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		res = append(res, backup{path: filepath.Join(filepath.Dir(r.path), name), t: t})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].t.After(res[j].t)
+	})
+
+	return res, nil
+}
+
+// mill compresses new backups and removes the expired ones.
+func (r *RotatingFile) mill(now time.Time) {
+	defer r.wg.Done()
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	backups, err := r.backups()
+	if err != nil {
+		fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to list log backups of %s: %v\n", r.path, err)
+		return
+	}
+
+	cutoff := now.Add(-r.cfg.MaxAge)
+	for i, b := range backups {
+		expired := (r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups) || (r.cfg.MaxAge > 0 && b.t.Before(cutoff))
+		if expired {
+			if err := os.Remove(b.path); err != nil {
+				fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to remove log backup %s: %v\n", b.path, err)
+			}
+			continue
+		}
+
+		if r.cfg.Compress && !strings.HasSuffix(b.path, compressSuffix) {
+			if err := compressFile(b.path); err != nil {
+				fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to compress log backup %s: %v\n", b.path, err)
+			}
+		}
+	}
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// Close closes the file and waits for pending compression and cleanup.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wg.Wait()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+
+	return err
+}
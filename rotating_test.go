@@ -0,0 +1,56 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	now := time.Date(2021, 5, 1, 23, 59, 0, 0, time.Local)
+
+	r, err := NewRotatingFile(path, RotationConfig{Daily: true, MaxBackups: 1, Compress: true})
+	assert.NoError(t, err)
+	r.now = func() time.Time { return now }
+	r.opened = now
+
+	for i := 0; i < 3; i++ {
+		_, err = r.Write([]byte("line\n"))
+		assert.NoError(t, err)
+		now = now.Add(24 * time.Hour)
+	}
+	assert.NoError(t, r.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "app-2021-05-03T23-59-00.000.log.gz"),
+		path,
+	}, files)
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line\n", string(b))
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := NewRotatingFile(path, RotationConfig{MaxSizeMB: 1})
+	assert.NoError(t, err)
+
+	chunk := make([]byte, megabyte/2+1)
+	for i := 0; i < 2; i++ {
+		_, err = r.Write(chunk)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, r.Close())
+
+	backups, err := r.backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
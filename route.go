@@ -0,0 +1,162 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Matcher selects entries for a route.
+type Matcher func(e Entry) bool
+
+// MatchLevel matches entries at min or more severe levels, e.g.
+// MatchLevel(LevelWarning) matches warnings, errors, panics and fatals.
+func MatchLevel(min Level) Matcher {
+	return func(e Entry) bool {
+		return e.Level.Severity() <= min
+	}
+}
+
+// MatchField matches entries with the field key set to value.
+func MatchField(key string, value interface{}) Matcher {
+	return func(e Entry) bool {
+		v, ok := e.Fields[key]
+		return ok && reflect.DeepEqual(v, value)
+	}
+}
+
+// MatchMessage matches entries whose message contains substr.
+func MatchMessage(substr string) Matcher {
+	return func(e Entry) bool {
+		return strings.Contains(e.Message, substr)
+	}
+}
+
+// MatchAll matches entries matched by all ms.
+func MatchAll(ms ...Matcher) Matcher {
+	return func(e Entry) bool {
+		for _, m := range ms {
+			if !m(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAny matches entries matched by at least one of ms.
+func MatchAny(ms ...Matcher) Matcher {
+	return func(e Entry) bool {
+		for _, m := range ms {
+			if m(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Rule sends the entries selected by its matcher to its sinks, in addition
+// to the other outputs of the logger:
+//
+//	billing := log.Route(log.MatchAll(
+//		log.MatchField("component", "billing"),
+//		log.MatchLevel(log.LevelWarning),
+//	)).To(billingAlerts)
+//	logger := log.NewJsonLogger(log.WithRoute(billing))
+//
+// A rule is a Hook, so it receives entries passing the logger level.
+type Rule struct {
+	match Matcher
+	sinks []Hook
+}
+
+// Route creates a rule for the entries matched by m.
+func Route(m Matcher) *Rule {
+	return &Rule{match: m}
+}
+
+// To adds sinks receiving the matched entries.
+func (r *Rule) To(sinks ...Hook) *Rule {
+	r.sinks = append(r.sinks, sinks...)
+	return r
+}
+
+// WithRoute adds the rule to the logger, its sinks are closed together with
+// the logger.
+func WithRoute(r *Rule) LogOption {
+	return WithHook(r)
+}
+
+// Fire passes a matched entry to all sinks.
+func (r *Rule) Fire(e Entry) error {
+	if !r.match(e) {
+		return nil
+	}
+
+	var errs []string
+	for _, s := range r.sinks {
+		if err := s.Fire(e); err != nil {
+			errs = append(errs, fmt.Sprintf("%T: %v", s, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("route: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Close closes the sinks which are io.Closers.
+func (r *Rule) Close() error {
+	var errs []string
+	for _, s := range r.sinks {
+		if c, ok := s.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Sprintf("%T: %v", s, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("route: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Flush flushes the sinks which are Flushers.
+func (r *Rule) Flush(ctx context.Context) error {
+	var errs []string
+	for _, s := range r.sinks {
+		if f, ok := s.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, fmt.Sprintf("%T: %v", s, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("route: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// CloseContext closes the sinks which are io.Closers like Close, giving up
+// on those still draining when ctx is done.
+func (r *Rule) CloseContext(ctx context.Context) error {
+	var errs []string
+	for _, s := range r.sinks {
+		if c, ok := s.(io.Closer); ok {
+			if err := closeContext(ctx, c); err != nil {
+				errs = append(errs, fmt.Sprintf("%T: %v", s, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("route: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
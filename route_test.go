@@ -0,0 +1,50 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closingHook struct {
+	entries []Entry
+	closed  bool
+}
+
+func (h *closingHook) Fire(e Entry) error {
+	h.entries = append(h.entries, e)
+	return nil
+}
+
+func (h *closingHook) Close() error {
+	h.closed = true
+	return nil
+}
+
+func TestRoute(t *testing.T) {
+	billing := &closingHook{}
+	timeouts := &closingHook{}
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(),
+		WithRoute(Route(MatchAll(MatchField("component", "billing"), MatchLevel(LevelWarning))).To(billing)),
+		WithRoute(Route(MatchAny(MatchMessage("timeout"), MatchField("retry", true))).To(timeouts)),
+	)
+
+	l.With(LogFields{"component": "billing"}).Info("invoice sent")
+	l.With(LogFields{"component": "billing"}).Error("card declined")
+	l.With(LogFields{"component": "search"}).Error("index missing")
+	l.Warning("upstream timeout")
+	l.With(LogFields{"retry": true}).Info("retrying")
+	l.Close()
+
+	if assert.Len(t, billing.entries, 1) {
+		assert.Equal(t, "card declined", billing.entries[0].Message)
+	}
+	if assert.Len(t, timeouts.entries, 2) {
+		assert.Equal(t, "upstream timeout", timeouts.entries[0].Message)
+		assert.Equal(t, "retrying", timeouts.entries[1].Message)
+	}
+	assert.True(t, billing.closed)
+	assert.Equal(t, 5, bytes.Count(out.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,25 @@
+package log
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// runtimeTraceHook mirrors entries as runtime/trace log events.
+type runtimeTraceHook struct{}
+
+func (runtimeTraceHook) Fire(e Entry) error {
+	if trace.IsEnabled() {
+		trace.Log(context.Background(), e.Level.String(), StdFormatter{}.Output(Ldisable, e.Level.String(), e.Fields, e.Message))
+	}
+
+	return nil
+}
+
+// WithRuntimeTrace emits a runtime/trace log event for every entry while
+// tracing is active, with the level as category and the fields and message
+// as text, so entries show up inline in go tool trace timelines. While no
+// trace is recorded the entries are skipped.
+func WithRuntimeTrace() LogOption {
+	return WithHook(runtimeTraceHook{})
+}
@@ -0,0 +1,25 @@
+package log
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRuntimeTrace(t *testing.T) {
+	l := New(&bytes.Buffer{}, WithoutStdout(), WithRuntimeTrace())
+	l.Info("before tracing")
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skip("tracing is already active:", err)
+	}
+	l.With(LogFields{"query": "q1"}).Warning("slow query")
+	trace.Stop()
+
+	assert.Contains(t, buf.String(), "query=q1 slow query")
+	assert.Contains(t, buf.String(), "warning")
+	assert.NotContains(t, buf.String(), "before tracing")
+}
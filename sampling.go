@@ -0,0 +1,231 @@
+package log
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SamplingHeader is the header carrying a SamplingDecision to downstream
+// services, e.g. "1;rate=0.1".
+const SamplingHeader = "Log-Sampled"
+
+// SamplingDecision tells whether the entries of a request are kept, made
+// once per request so correlated entries are kept or dropped together, also
+// by downstream services receiving it in the SamplingHeader.
+type SamplingDecision struct {
+	Sampled bool
+	Rate    float64
+}
+
+// NewSamplingDecision keeps a request with the probability rate.
+func NewSamplingDecision(rate float64) SamplingDecision {
+	var b [8]byte
+	randomBytes(b[:])
+	r := float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+
+	return SamplingDecision{Sampled: r < rate, Rate: rate}
+}
+
+type samplingKey struct{}
+
+// ContextWithSampling returns a context carrying the decision.
+func ContextWithSampling(ctx context.Context, d SamplingDecision) context.Context {
+	return context.WithValue(ctx, samplingKey{}, d)
+}
+
+// SamplingFromContext returns the decision stored in ctx.
+func SamplingFromContext(ctx context.Context) (SamplingDecision, bool) {
+	if ctx == nil {
+		return SamplingDecision{}, false
+	}
+	d, ok := ctx.Value(samplingKey{}).(SamplingDecision)
+
+	return d, ok
+}
+
+// SampleContext returns ctx with a sampling decision: the one it carries
+// already, or a new one keeping the request with the probability rate.
+// Call it where a request enters the service.
+func SampleContext(ctx context.Context, rate float64) (context.Context, SamplingDecision) {
+	if d, ok := SamplingFromContext(ctx); ok {
+		return ctx, d
+	}
+	d := NewSamplingDecision(rate)
+
+	return ContextWithSampling(ctx, d), d
+}
+
+// Header returns the value of the SamplingHeader.
+func (d SamplingDecision) Header() string {
+	sampled := "0"
+	if d.Sampled {
+		sampled = "1"
+	}
+
+	return sampled + ";rate=" + strconv.FormatFloat(d.Rate, 'g', -1, 64)
+}
+
+// ParseSamplingHeader parses the value of the SamplingHeader.
+func ParseSamplingHeader(v string) (SamplingDecision, error) {
+	parts := strings.SplitN(strings.TrimSpace(v), ";", 2)
+	d := SamplingDecision{Rate: 1}
+	switch parts[0] {
+	case "1":
+		d.Sampled = true
+	case "0":
+	default:
+		return d, fmt.Errorf("log: invalid sampling header %q", v)
+	}
+	if len(parts) == 2 {
+		rate, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(parts[1]), "rate="), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return d, fmt.Errorf("log: invalid sampling header %q", v)
+		}
+		d.Rate = rate
+	}
+
+	return d, nil
+}
+
+// WithSampleRate keeps info, debug and trace entries with the probability
+// rate, more severe entries are always kept. Entries logged with a context
+// carrying a SamplingDecision, see SampleContext, follow it, so all entries
+// of a request are kept or dropped together; others are sampled one by
+// one. Kept entries get the fields sampled=true and sample_rate. Legal holds
+// and ring buffers still receive dropped entries.
+func WithSampleRate(rate float64) LogOption {
+	return func(l *logger) {
+		if rate < 0 || rate > 1 {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: sample rate %v is not between 0 and 1", rate))
+			return
+		}
+		l.sampleRate = rate
+		l.sampling = true
+	}
+}
+
+// sampled applies the decision d, or the sample rate when d is nil. It
+// returns false when the entry is dropped and adds the sampling fields
+// otherwise. It is called with logLock held.
+func (l *logger) sampled(e *Entry, d *SamplingDecision) bool {
+	if e.Level.Severity() <= LevelWarning {
+		return true
+	}
+
+	if d == nil {
+		nd := NewSamplingDecision(l.sampleRate)
+		d = &nd
+	}
+	if !d.Sampled {
+		return false
+	}
+
+	e.Fields = e.Fields.clone()
+	e.Fields["sampled"] = true
+	e.Fields["sample_rate"] = d.Rate
+
+	return true
+}
+
+// SamplingConfig configures WithSampling.
+type SamplingConfig struct {
+	// Initial entries of a key are kept in every tick.
+	Initial int
+	// Thereafter every Thereafter-th entry of the key is kept in the rest
+	// of the tick, none when it is 0.
+	Thereafter int
+	// Tick is the interval the counts are reset after, 1s by default.
+	Tick time.Duration
+	// KeyFunc returns the key of an entry, entries with the same key are
+	// counted together. The level and message by default.
+	KeyFunc func(e Entry) string
+}
+
+// sampleCount counts the entries of a key.
+type sampleCount struct {
+	n       int
+	dropped int
+}
+
+// tickSampler keeps the first entries of a key per tick and every n-th
+// one afterwards.
+type tickSampler struct {
+	cfg    SamplingConfig
+	start  time.Time
+	counts map[string]*sampleCount
+}
+
+// WithSampling drops repeated entries: per key and tick the first Initial
+// entries are kept and then every Thereafter-th, e.g. a retry loop logging
+// the same error thousands of times per second is logged
+//
+//	log.WithSampling(log.SamplingConfig{Initial: 100, Thereafter: 10, Tick: time.Second})
+//
+// at most a hundred times and then 10%. A kept entry following dropped ones
+// gets the field sampled_count with the number dropped. Panic and fatal
+// entries are always kept, legal holds and ring buffers still receive
+// dropped entries.
+func WithSampling(cfg SamplingConfig) LogOption {
+	return func(l *logger) {
+		if cfg.Initial < 0 || cfg.Thereafter < 0 || cfg.Tick < 0 {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: invalid sampling %+v", cfg))
+			return
+		}
+		if cfg.Tick == 0 {
+			cfg.Tick = time.Second
+		}
+		if cfg.KeyFunc == nil {
+			cfg.KeyFunc = sampleKey
+		}
+		l.sampler = &tickSampler{cfg: cfg, counts: map[string]*sampleCount{}}
+	}
+}
+
+// sampleKey is the default key of WithSampling.
+func sampleKey(e Entry) string {
+	return e.Level.String() + " " + e.Message
+}
+
+// keep counts the entry and reports whether it is kept, adding the
+// sampled_count field. It is called with logLock held.
+func (s *tickSampler) keep(e *Entry) bool {
+	if e.Level.Severity() <= LevelPanic {
+		return true
+	}
+
+	now := clock()
+	if now.Sub(s.start) >= s.cfg.Tick {
+		s.start = now
+		for key, c := range s.counts {
+			if c.dropped == 0 {
+				delete(s.counts, key)
+			} else {
+				c.n = 0
+			}
+		}
+	}
+
+	key := s.cfg.KeyFunc(*e)
+	c, ok := s.counts[key]
+	if !ok {
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+	c.n++
+
+	if c.n > s.cfg.Initial && (s.cfg.Thereafter == 0 || (c.n-s.cfg.Initial)%s.cfg.Thereafter != 0) {
+		c.dropped++
+		return false
+	}
+	if c.dropped > 0 {
+		e.Fields = e.Fields.clone()
+		e.Fields["sampled_count"] = c.dropped
+		c.dropped = 0
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleRate(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithSampleRate(0))
+
+	kept := ContextWithSampling(context.Background(), SamplingDecision{Sampled: true, Rate: 0.25})
+	dropped := ContextWithSampling(context.Background(), SamplingDecision{Sampled: false, Rate: 0.25})
+	l.InfoCtx(kept, "kept")
+	l.InfoCtx(dropped, "dropped")
+	l.Info("sampled out")
+	l.ErrorCtx(dropped, "always kept")
+
+	assert.Equal(t, []string{
+		"INFO : sample_rate=0.25 sampled=true kept",
+		"ERROR: always kept",
+	}, trimLines(strings.Split(strings.TrimSpace(out.String()), "\n")))
+}
+
+func TestSampleContext(t *testing.T) {
+	ctx, d := SampleContext(context.Background(), 1)
+	assert.Equal(t, SamplingDecision{Sampled: true, Rate: 1}, d)
+
+	// downstream keeps the decision made upstream
+	up, err := ParseSamplingHeader(SamplingDecision{Sampled: false, Rate: 0.1}.Header())
+	assert.NoError(t, err)
+	ctx, d = SampleContext(ContextWithSampling(ctx, up), 1)
+	assert.Equal(t, SamplingDecision{Sampled: false, Rate: 0.1}, d)
+	got, ok := SamplingFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, d, got)
+
+	_, d = SampleContext(context.Background(), 0)
+	assert.False(t, d.Sampled)
+
+	assert.Equal(t, "1;rate=0.5", SamplingDecision{Sampled: true, Rate: 0.5}.Header())
+	_, err = ParseSamplingHeader("yes")
+	assert.Error(t, err)
+}
+
+func TestWithSampling(t *testing.T) {
+	var out bytes.Buffer
+	var held []Entry
+	hold := NewLegalHold(LegalHoldConfig{
+		Sink:    HookFunc(func(e Entry) error { held = append(held, e); return nil }),
+		UserIDs: []string{"42"},
+	})
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithLegalHold(hold),
+		WithSampling(SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Hour}))
+
+	for i := 0; i < 9; i++ {
+		l.Error("connection refused")
+	}
+	for i := 0; i < 4; i++ {
+		l.With(LogFields{"user_id": 42}).Warning("retrying")
+	}
+	l.Info("other")
+	l.(*logger).sampler.start = time.Time{} // next tick
+	l.Error("connection refused")
+
+	assert.Equal(t, "ERROR: connection refused\n"+
+		"ERROR: connection refused\n"+
+		"ERROR: sampled_count=2 connection refused\n"+
+		"ERROR: sampled_count=2 connection refused\n"+
+		"WARN : user_id=42 retrying\n"+
+		"WARN : user_id=42 retrying\n"+
+		"INFO : other\n"+
+		"ERROR: sampled_count=1 connection refused\n", out.String())
+	assert.Len(t, held, 4, "legal holds get the dropped entries")
+}
@@ -0,0 +1,179 @@
+package log
+
+import "strings"
+
+// LoggerField holds the name of a logger created with Named.
+const LoggerField = "logger"
+
+// top returns the logger l was derived from, e.g. with Named or With, l
+// itself when it was created by a constructor. Derived loggers share its
+// level, outputs, subscribers and state.
+func (l *logger) top() *logger {
+	if l.root != nil {
+		return l.root
+	}
+
+	return l
+}
+
+// WithMutableWith keeps the behavior With had before it returned a new
+// logger: the fields are added to the logger itself and cleared by its next
+// entry, so
+//
+//	logger.With(fields)
+//	logger.Info("sent")
+//
+// logs the fields once. Loggers shared by goroutines mix up their fields
+// this way, it is meant for code not yet using the logger returned by With.
+func WithMutableWith() LogOption {
+	return func(l *logger) {
+		l.mutableWith = true
+	}
+}
+
+// derive returns a logger sharing the outputs, hooks and state of l, with
+// its own fields.
+func (l *logger) derive() *logger {
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	c := *l
+	c.root = l.top()
+	c.fields = LogFields{}
+	c.typedCache = nil
+	c.ctx = nil
+
+	return &c
+}
+
+// Named returns a logger whose entries carry the field logger with the
+// dotted path of names, e.g. logger=api.db for
+// logger.Named("api").Named("db"). Text formatters print it as a prefix,
+// "[api.db]". The level of the name set with SetNamedLevel applies to its
+// entries, SetLevel of the returned logger sets it. The logger shares the
+// outputs and the state of l, closing it closes l.
+func (l *logger) Named(name string) Logger {
+	c := l.derive()
+	if c.name != "" {
+		name = c.name + "." + name
+	}
+	c.name = name
+	c.bind(LogFields{LoggerField: name})
+
+	return c
+}
+
+// WithGroup returns a logger prefixing the keys of the fields added with
+// With by name and a dot, e.g. http.status=200 for
+// logger.WithGroup("http").With(log.LogFields{"status": 200}). Groups
+// nest. Options naming fields, e.g. WithFieldEncryption, WithLegalHold,
+// WithKeyedRateLimit and WithSinkFieldMap, match the keys without the
+// group. The logger shares the outputs and the state of l.
+func (l *logger) WithGroup(name string) Logger {
+	c := l.derive()
+	if c.group != "" {
+		name = c.group + "." + name
+	}
+	c.group = name
+
+	return c
+}
+
+// grouped returns fields with the keys prefixed by the group of l.
+func (l *logger) grouped(fields LogFields) LogFields {
+	if l.group == "" || len(fields) == 0 {
+		return fields
+	}
+
+	g := make(LogFields, len(fields))
+	for k, v := range fields {
+		if k == fieldOrderKey {
+			continue
+		}
+		g[l.group+"."+k] = v
+	}
+	if order := fields.order(); order != nil {
+		prefixed := make([]string, len(order))
+		for i, k := range order {
+			prefixed[i] = l.group + "." + k
+		}
+		g[fieldOrderKey] = prefixed
+	}
+
+	return g
+}
+
+// ungroup matches the key of a field against has, trying the key itself
+// and then the key without its group prefixes, e.g. api.user.ssn, user.ssn
+// and ssn, so options naming a field also apply to it in a group. It
+// returns the prefix stripped from the key and the name matched.
+func ungroup(key string, has func(name string) bool) (prefix, name string, ok bool) {
+	for i := 0; ; {
+		if has(key[i:]) {
+			return key[:i], key[i:], true
+		}
+		dot := strings.IndexByte(key[i:], '.')
+		if dot < 0 {
+			return "", "", false
+		}
+		i += dot + 1
+	}
+}
+
+// lookup returns the value of the field name, or of the field name in a
+// group, e.g. user.id for id, when there is no field name.
+func (l LogFields) lookup(name string) (interface{}, bool) {
+	if v, ok := l[name]; ok {
+		return v, true
+	}
+	suffix := "." + name
+	for k, v := range l {
+		if strings.HasSuffix(k, suffix) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// entryFields returns the fields of the next entry: those bound to the
+// logger, typed or not, and those added for this entry by With with
+// WithMutableWith. It is called with logLock held.
+func (l *logger) entryFields() LogFields {
+	bound := l.bound
+	if len(l.typed) > 0 {
+		bound = l.typedFields().fields
+	}
+	if len(bound) == 0 {
+		return l.fields
+	}
+
+	return bound.Add(l.fields)
+}
+
+// effectiveLevel returns the level applying to the entries of l.
+func (l *logger) effectiveLevel() Level {
+	t := l.top()
+	if l.name == "" {
+		return t.loadLevel()
+	}
+	named := t.namedLevels()
+	if len(named) == 0 {
+		return t.loadLevel()
+	}
+	lvl, _ := named.effective(l.name, t.loadLevel())
+
+	return lvl
+}
+
+// Named returns a logger of the default logger with the name, see
+// Logger.Named.
+func Named(name string) Logger {
+	return defaultLogger.Named(name)
+}
+
+// WithGroup returns a logger of the default logger prefixing field keys
+// with the group, see Logger.WithGroup.
+func WithGroup(name string) Logger {
+	return defaultLogger.WithGroup(name)
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamed(t *testing.T) {
+	var out, js bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithSecondaryOutput(&js, JsonFormatter{}))
+	db := l.Named("api").Named("db")
+
+	db.With(LogFields{"table": "users"}).Info("query")
+	db.Info("again")
+	l.Info("root")
+	db.SetLevel(LevelWarning)
+	db.Info("hidden")
+	l.Info("still shown")
+
+	assert.Equal(t, "INFO : [api.db] table=users query\n"+
+		"INFO : [api.db] again\n"+
+		"INFO : root\n"+
+		"INFO : still shown\n", out.String())
+	assert.Contains(t, js.String(), `"logger":"api.db"`)
+	lvl, from := l.EffectiveLevel("api.db")
+	assert.Equal(t, LevelWarning, lvl)
+	assert.Equal(t, "api.db", from)
+}
+
+func TestWithGroup(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+	req := l.WithGroup("http")
+
+	req.With(LogFields{"status": 200}).Info("served")
+	req.WithGroup("client").With(Ordered("ip", "10.0.0.1", "agent", "curl")).Info("client")
+	l.With(LogFields{"status": 500}).Info("ungrouped")
+
+	assert.Equal(t, "INFO : http.status=200 served\n"+
+		"INFO : http.client.ip=10.0.0.1 http.client.agent=curl client\n"+
+		"INFO : status=500 ungrouped\n", out.String())
+}
+
+func TestWithGroupFieldOptions(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out, legacy bytes.Buffer
+	var held []Entry
+	hold := NewLegalHold(LegalHoldConfig{
+		Sink:    HookFunc(func(e Entry) error { held = append(held, e); return nil }),
+		UserIDs: []string{"42"},
+	})
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithOutput(&legacy),
+		WithFieldEncryption([]string{"ssn"}, &priv.PublicKey), WithLegalHold(hold),
+		WithSinkFieldMap(&legacy, map[string]string{"user_id": "uid", "ssn": ""}))
+
+	l.WithGroup("user").With(LogFields{"user_id": 42, "ssn": "123-45-6789"}).Info("signup")
+
+	assert.NotContains(t, out.String(), "123-45-6789")
+	assert.Contains(t, out.String(), "user.ssn=enc:v1:")
+	assert.Equal(t, "INFO : user.uid=42 signup\n", legacy.String())
+	if assert.Len(t, held, 1) {
+		assert.Equal(t, "user_id=42", held[0].Fields["legal_hold"])
+	}
+}
+
+func TestNamedSharesState(t *testing.T) {
+	out := &closeRecorder{}
+	l := New(out, WithoutStdout(), WithFlags(Ldisable))
+	child := l.Named("worker")
+
+	entries, cancel := l.Subscribe(nil)
+	defer cancel()
+	child.Info("published")
+	e := <-entries
+	assert.Equal(t, "worker", e.Fields[LoggerField])
+
+	l.SetLevel(LevelError)
+	child.Info("filtered")
+	child.Close()
+	assert.True(t, out.closed)
+	assert.Equal(t, "INFO : [worker] published\n", out.String())
+}
+
+func TestWithChild(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+	req := l.With(LogFields{"request_id": "r1"})
+	user := req.With(LogFields{"user": "ann"})
+
+	req.Info("started")
+	user.Info("authorized")
+	req.Info("finished")
+	l.Info("idle")
+
+	assert.Equal(t, "INFO : request_id=r1 started\n"+
+		"INFO : request_id=r1 user=ann authorized\n"+
+		"INFO : request_id=r1 finished\n"+
+		"INFO : idle\n", out.String())
+}
+
+func TestMutableWith(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithMutableWith())
+
+	l.With(LogFields{"user": "ann"})
+	l.Info("once")
+	l.Info("cleared")
+
+	assert.Equal(t, "INFO : user=ann once\nINFO : cleared\n", out.String())
+}
+
+func TestNamedLevelConcurrent(t *testing.T) {
+	l := New(nil, WithoutStdout())
+	db := l.Named("db")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			db.Info("query")
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		l.SetNamedLevel("db", Level(i%int(LevelTrace+1)))
+		l.ResetNamedLevel("db.pool")
+	}
+	<-done
+}
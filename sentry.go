@@ -0,0 +1,335 @@
+package log
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SentryConfig configures a SentryHook.
+type SentryConfig struct {
+	// DSN of the project, e.g. https://<key>@o1.ingest.sentry.io/<project>.
+	DSN         string
+	Environment string
+	Release     string
+	// TagFields lists fields sent as tags, other fields are sent as extra.
+	// When nil, short scalar fields are tags and the rest is extra.
+	TagFields []string
+	// QueueSize bounds the number of error events waiting to be sent, more
+	// events are dropped. Fatal and panic events are sent before returning,
+	// once and within two seconds.
+	QueueSize int
+	Backoff   Backoff
+	Client    *http.Client
+}
+
+// SentryHook sends Error, Fatal and Panic entries to Sentry with the stack
+// trace of the logging call. Use it with WithHook or WithSentry, it is
+// flushed and stopped when the logger is closed.
+type SentryHook struct {
+	cfg     SentryConfig
+	url     string
+	headers map[string]string
+	tags    map[string]bool
+	events  chan []byte
+	dropped uint64
+	// mu guards closed, events fired after Close are dropped
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+}
+
+type sentryFrame struct {
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger"`
+	Platform    string                 `json:"platform"`
+	Message     string                 `json:"message"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Stacktrace  struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+// sentryFinalTimeout bounds sending a fatal or panic event, which is done
+// with the logger lock held before the process stops.
+var sentryFinalTimeout = 2 * time.Second
+
+var sentryLevels = map[Level]string{
+	LevelFatal: "fatal",
+	LevelPanic: "fatal",
+	LevelError: "error",
+}
+
+// NewSentryHook parses the DSN and starts the background sender.
+func NewSentryHook(cfg SentryConfig) (*SentryHook, error) {
+	u, err := url.Parse(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("sentry: invalid DSN: %w", err)
+	}
+	project := path.Base(u.Path)
+	if u.User == nil || u.User.Username() == "" || project == "/" || project == "." {
+		return nil, fmt.Errorf("sentry: invalid DSN %q, expected scheme://key@host/project", cfg.DSN)
+	}
+
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Backoff == (Backoff{}) {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	auth := "Sentry sentry_version=7, sentry_client=bialas1993-log/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+
+	h := &SentryHook{
+		cfg:     cfg,
+		url:     fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, strings.TrimSuffix(path.Dir(u.Path), "/"), project),
+		headers: map[string]string{"X-Sentry-Auth": auth},
+		events:  make(chan []byte, cfg.QueueSize),
+		done:    make(chan struct{}),
+	}
+	if cfg.TagFields != nil {
+		h.tags = map[string]bool{}
+		for _, f := range cfg.TagFields {
+			h.tags[f] = true
+		}
+	}
+	go h.run()
+
+	return h, nil
+}
+
+// WithSentry sends Error, Fatal and Panic entries to the Sentry project of
+// the DSN, lower levels go only to the regular outputs.
+func WithSentry(dsn string) LogOption {
+	return func(l *logger) {
+		h, err := NewSentryHook(SentryConfig{DSN: dsn})
+		if err != nil {
+			l.setupErrs = append(l.setupErrs, err)
+			return
+		}
+		WithHook(h)(l)
+	}
+}
+
+// Fire sends the entry if it is an error or more severe. Error events are
+// queued, fatal and panic events are sent right away as the process is
+// about to stop, without retries, so a Sentry outage doesn't delay the
+// exit.
+func (h *SentryHook) Fire(e Entry) error {
+	if e.Level.Severity() > LevelError {
+		return nil
+	}
+
+	body, err := json.Marshal(h.event(e))
+	if err != nil {
+		return err
+	}
+
+	if e.Level.Severity() < LevelError {
+		return h.sendFinal(body)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		atomic.AddUint64(&h.dropped, 1)
+		return nil
+	}
+	select {
+	case h.events <- body:
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+
+	return nil
+}
+
+// Dropped returns the number of events dropped because the queue was full
+// or the hook was closed.
+func (h *SentryHook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Close sends the queued events and stops the sender.
+func (h *SentryHook) Close() error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.events)
+	}
+	h.mu.Unlock()
+	<-h.done
+
+	return nil
+}
+
+func (h *SentryHook) run() {
+	defer close(h.done)
+
+	for body := range h.events {
+		if err := h.send(body); err != nil {
+			fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to send log entry to sentry: %v\n", err)
+		}
+	}
+}
+
+func (h *SentryHook) send(body []byte) error {
+	return h.cfg.Backoff.retry(func() error {
+		return post(h.cfg.Client, h.url, "application/json", h.headers, body)
+	})
+}
+
+// sendFinal sends the event once, giving up after sentryFinalTimeout.
+func (h *SentryHook) sendFinal(body []byte) error {
+	client := *h.cfg.Client
+	if client.Timeout <= 0 || client.Timeout > sentryFinalTimeout {
+		client.Timeout = sentryFinalTimeout
+	}
+
+	err := post(&client, h.url, "application/json", h.headers, body)
+	if p, ok := err.(permanentError); ok {
+		return p.err
+	}
+
+	return err
+}
+
+func (h *SentryHook) event(e Entry) *sentryEvent {
+	ev := &sentryEvent{
+		EventID:     eventID(),
+		Timestamp:   e.Time.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevels[e.Level],
+		Logger:      "log",
+		Platform:    "go",
+		Message:     e.Message,
+		Environment: h.cfg.Environment,
+		Release:     h.cfg.Release,
+		Tags:        map[string]string{},
+		Extra:       map[string]interface{}{},
+	}
+	ev.ServerName, _ = hostname()
+	ev.Stacktrace.Frames = stackFrames()
+
+	for _, k := range e.Fields.Keys() {
+		v := e.Fields[k]
+		if h.isTag(k, v) {
+			ev.Tags[k] = formatValue(v)
+		} else {
+			ev.Extra[k] = sentryExtra(v)
+		}
+	}
+
+	return ev
+}
+
+func (h *SentryHook) isTag(key string, value interface{}) bool {
+	if h.tags != nil {
+		return h.tags[key]
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return len(formatValue(value)) <= 200
+	}
+
+	return false
+}
+
+// sentryExtra keeps values encoding/json can not render meaningfully, such
+// as errors, readable.
+func sentryExtra(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return v
+}
+
+// stackFrames returns the frames outside of this package, oldest first as
+// Sentry expects.
+func stackFrames() []sentryFrame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []sentryFrame
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != pkgDir || strings.HasSuffix(frame.File, "_test.go") {
+			module, function := splitFunction(frame.Function)
+			out = append(out, sentryFrame{
+				Filename: filepath.Base(frame.File),
+				AbsPath:  frame.File,
+				Function: function,
+				Module:   module,
+				Lineno:   frame.Line,
+				InApp:    !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(frame.Function, "testing."),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return out
+}
+
+// splitFunction splits github.com/a/b.(*T).M into the package path and
+// the function name.
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		i := slash + 1 + dot
+		return name[:i], name[i+1:]
+	}
+
+	return "", name
+}
+
+func eventID() string {
+	b := make([]byte, 16)
+	randomBytes(b)
+
+	return hex.EncodeToString(b)
+}
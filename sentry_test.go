@@ -0,0 +1,106 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentryHook(t *testing.T) {
+	var paths, auths []string
+	var events []sentryEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev sentryEvent
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &ev))
+		paths = append(paths, r.URL.Path)
+		auths = append(auths, r.Header.Get("X-Sentry-Auth"))
+		events = append(events, ev)
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"
+	l := New(&bytes.Buffer{}, WithSentry(dsn))
+	l.Info("ignored")
+	l.With(LogFields{"user": "bob", "err": errors.New("timeout")}).Error("request failed")
+	l.Close()
+
+	if !assert.Len(t, events, 1) {
+		return
+	}
+	assert.Equal(t, "/api/42/store/", paths[0])
+	assert.Contains(t, auths[0], "sentry_key=public")
+
+	ev := events[0]
+	assert.Equal(t, "error", ev.Level)
+	assert.Equal(t, "request failed", ev.Message)
+	assert.Equal(t, map[string]string{"user": "bob"}, ev.Tags)
+	assert.Equal(t, map[string]interface{}{"err": "timeout"}, ev.Extra)
+	assert.Len(t, ev.EventID, 32)
+
+	frames := ev.Stacktrace.Frames
+	if assert.NotEmpty(t, frames) {
+		last := frames[len(frames)-1]
+		assert.Equal(t, "sentry_test.go", last.Filename)
+		assert.Equal(t, "TestSentryHook", last.Function)
+		assert.True(t, last.InApp)
+	}
+}
+
+func TestSentryHookAfterClose(t *testing.T) {
+	h, err := NewSentryHook(SentryConfig{DSN: "http://public@127.0.0.1:1/42"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, h.Close())
+
+	assert.NotPanics(t, func() {
+		assert.NoError(t, h.Fire(Entry{Level: LevelError, Message: "after close"}))
+	})
+	assert.Equal(t, uint64(1), h.Dropped())
+}
+
+func TestSentryHookFatal(t *testing.T) {
+	defer func(d time.Duration) { sentryFinalTimeout = d }(sentryFinalTimeout)
+	sentryFinalTimeout = 50 * time.Millisecond
+
+	var requests int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	h, err := NewSentryHook(SentryConfig{DSN: strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer h.Close()
+
+	// no retries of an unavailable server
+	assert.Error(t, h.Fire(Entry{Level: LevelFatal, Message: "down"}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	start := time.Now()
+	assert.Error(t, h.Fire(Entry{Level: LevelPanic, Message: "stuck"}))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSentryInvalidDSN(t *testing.T) {
+	_, err := NewSentryHook(SentryConfig{DSN: "https://sentry.io/42"})
+	assert.Error(t, err)
+}
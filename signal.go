@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// EnableSignalLevelControl lets operators change the level of l without a
+// restart: SIGUSR1 switches it to Debug and SIGUSR2 restores the level l had
+// when the control was enabled. When reload is not nil, SIGHUP calls it and
+// sets the returned level, which SIGUSR2 restores from then on; otherwise
+// SIGHUP keeps its default behavior. Every change is logged. The signals
+// are not available on Windows, where it does nothing. Call the returned
+// function to stop handling the signals.
+func EnableSignalLevelControl(l Logger, reload func() (Level, error)) (stop func()) {
+	if debugSignal == nil {
+		return func() {}
+	}
+
+	signals := []os.Signal{debugSignal, restoreSignal}
+	if reload != nil {
+		signals = append(signals, reloadSignal)
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, signals...)
+
+	configured, _ := l.EffectiveLevel("")
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-c:
+				lvl := configured
+				switch sig {
+				case debugSignal:
+					lvl = LevelDebug
+				case reloadSignal:
+					reloaded, err := reload()
+					if err != nil {
+						l.With(LogFields{"signal": signalName(sig), "error": err.Error()}).Error("Failed to reload log level")
+						continue
+					}
+					configured, lvl = reloaded, reloaded
+				}
+				l.SetLevel(lvl)
+				l.With(LogFields{"signal": signalName(sig), "level": lvl.String()}).Info("log level changed")
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// flushOnExitTimeout bounds the flush of RegisterFlushOnExit.
+const flushOnExitTimeout = 5 * time.Second
+
+// RegisterFlushOnExit flushes the buffering sinks of the default logger,
+// e.g. HTTPSink, when the process gets SIGINT or SIGTERM, so the entries
+// logged right before are not lost, waiting at most 5 seconds. The signal
+// is then raised again, ending the process as it would have without the
+// handler or reaching the handlers of the program. On Windows, where
+// signals can't be raised again, the process exits with status 1 after an
+// interrupt. Call the returned function to stop handling the signals.
+func RegisterFlushOnExit() (stop func()) {
+	signals := []os.Signal{os.Interrupt}
+	if terminateSignal != nil {
+		signals = append(signals, terminateSignal)
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, signals...)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-c:
+			ctx, cancel := context.WithTimeout(context.Background(), flushOnExitTimeout)
+			if err := Flush(ctx); err != nil {
+				fmt.Fprintf(consoleWriter{os.Stderr}, "Failed to flush logs on %s: %v\n", signalName(sig), err)
+			}
+			cancel()
+			signal.Stop(c)
+			raise(sig)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package log
+
+import "os"
+
+var debugSignal, restoreSignal, reloadSignal, terminateSignal os.Signal
+
+var raise = func(sig os.Signal) {
+	os.Exit(1)
+}
+
+func signalName(sig os.Signal) string {
+	return sig.String()
+}
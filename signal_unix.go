@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var debugSignal, restoreSignal, reloadSignal os.Signal = unix.SIGUSR1, unix.SIGUSR2, unix.SIGHUP
+
+var terminateSignal os.Signal = unix.SIGTERM
+
+// raise sends sig to the process again once it is no longer handled.
+var raise = func(sig os.Signal) {
+	if s, ok := sig.(unix.Signal); ok {
+		unix.Kill(os.Getpid(), s)
+	}
+}
+
+func signalName(sig os.Signal) string {
+	if s, ok := sig.(unix.Signal); ok {
+		return unix.SignalName(s)
+	}
+
+	return sig.String()
+}
@@ -0,0 +1,101 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestSignalLevelControl(t *testing.T) {
+	var out syncBuffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable), WithLevel(LevelInfo))
+	stop := EnableSignalLevelControl(l, func() (Level, error) { return LevelError, nil })
+	defer stop()
+
+	level := func(sig unix.Signal, want Level) {
+		unix.Kill(os.Getpid(), sig)
+		assert.Eventually(t, func() bool {
+			lvl, _ := l.EffectiveLevel("")
+			return lvl == want
+		}, time.Second, time.Millisecond, "level after %v", sig)
+	}
+
+	level(unix.SIGUSR1, LevelDebug)
+	level(unix.SIGUSR2, LevelInfo)
+	level(unix.SIGHUP, LevelError)
+	level(unix.SIGUSR1, LevelDebug)
+	level(unix.SIGUSR2, LevelError)
+
+	assert.Contains(t, out.String(), "INFO : level=debug signal=SIGUSR1 log level changed\n")
+}
+
+type flushCounter struct {
+	mu      sync.Mutex
+	flushes int
+}
+
+func (f *flushCounter) Fire(e Entry) error { return nil }
+
+func (f *flushCounter) Flush(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	return nil
+}
+
+func (f *flushCounter) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushes
+}
+
+func TestRegisterFlushOnExit(t *testing.T) {
+	sink := &flushCounter{}
+	old, oldRaise := defaultLogger, raise
+	defaultLogger = New(nil, WithoutStdout(), WithHook(sink)).(*logger)
+	raised := make(chan os.Signal, 1)
+	raise = func(sig os.Signal) { raised <- sig }
+	defer func() {
+		defaultLogger.Close()
+		defaultLogger = old
+		raise = oldRaise
+	}()
+
+	stop := RegisterFlushOnExit()
+	defer stop()
+	unix.Kill(os.Getpid(), unix.SIGTERM)
+
+	select {
+	case sig := <-raised:
+		assert.Equal(t, unix.SIGTERM, sig)
+	case <-time.After(time.Second):
+		t.Fatal("signal not raised again")
+	}
+	assert.Equal(t, 1, sink.count())
+}
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// SLOConfig configures an SLOHook.
+type SLOConfig struct {
+	// Objective is the target ratio of good events, e.g. 0.999.
+	Objective float64
+	// Window over which the error ratio is computed, defaults to one hour.
+	Window time.Duration
+	// Match selects entries counted as events, all entries by default.
+	Match func(e Entry) bool
+	// Bad decides if an event counts against the objective, by default
+	// entries at LevelError or more severe.
+	Bad func(e Entry) bool
+	// Thresholds are burn rates calling OnBurn when exceeded, e.g. 2 and 14.4.
+	Thresholds []float64
+	// OnBurn is called in its own goroutine when the burn rate rises above a
+	// threshold. It is called again only after the rate dropped below it.
+	OnBurn func(burnRate, threshold float64)
+}
+
+const sloBuckets = 60
+
+type sloBucket struct {
+	slot       int64
+	total, bad uint64
+}
+
+// SLOHook tracks a rolling error ratio against an objective and annotates
+// error entries with the current slo_burn_rate. A burn rate of 1 uses up the
+// error budget exactly at the end of the window.
+type SLOHook struct {
+	cfg   SLOConfig
+	width time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets [sloBuckets]sloBucket
+	firing  map[float64]bool
+}
+
+// NewSLOHook creates the hook, add it to a logger with WithSLO or WithEnricher.
+func NewSLOHook(cfg SLOConfig) *SLOHook {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Hour
+	}
+	if cfg.Match == nil {
+		cfg.Match = func(Entry) bool { return true }
+	}
+	if cfg.Bad == nil {
+		cfg.Bad = func(e Entry) bool { return e.Level.Severity() <= LevelError }
+	}
+
+	return &SLOHook{
+		cfg:    cfg,
+		width:  cfg.Window / sloBuckets,
+		now:    clock,
+		firing: map[float64]bool{},
+	}
+}
+
+// WithSLO tracks the objective and annotates error entries of the logger.
+func WithSLO(cfg SLOConfig) LogOption {
+	return WithEnricher(NewSLOHook(cfg))
+}
+
+// Enrich counts the entry and adds slo_burn_rate to bad events.
+func (h *SLOHook) Enrich(e *Entry) {
+	if !h.cfg.Match(*e) {
+		return
+	}
+
+	bad := h.cfg.Bad(*e)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot := h.now().UnixNano() / int64(h.width)
+	b := &h.buckets[slot%sloBuckets]
+	if b.slot != slot {
+		*b = sloBucket{slot: slot}
+	}
+	b.total++
+	if bad {
+		b.bad++
+	}
+
+	rate := h.burnRate(slot)
+	if bad {
+		e.Fields["slo_burn_rate"] = rate
+	}
+	h.escalate(rate)
+}
+
+// BurnRate returns the current burn rate.
+func (h *SLOHook) BurnRate() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.burnRate(h.now().UnixNano() / int64(h.width))
+}
+
+func (h *SLOHook) burnRate(slot int64) float64 {
+	var total, bad uint64
+	for _, b := range h.buckets {
+		if b.slot > slot-sloBuckets {
+			total += b.total
+			bad += b.bad
+		}
+	}
+
+	budget := 1 - h.cfg.Objective
+	if total == 0 || budget <= 0 {
+		return 0
+	}
+
+	// rounded, so fields stay readable and thresholds compare as written
+	return math.Round(float64(bad)/float64(total)/budget*1000) / 1000
+}
+
+func (h *SLOHook) escalate(rate float64) {
+	for _, t := range h.cfg.Thresholds {
+		switch {
+		case rate > t && !h.firing[t]:
+			h.firing[t] = true
+			if h.cfg.OnBurn != nil {
+				go h.cfg.OnBurn(rate, t)
+			}
+		case rate <= t:
+			h.firing[t] = false
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLOHook(t *testing.T) {
+	burns := make(chan float64, 1)
+	h := NewSLOHook(SLOConfig{
+		Objective:  0.9,
+		Window:     time.Minute,
+		Thresholds: []float64{2},
+		OnBurn:     func(rate, threshold float64) { burns <- threshold },
+	})
+	now := time.Unix(1000, 0)
+	h.now = func() time.Time { return now }
+
+	var buf bytes.Buffer
+	l := New(&buf, WithEnricher(h))
+	l.SetFlags(Ldisable)
+	for i := 0; i < 8; i++ {
+		l.Info("ok")
+	}
+	l.Error("failed")
+	l.Error("failed")
+
+	assert.Contains(t, buf.String(), "ERROR: slo_burn_rate=1.111 failed\n")
+	assert.Contains(t, buf.String(), "ERROR: slo_burn_rate=2 failed\n")
+	assert.NotContains(t, buf.String(), "INFO : slo_burn_rate")
+
+	l.Error("failed")
+	select {
+	case threshold := <-burns:
+		assert.Equal(t, 2.0, threshold)
+	case <-time.After(time.Second):
+		t.Fatal("OnBurn not called")
+	}
+
+	now = now.Add(2 * time.Minute)
+	assert.Zero(t, h.BurnRate())
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"io"
+	"net"
+	"time"
+)
+
+func dialSocket(path string, timeout time.Duration) (io.WriteCloser, error) {
+	return net.DialTimeout("unix", path, timeout)
+}
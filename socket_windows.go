@@ -0,0 +1,19 @@
+package log
+
+import (
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// dialSocket opens the client end of a named pipe, other paths are unix
+// domain sockets, supported since Windows 10.
+func dialSocket(path string, timeout time.Duration) (io.WriteCloser, error) {
+	if strings.HasPrefix(path, `\\.\pipe\`) {
+		return os.OpenFile(path, os.O_WRONLY, 0)
+	}
+
+	return net.DialTimeout("unix", path, timeout)
+}
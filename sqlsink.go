@@ -0,0 +1,169 @@
+package log
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// SQLConfig configures an SQLSink.
+type SQLConfig struct {
+	// DB is an open database, e.g. SQLite on an embedded device. The sink
+	// does not close it.
+	DB *sql.DB
+	// Table receiving the entries, defaults to logs. Its columns are time,
+	// level, msg and fields, which holds the fields as a JSON object.
+	Table string
+	// CreateTable creates the table when it does not exist.
+	CreateTable bool
+	// Placeholder is the bind parameter style of the driver: "?" for SQLite
+	// and MySQL (default) or "$" for PostgreSQL.
+	Placeholder string
+	// BatchSize entries, or those collected within BatchWait, are inserted
+	// in one transaction.
+	BatchSize int
+	BatchWait time.Duration
+	// QueueSize bounds the number of entries waiting to be inserted, more
+	// entries are dropped.
+	QueueSize int
+	Backoff   Backoff
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLSink is a hook inserting entries into an SQL table, so recent logs can
+// be queried locally. Use it with WithHook, it is flushed and stopped when
+// the logger is closed.
+type SQLSink struct {
+	cfg     SQLConfig
+	insert  string
+	batcher *batcher
+}
+
+// NewSQLSink checks the table name, creates the table if requested and
+// starts the background inserter.
+func NewSQLSink(cfg SQLConfig) (*SQLSink, error) {
+	if cfg.Table == "" {
+		cfg.Table = "logs"
+	}
+	if !sqlIdentifier.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("log: invalid table name %q", cfg.Table)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BatchWait <= 0 {
+		cfg.BatchWait = time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.Backoff == (Backoff{}) {
+		cfg.Backoff = DefaultBackoff
+	}
+
+	if cfg.CreateTable {
+		_, err := cfg.DB.Exec("CREATE TABLE IF NOT EXISTS " + cfg.Table +
+			" (time TIMESTAMP NOT NULL, level VARCHAR(16) NOT NULL, msg TEXT NOT NULL, fields TEXT NOT NULL)")
+		if err != nil {
+			return nil, fmt.Errorf("log: create table %s: %w", cfg.Table, err)
+		}
+	}
+
+	params := []string{"?", "?", "?", "?"}
+	if cfg.Placeholder == "$" {
+		for i := range params {
+			params[i] = "$" + strconv.Itoa(i+1)
+		}
+	}
+	s := &SQLSink{
+		cfg:    cfg,
+		insert: "INSERT INTO " + cfg.Table + " (time, level, msg, fields) VALUES (" + strings.Join(params, ", ") + ")",
+	}
+	s.batcher = newBatcher("sql table "+cfg.Table, cfg.BatchSize, cfg.QueueSize, cfg.BatchWait, s.send)
+
+	return s, nil
+}
+
+// Fire queues the entry for the next transaction.
+func (s *SQLSink) Fire(e Entry) error {
+	s.batcher.add(e)
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full
+// or the sink was closed.
+func (s *SQLSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.batcher.dropped)
+}
+
+// Close inserts the queued entries and stops the inserter.
+func (s *SQLSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// CloseContext stops the sender like Close, giving up when ctx is done.
+func (s *SQLSink) CloseContext(ctx context.Context) error {
+	return s.batcher.closeContext(ctx)
+}
+
+// Flush inserts the entries queued so far, giving up when ctx is done.
+func (s *SQLSink) Flush(ctx context.Context) error {
+	return s.batcher.flush(ctx)
+}
+
+// fieldsJSON renders the fields as a JSON object like the other sinks.
+// Values encoding/json can't render are stored in their text form, so one
+// bad field doesn't lose the entry.
+func fieldsJSON(fields LogFields) string {
+	b, err := fields.appendJSON(nil)
+	if err == nil {
+		return string(b)
+	}
+
+	safe := make(LogFields, len(fields))
+	for k, v := range fields {
+		if _, err := (LogFields{k: v}).appendJSON(nil); err != nil {
+			v = formatValue(v)
+		}
+		safe[k] = v
+	}
+	b, _ = safe.appendJSON(nil)
+
+	return string(b)
+}
+
+func (s *SQLSink) send(entries []Entry) error {
+	fields := make([]string, len(entries))
+	for i, e := range entries {
+		fields[i] = fieldsJSON(e.Fields)
+	}
+
+	return s.cfg.Backoff.retry(func() error {
+		tx, err := s.cfg.DB.Begin()
+		if err != nil {
+			return err
+		}
+		stmt, err := tx.Prepare(s.insert)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		defer stmt.Close()
+
+		for i, e := range entries {
+			if _, err := stmt.Exec(e.Time.UTC(), e.Level.String(), e.Message, fields[i]); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDriver is a database/sql driver recording statements and their
+// arguments.
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries []string
+	commits int
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c.d, query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *recordingConn) Rollback() error           { return errors.New("unexpected rollback") }
+
+func (c *recordingConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.commits++
+	return nil
+}
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, io.EOF
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	q := s.query
+	for _, a := range args {
+		if t, ok := a.(time.Time); ok {
+			a = t.Format(time.RFC3339)
+		}
+		q += fmt.Sprintf(" %v", a)
+	}
+	s.d.queries = append(s.d.queries, q)
+	return driver.RowsAffected(1), nil
+}
+
+func TestSQLSink(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("logtest-recording", d)
+	db, err := sql.Open("logtest-recording", "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewSQLSink(SQLConfig{DB: db, Table: "logs; DROP TABLE users"})
+	assert.Error(t, err)
+
+	sink, err := NewSQLSink(SQLConfig{DB: db, CreateTable: true, Placeholder: "$", BatchWait: time.Hour})
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sink.Fire(Entry{Time: now, Level: LevelInfo, Message: "booted", Fields: LogFields{}})
+	sink.Fire(Entry{Time: now, Level: LevelError, Message: "sensor lost", Fields: LogFields{"sensor": 3}})
+	sink.Fire(Entry{Time: now, Level: LevelError, Message: "read failed", Fields: LogFields{"err": errors.New("timeout"), "gain": math.Inf(1)}})
+	sink.Close()
+
+	assert.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS logs (time TIMESTAMP NOT NULL, level VARCHAR(16) NOT NULL, msg TEXT NOT NULL, fields TEXT NOT NULL)",
+		"INSERT INTO logs (time, level, msg, fields) VALUES ($1, $2, $3, $4) 2024-05-01T12:00:00Z info booted {}",
+		`INSERT INTO logs (time, level, msg, fields) VALUES ($1, $2, $3, $4) 2024-05-01T12:00:00Z error sensor lost {"sensor":3}`,
+		`INSERT INTO logs (time, level, msg, fields) VALUES ($1, $2, $3, $4) 2024-05-01T12:00:00Z error read failed {"err":"timeout","gain":"+Inf"}`,
+	}, d.queries)
+	assert.Equal(t, 1, d.commits)
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackFrame is a frame of a Stacktrace.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Stacktrace is the value of the stacktrace field added with
+// WithStacktraceLevel, innermost frame first. Text formatters render it on
+// one line, e.g. "main.load (config.go:42); main.main (main.go:12)", and
+// JsonFormatter as an array of frame objects.
+type Stacktrace []StackFrame
+
+// String renders the frames with the base names of their files.
+func (s Stacktrace) String() string {
+	var b strings.Builder
+	for i, f := range s {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.Function)
+		b.WriteString(" (")
+		b.WriteString(filepath.Base(f.File))
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte(')')
+	}
+
+	return b.String()
+}
+
+type stacktraceConfig struct {
+	lvl   Level
+	depth int
+}
+
+// WithStacktraceLevel adds a stacktrace field with the stack of the calling
+// goroutine to entries of lvl and more severe levels, e.g.
+// WithStacktraceLevel(LevelError). Frames of this package are left out.
+func WithStacktraceLevel(lvl Level) LogOption {
+	return func(l *logger) {
+		if l.stacktrace == nil {
+			l.stacktrace = &stacktraceConfig{depth: 32}
+		}
+		l.stacktrace.lvl = lvl
+	}
+}
+
+// WithStacktraceDepth limits the frames of the stacktrace field, 32 by
+// default.
+func WithStacktraceDepth(depth int) LogOption {
+	return func(l *logger) {
+		if l.stacktrace == nil {
+			l.stacktrace = &stacktraceConfig{lvl: LevelError}
+		}
+		l.stacktrace.depth = depth
+	}
+}
+
+// addStacktrace adds the stacktrace field when the entry is severe enough.
+func (l *logger) addStacktrace(e *Entry) {
+	if l.stacktrace == nil || e.Level.Severity() > l.stacktrace.lvl.Severity() {
+		return
+	}
+
+	e.Fields = e.Fields.clone()
+	e.Fields["stacktrace"] = captureStack(l.stacktrace.depth)
+}
+
+// captureStack returns up to depth frames outside of this package.
+func captureStack(depth int) Stacktrace {
+	pcs := make([]uintptr, depth+16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var st Stacktrace
+	for len(st) < depth {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != pkgDir || strings.HasSuffix(frame.File, "_test.go") {
+			st = append(st, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+
+	return st
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStacktraceLevel(t *testing.T) {
+	var text, js bytes.Buffer
+	l := New(&text, WithoutStdout(), WithFlags(0), WithStacktraceLevel(LevelWarning), WithStacktraceDepth(2),
+		WithSecondaryOutput(&js, JsonFormatter{}))
+	l.Info("started")
+	l.Error("failed")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.NotContains(t, lines[0], "stacktrace")
+		assert.Contains(t, lines[1], `stacktrace="github.com/bialas1993/log.TestStacktraceLevel (stacktrace_test.go:`)
+	}
+
+	type entry struct {
+		Stacktrace []StackFrame `json:"stacktrace"`
+	}
+	var entries []entry
+	for _, line := range strings.Split(strings.TrimSpace(js.String()), "\n") {
+		var e entry
+		assert.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	if assert.Len(t, entries, 2) && assert.Len(t, entries[1].Stacktrace, 2) {
+		assert.Empty(t, entries[0].Stacktrace)
+		assert.Equal(t, "github.com/bialas1993/log.TestStacktraceLevel", entries[1].Stacktrace[0].Function)
+		assert.Equal(t, "testing.tRunner", entries[1].Stacktrace[1].Function)
+	}
+}
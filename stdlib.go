@@ -0,0 +1,120 @@
+package log
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Print logs with the Info severity, like Info, for code written against
+// the standard library logger.
+// Arguments are handled in the manner of fmt.Print.
+func (l *logger) Print(v ...interface{}) {
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.output(LevelInfo, 0, fmt.Sprint(v...))
+}
+
+// Printf logs with the Info severity.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *logger) Printf(format string, v ...interface{}) {
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.output(LevelInfo, 0, fmt.Sprintf(format, v...))
+}
+
+// Println logs with the Info severity.
+// Arguments are handled in the manner of fmt.Println, without the newline.
+func (l *logger) Println(v ...interface{}) {
+	if !l.wants(LevelInfo) {
+		l.clear()
+		return
+	}
+	l.output(LevelInfo, 0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+// Write logs p as an Info entry without its trailing newline, so the logger
+// is an io.Writer for libraries writing whole lines, e.g. the driver logs
+// of database/sql. Multi-line writes become a single entry. It always
+// reports p as written.
+func (l *logger) Write(p []byte) (int, error) {
+	l.writeLine(LevelInfo, 0, p)
+	return len(p), nil
+}
+
+// writeLine logs p without its trailing newline, the caller is reported
+// depth frames above the caller of the method calling writeLine.
+func (l *logger) writeLine(s Level, depth int, p []byte) {
+	if !l.wants(s) {
+		l.clear()
+		return
+	}
+	l.output(s, depth+1, trimNewline(p))
+}
+
+// StdLog returns a standard library logger writing to l with the level, for
+// APIs taking a *log.Logger, e.g.
+//
+//	srv := &http.Server{ErrorLog: log.StdLog(logger, log.LevelError)}
+//
+// Every line written by the returned logger becomes an entry, its prefix and
+// flags are empty as l adds its own.
+func StdLog(l Logger, lvl Level) *log.Logger {
+	return log.New(levelWriter{l: l, lvl: lvl}, "", 0)
+}
+
+// levelWriter logs the lines written to it with its level.
+type levelWriter struct {
+	l   Logger
+	lvl Level
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	if l, ok := w.l.(*logger); ok {
+		// report the caller of the standard logger, skipping its Print
+		// method and the output method calling Write
+		l.writeLine(w.lvl, 2, p)
+		return len(p), nil
+	}
+	w.l.Log(w.lvl, trimNewline(p))
+
+	return len(p), nil
+}
+
+func trimNewline(p []byte) string {
+	return strings.TrimSuffix(strings.TrimSuffix(string(p), "\n"), "\r")
+}
+
+// Print uses the default logger and logs with the Info severity.
+// Arguments are handled in the manner of fmt.Print.
+func Print(v ...interface{}) {
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelInfo, 0, fmt.Sprint(v...))
+}
+
+// Printf uses the default logger and logs with the Info severity.
+// Arguments are handled in the manner of fmt.Printf.
+func Printf(format string, v ...interface{}) {
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelInfo, 0, fmt.Sprintf(format, v...))
+}
+
+// Println uses the default logger and logs with the Info severity.
+// Arguments are handled in the manner of fmt.Println, without the newline.
+func Println(v ...interface{}) {
+	if !defaultLogger.wants(LevelInfo) {
+		defaultLogger.clear()
+		return
+	}
+	defaultLogger.output(LevelInfo, 0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
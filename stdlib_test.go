@@ -0,0 +1,43 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdlibCompat(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	l.Print("a", 1)
+	l.Printf("b %d", 2)
+	l.Println("c", 3)
+	fmt.Fprintf(l, "d %d\n", 4)
+	StdLog(l, LevelError).Printf("e %d", 5)
+
+	assert.Equal(t, "INFO : a1\n"+
+		"INFO : b 2\n"+
+		"INFO : c 3\n"+
+		"INFO : d 4\n"+
+		"ERROR: e 5\n", out.String())
+}
+
+func TestStdlibCaller(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Lshortfile))
+
+	l.Write([]byte("written\n"))
+	StdLog(l, LevelError).Print("printed")
+	StdLog(l.Named("http"), LevelWarning).Output(1, "output")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if assert.Len(t, lines, 3) {
+		for _, line := range lines {
+			assert.Contains(t, line, "stdlib_test.go:")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package log
+
+// subscriberBuffer is the number of entries a subscriber may fall behind
+// before entries are dropped for it.
+const subscriberBuffer = 256
+
+type subscriber struct {
+	ch     chan Entry
+	filter func(Entry) bool
+}
+
+// Subscribe returns a channel receiving the entries passing filter, or all
+// entries logged at the logger level when filter is nil. Entries are never
+// blocked on a slow subscriber, they are dropped once it is 256 entries
+// behind. cancel stops the subscription and closes the channel, which is
+// also closed by Close.
+func (l *logger) Subscribe(filter func(Entry) bool) (<-chan Entry, func()) {
+	l = l.top()
+	logLock.Lock()
+	defer logLock.Unlock()
+
+	s := &subscriber{ch: make(chan Entry, subscriberBuffer), filter: filter}
+	l.subscribers = append(l.subscribers, s)
+
+	cancel := func() {
+		logLock.Lock()
+		defer logLock.Unlock()
+		l.unsubscribe(s)
+	}
+
+	return s.ch, cancel
+}
+
+// unsubscribe closes the subscriber channel, it is a no-op when it was
+// already removed.
+func (l *logger) unsubscribe(s *subscriber) {
+	for i, sub := range l.subscribers {
+		if sub == s {
+			l.subscribers = append(l.subscribers[:i:i], l.subscribers[i+1:]...)
+			close(s.ch)
+			return
+		}
+	}
+}
+
+func (l *logger) publish(e Entry) {
+	l = l.top()
+	if len(l.subscribers) == 0 {
+		return
+	}
+
+	e.Fields = e.Fields.clone()
+	for _, s := range l.subscribers {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe uses the default logger, see Logger.Subscribe.
+func Subscribe(filter func(Entry) bool) (<-chan Entry, func()) {
+	return defaultLogger.Subscribe(filter)
+}
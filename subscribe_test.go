@@ -0,0 +1,34 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	l := New(&bytes.Buffer{})
+	errs, cancel := l.Subscribe(func(e Entry) bool { return e.Level <= LevelError })
+	all, _ := l.Subscribe(nil)
+
+	l.Info("started")
+	l.With(LogFields{"code": 500}).Error("failed")
+
+	e := <-errs
+	assert.Equal(t, "failed", e.Message)
+	assert.Equal(t, LogFields{"code": 500}, e.Fields)
+	assert.Equal(t, "started", (<-all).Message)
+	assert.Equal(t, "failed", (<-all).Message)
+
+	cancel()
+	cancel()
+	l.Error("after cancel")
+	_, ok := <-errs
+	assert.False(t, ok)
+
+	l.Close()
+	assert.Equal(t, "after cancel", (<-all).Message)
+	_, ok = <-all
+	assert.False(t, ok)
+}
@@ -0,0 +1,88 @@
+package log
+
+// The *w methods take the fields of an entry as alternating keys and values
+// after the message, e.g.
+//
+//	logger.Infow("request served", "status", 200, "path", r.URL.Path)
+//
+// The fields are rendered in the given order, see Ordered. Keys which are
+// not strings are formatted with fmt.Sprint and a trailing value without key
+// is logged under !BADKEY. Unlike With, no fields are created for entries
+// of disabled levels.
+
+// Debugw logs with the Debug severity and the keys and values as fields.
+func (l *logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelDebug, msg, keysAndValues)
+}
+
+// Infow logs with the Info severity and the keys and values as fields.
+func (l *logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelInfo, msg, keysAndValues)
+}
+
+// Warnw logs with the Warning severity and the keys and values as fields.
+func (l *logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelWarning, msg, keysAndValues)
+}
+
+// Errorw logs with the Error severity and the keys and values as fields.
+func (l *logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelError, msg, keysAndValues)
+}
+
+// Fatalw logs with the Fatal severity and the keys and values as fields,
+// and ends with os.Exit using the code set with WithFatalExitCode.
+func (l *logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.outputCall(nil, l.keysAndValues(keysAndValues), LevelFatal, 0, msg)
+	l.Close()
+	osExit(l.exitCode)
+}
+
+func (l *logger) logw(lvl Level, msg string, keysAndValues []interface{}) {
+	if !l.wants(lvl) {
+		l.clear()
+		return
+	}
+	// skip logw as well
+	l.outputCall(nil, l.keysAndValues(keysAndValues), lvl, 1, msg)
+}
+
+// keysAndValues returns the fields of the keys and values.
+func (l *logger) keysAndValues(keysAndValues []interface{}) LogFields {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	return l.grouped(Ordered(keysAndValues...))
+}
+
+// Debugw uses the default logger, logs with the Debug severity and the keys
+// and values as fields.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.logw(LevelDebug, msg, keysAndValues)
+}
+
+// Infow uses the default logger, logs with the Info severity and the keys
+// and values as fields.
+func Infow(msg string, keysAndValues ...interface{}) {
+	defaultLogger.logw(LevelInfo, msg, keysAndValues)
+}
+
+// Warnw uses the default logger, logs with the Warning severity and the
+// keys and values as fields.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.logw(LevelWarning, msg, keysAndValues)
+}
+
+// Errorw uses the default logger, logs with the Error severity and the keys
+// and values as fields.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.logw(LevelError, msg, keysAndValues)
+}
+
+// Fatalw uses the default logger, logs with the Fatal severity and the keys
+// and values as fields, and ends with os.Exit using the code set with
+// WithFatalExitCode.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Fatalw(msg, keysAndValues...)
+}
@@ -0,0 +1,23 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysAndValues(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(Lshortfile))
+
+	l.Infow("served", "status", 200, "path", "/users")
+	l.Warnw("odd", "user", "ann", "dangling")
+	l.Debugw("hidden", "status", 200)
+	l.Info("plain")
+
+	assert.Regexp(t, `^INFO : sugar_test.go:\d+: status=200 path=/users served\n`+
+		`WARN : sugar_test.go:\d+: user=ann !BADKEY=dangling odd\n`+
+		`INFO : sugar_test.go:\d+: plain\n$`, out.String())
+
+}
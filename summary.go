@@ -0,0 +1,225 @@
+package log
+
+import (
+	"reflect"
+	"time"
+)
+
+// SummaryConfig configures a Summarizer.
+type SummaryConfig struct {
+	// Fields are the numeric fields summarized, e.g. "latency_ms".
+	Fields []string
+	// Interval of a summary, defaults to 10 seconds.
+	Interval time.Duration
+	// Threshold is the number of entries with the same level and message
+	// written individually in an interval, more are only summarized.
+	// Defaults to 100.
+	Threshold int
+}
+
+// Summarizer cuts the volume of hot paths logging numeric fields. Once the
+// entries with the same level and message exceed the threshold in an
+// interval, the remaining entries of the interval are not written but
+// summarized by a single entry with the same level and message and the
+// fields summary, count, interval and the min, max and avg of every field,
+// e.g. latency_ms.min. Entries without any of the fields are not affected.
+//
+// Summaries are written with the next entry after the interval and when the
+// logger is closed.
+type Summarizer struct {
+	cfg    SummaryConfig
+	fields map[string]bool
+	now    func() time.Time
+
+	// used with logLock held
+	windows map[summaryKey]*summaryWindow
+	swept   time.Time
+}
+
+type summaryKey struct {
+	lvl Level
+	msg string
+}
+
+type summaryWindow struct {
+	start      time.Time
+	seen       int
+	suppressed int
+	stats      map[string]*fieldStats
+}
+
+type fieldStats struct {
+	count         int
+	min, max, sum float64
+}
+
+// NewSummarizer creates a summarizer, add it to a logger with
+// WithSummarizer.
+func NewSummarizer(cfg SummaryConfig) *Summarizer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 100
+	}
+
+	s := &Summarizer{cfg: cfg, fields: make(map[string]bool, len(cfg.Fields)), now: clock, windows: map[summaryKey]*summaryWindow{}}
+	for _, f := range cfg.Fields {
+		s.fields[f] = true
+	}
+
+	return s
+}
+
+// WithSummarizer summarizes entries with the summarizer before they are
+// written. Legal holds and ring buffers still receive every entry.
+func WithSummarizer(s *Summarizer) LogOption {
+	return func(l *logger) {
+		l.summarizers = append(l.summarizers, s)
+	}
+}
+
+// observe records the entry and reports whether it is left out, due are the
+// summaries of the intervals which ended.
+func (s *Summarizer) observe(e Entry) (suppress bool, due []Entry) {
+	now := s.now()
+	if now.Sub(s.swept) >= s.cfg.Interval {
+		s.swept = now
+		for k, w := range s.windows {
+			if now.Sub(w.start) >= s.cfg.Interval {
+				if w.suppressed > 0 {
+					due = append(due, s.summary(k, w, now))
+				}
+				delete(s.windows, k)
+			}
+		}
+	}
+
+	var values map[string]float64
+	for k, v := range e.Fields {
+		if !s.fields[k] {
+			continue
+		}
+		if f, ok := number(v); ok {
+			if values == nil {
+				values = map[string]float64{}
+			}
+			values[k] = f
+		}
+	}
+	if values == nil {
+		return false, due
+	}
+
+	k := summaryKey{lvl: e.Level, msg: e.Message}
+	w, ok := s.windows[k]
+	if !ok || now.Sub(w.start) >= s.cfg.Interval {
+		if ok && w.suppressed > 0 {
+			due = append(due, s.summary(k, w, now))
+		}
+		w = &summaryWindow{start: now, stats: map[string]*fieldStats{}}
+		s.windows[k] = w
+	}
+	w.seen++
+	if w.seen <= s.cfg.Threshold {
+		return false, due
+	}
+
+	w.suppressed++
+	for f, v := range values {
+		st, ok := w.stats[f]
+		if !ok {
+			st = &fieldStats{min: v, max: v}
+			w.stats[f] = st
+		}
+		st.count++
+		st.sum += v
+		if v < st.min {
+			st.min = v
+		}
+		if v > st.max {
+			st.max = v
+		}
+	}
+
+	return true, due
+}
+
+// flush returns the summaries of all intervals and starts over.
+func (s *Summarizer) flush() []Entry {
+	now := s.now()
+	var due []Entry
+	for k, w := range s.windows {
+		if w.suppressed > 0 {
+			due = append(due, s.summary(k, w, now))
+		}
+		delete(s.windows, k)
+	}
+
+	return due
+}
+
+func (s *Summarizer) summary(k summaryKey, w *summaryWindow, now time.Time) Entry {
+	fields := LogFields{
+		"summary":  true,
+		"count":    w.suppressed,
+		"interval": s.cfg.Interval.String(),
+	}
+	for f, st := range w.stats {
+		fields[f+".min"] = st.min
+		fields[f+".max"] = st.max
+		fields[f+".avg"] = st.sum / float64(st.count)
+	}
+
+	return Entry{Time: now, Level: k.lvl, Message: k.msg, Fields: fields}
+}
+
+// number converts a numeric field value to float64.
+func number(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+
+	return 0, false
+}
+
+// summarize passes the entry to the summarizers, writes the summaries due
+// and reports whether the entry is left out. It is called with logLock held.
+func (l *logger) summarize(e Entry) bool {
+	suppress := false
+	for _, s := range l.summarizers {
+		skip, due := s.observe(e)
+		for _, d := range due {
+			l.emit(d)
+		}
+		suppress = suppress || skip
+	}
+
+	return suppress
+}
+
+// flushSummaries writes the pending summaries, with logLock held.
+func (l *logger) flushSummaries() {
+	for _, s := range l.summarizers {
+		for _, d := range s.flush() {
+			l.emit(d)
+		}
+	}
+}
+
+// emit writes an entry made by the logger itself, e.g. a summary, to the
+// outputs, hooks and subscribers, with logLock held.
+func (l *logger) emit(e Entry) {
+	for _, o := range l.outputs {
+		o.write(e.Level, 0, l.top().flags, e.Fields, e.Message)
+	}
+	l.fireHooks(e)
+	l.noticeCaps()
+	l.publish(e)
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizer(t *testing.T) {
+	now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	s := NewSummarizer(SummaryConfig{Fields: []string{"latency_ms"}, Interval: time.Minute, Threshold: 2})
+	s.now = func() time.Time { return now }
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0), WithSummarizer(s))
+	for _, ms := range []int{5, 7, 3, 9, 6} {
+		l.With(LogFields{"latency_ms": ms}).Info("request served")
+	}
+	l.Info("cache warmed")
+
+	now = now.Add(time.Minute)
+	l.With(LogFields{"latency_ms": 4}).Info("request served")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 5) {
+		assert.Contains(t, lines[0], "latency_ms=5")
+		assert.Contains(t, lines[1], "latency_ms=7")
+		assert.Contains(t, lines[2], "cache warmed")
+		for _, f := range []string{"summary=true", "count=3", "latency_ms.min=3", "latency_ms.max=9", "latency_ms.avg=6", "interval=1m0s"} {
+			assert.Contains(t, lines[3], f)
+		}
+		assert.Contains(t, lines[4], "latency_ms=4")
+	}
+}
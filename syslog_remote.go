@@ -0,0 +1,314 @@
+package log
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RemoteSyslogConfig configures a RemoteSyslogHook.
+type RemoteSyslogConfig struct {
+	// Addr of the syslog server, e.g. logs.example.com:6514.
+	Addr string
+	// TLS makes TLS the preferred transport instead of TCP.
+	TLS *tls.Config
+	// App is the APP-NAME of messages, defaults to the program name.
+	App string
+	// Formatter renders the MSG part, StdFormatter when nil.
+	Formatter Formatter
+	// DialTimeout of a single connection attempt, defaults to 5 seconds.
+	DialTimeout time.Duration
+	// ProbeInterval is how often the preferred transport is tried again
+	// while falling back to UDP, defaults to 30 seconds.
+	ProbeInterval time.Duration
+	// QueueSize bounds the number of messages waiting to be sent, more
+	// messages are dropped, defaults to 1000.
+	QueueSize int
+}
+
+// Remote syslog transports, in order of preference.
+const (
+	TransportTLS  = "tls"
+	TransportTCP  = "tcp"
+	TransportUDP  = "udp"
+	TransportDown = "down"
+)
+
+// RemoteSyslogHook sends entries to a remote syslog server as RFC 5424
+// messages. It prefers a stream transport (TLS when configured, otherwise
+// TCP, framed by octet counting as in RFC 6587) and falls back to UDP when
+// the connection fails, probing the preferred transport again
+// periodically. Messages are sent in the background, so a slow server
+// never blocks logging.
+type RemoteSyslogHook struct {
+	cfg       RemoteSyslogConfig
+	preferred string
+	host      string
+
+	// notify reports transport changes, see WithRemoteSyslog.
+	notify func(lvl Level, msg string, fields LogFields)
+
+	queue   chan []byte
+	dropped uint64
+	state   atomic.Value
+	once    sync.Once
+	done    chan struct{}
+	stopped chan struct{}
+
+	// used only by the sender goroutine
+	conn      net.Conn
+	transport string
+}
+
+// syslogSeverity maps levels to RFC 5424 severities.
+var syslogSeverity = map[Level]int{
+	LevelFatal:   2,
+	LevelPanic:   2,
+	LevelError:   3,
+	LevelWarning: 4,
+	LevelInfo:    6,
+	LevelDebug:   7,
+	LevelTrace:   7,
+}
+
+// syslogFacilityUser is the user-level facility used for all messages.
+const syslogFacilityUser = 1
+
+// NewRemoteSyslogHook creates the hook and starts its background sender,
+// which connects on the first message.
+func NewRemoteSyslogHook(cfg RemoteSyslogConfig) *RemoteSyslogHook {
+	if cfg.App == "" {
+		cfg.App = filepath.Base(os.Args[0])
+	}
+	if cfg.Formatter == nil {
+		cfg.Formatter = StdFormatter{}
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = 30 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+
+	h := &RemoteSyslogHook{
+		cfg:       cfg,
+		preferred: TransportTCP,
+		host:      "-",
+		notify:    stderrDiagnostic,
+		queue:     make(chan []byte, cfg.QueueSize),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		transport: TransportDown,
+	}
+	if cfg.TLS != nil {
+		h.preferred = TransportTLS
+	}
+	h.state.Store(TransportDown)
+	if host, err := hostname(); err == nil {
+		h.host = host
+	}
+	go h.run()
+
+	return h
+}
+
+// WithRemoteSyslog sends entries to a remote syslog server. Transport
+// changes are logged by the logger as warnings, or info when the preferred
+// transport is back.
+func WithRemoteSyslog(cfg RemoteSyslogConfig) LogOption {
+	return func(l *logger) {
+		h := NewRemoteSyslogHook(cfg)
+		h.notify = func(lvl Level, msg string, fields LogFields) {
+			// the sender may be waited for by Close with the lock held
+			go l.diagnostic(lvl, msg, fields)
+		}
+		WithHook(h)(l)
+	}
+}
+
+func stderrDiagnostic(lvl Level, msg string, fields LogFields) {
+	fmt.Fprintf(consoleWriter{os.Stderr}, "%s%s\n", levelTags[lvl], StdFormatter{}.Output(Ldisable, lvl.String(), fields, msg))
+}
+
+// Fire queues the entry, it is dropped when the queue is full.
+func (h *RemoteSyslogHook) Fire(e Entry) error {
+	select {
+	case h.queue <- h.message(e):
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+
+	return nil
+}
+
+// message renders e.g. "<11>1 2020-01-01T00:00:00Z host app 42 - - msg".
+func (h *RemoteSyslogHook) message(e Entry) []byte {
+	pri := syslogFacilityUser*8 + syslogSeverity[e.Level]
+	b := make([]byte, 0, 128+len(e.Message))
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(pri), 10)
+	b = append(b, ">1 "...)
+	b = e.Time.UTC().AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	b = append(b, ' ')
+	b = append(b, h.host...)
+	b = append(b, ' ')
+	b = append(b, h.cfg.App...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(pid()), 10)
+	b = append(b, " - - "...)
+
+	return append(b, h.cfg.Formatter.Output(Ldisable, e.Level.String(), e.Fields, e.Message)...)
+}
+
+// Dropped returns the number of messages dropped because the queue was
+// full or no transport was available.
+func (h *RemoteSyslogHook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Transport returns the transport in use. It is meant for health checks and
+// may lag behind the sender.
+func (h *RemoteSyslogHook) Transport() string {
+	return h.state.Load().(string)
+}
+
+// Close sends the queued messages and closes the connection.
+func (h *RemoteSyslogHook) Close() error {
+	h.once.Do(func() {
+		close(h.done)
+	})
+	<-h.stopped
+
+	return nil
+}
+
+func (h *RemoteSyslogHook) run() {
+	defer close(h.stopped)
+
+	probe := time.NewTicker(h.cfg.ProbeInterval)
+	defer probe.Stop()
+
+	for {
+		select {
+		case msg := <-h.queue:
+			h.send(msg)
+		case <-probe.C:
+			if h.transport != h.preferred && h.transport != TransportDown {
+				h.probe()
+			}
+		case <-h.done:
+			for {
+				select {
+				case msg := <-h.queue:
+					h.send(msg)
+				default:
+					if h.conn != nil {
+						h.conn.Close()
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// send writes the message, reconnecting once when the write fails.
+func (h *RemoteSyslogHook) send(msg []byte) {
+	for attempt := 0; attempt < 2; attempt++ {
+		if h.conn == nil && !h.connect() {
+			break
+		}
+		if _, err := h.conn.Write(h.frame(msg)); err == nil {
+			return
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+
+	atomic.AddUint64(&h.dropped, 1)
+}
+
+// frame adds the octet count stream transports need to split messages.
+func (h *RemoteSyslogHook) frame(msg []byte) []byte {
+	if h.transport == TransportUDP {
+		return msg
+	}
+
+	return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+}
+
+// connect dials the preferred transport and falls back to UDP.
+func (h *RemoteSyslogHook) connect() bool {
+	conn, err := h.dial(h.preferred)
+	if err == nil {
+		h.setTransport(h.preferred, conn, nil)
+		return true
+	}
+
+	udp, udpErr := h.dial(TransportUDP)
+	if udpErr == nil {
+		h.setTransport(TransportUDP, udp, err)
+		return true
+	}
+
+	h.setTransport(TransportDown, nil, udpErr)
+	return false
+}
+
+// probe switches back to the preferred transport when it is reachable.
+func (h *RemoteSyslogHook) probe() {
+	conn, err := h.dial(h.preferred)
+	if err != nil {
+		return
+	}
+	if h.conn != nil {
+		h.conn.Close()
+	}
+	h.setTransport(h.preferred, conn, nil)
+}
+
+func (h *RemoteSyslogHook) dial(transport string) (net.Conn, error) {
+	switch transport {
+	case TransportTLS:
+		return tls.DialWithDialer(&net.Dialer{Timeout: h.cfg.DialTimeout}, "tcp", h.cfg.Addr, h.cfg.TLS)
+	case TransportTCP:
+		return net.DialTimeout("tcp", h.cfg.Addr, h.cfg.DialTimeout)
+	}
+
+	// UDP dials succeed without a server, a missing one shows up only as
+	// failing writes.
+	return net.DialTimeout("udp", h.cfg.Addr, h.cfg.DialTimeout)
+}
+
+func (h *RemoteSyslogHook) setTransport(transport string, conn net.Conn, cause error) {
+	h.conn = conn
+	if transport == h.transport {
+		return
+	}
+
+	from := h.transport
+	h.transport = transport
+	h.state.Store(transport)
+
+	fields := LogFields{"addr": h.cfg.Addr, "from": from, "to": transport}
+	if cause != nil {
+		fields["error"] = cause.Error()
+	}
+	switch {
+	case transport == h.preferred && from == TransportDown:
+		// first connection, nothing worth reporting
+	case transport == h.preferred:
+		h.notify(LevelInfo, "remote syslog transport restored", fields)
+	default:
+		h.notify(LevelWarning, "remote syslog transport degraded", fields)
+	}
+}
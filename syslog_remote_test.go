@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	h := NewRemoteSyslogHook(RemoteSyslogConfig{Addr: ln.Addr().String(), App: "api"})
+	l := New(&bytes.Buffer{}, WithHook(h))
+	l.With(LogFields{"user": "bob"}).Error("failed")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	size, err := r.ReadString(' ')
+	assert.NoError(t, err)
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	assert.NoError(t, err)
+	msg := make([]byte, n)
+	_, err = io.ReadFull(r, msg)
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(msg), "<11>1 "), string(msg))
+	assert.True(t, strings.HasSuffix(string(msg), fmt.Sprintf(" api %d - - user=bob failed", os.Getpid())), string(msg))
+	assert.Equal(t, TransportTCP, h.Transport())
+	l.Close()
+}
+
+func TestRemoteSyslogFallback(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	addr := udp.LocalAddr().String()
+
+	var out bytes.Buffer
+	l := New(&out, WithRemoteSyslog(RemoteSyslogConfig{Addr: addr, ProbeInterval: 20 * time.Millisecond}))
+	l.SetFlags(Ldisable)
+	l.Info("started")
+
+	buf := make([]byte, 1024)
+	udp.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := udp.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(buf[:n]), " - - started"), string(buf[:n]))
+
+	// the preferred transport comes back on the same port
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	assert.Eventually(t, func() bool {
+		logLock.Lock()
+		defer logLock.Unlock()
+		return strings.Contains(out.String(), "remote syslog transport restored")
+	}, 5*time.Second, 10*time.Millisecond)
+	l.Close()
+
+	assert.Contains(t, out.String(), "WARN : addr="+addr+" error=")
+	assert.Contains(t, out.String(), "from=down to=udp remote syslog transport degraded\n")
+	assert.Contains(t, out.String(), "INFO : addr="+addr+" from=udp to=tcp remote syslog transport restored\n")
+}
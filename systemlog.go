@@ -0,0 +1,37 @@
+package log
+
+import "io"
+
+// SystemLogAvailable reports whether the system log (syslog or the Windows
+// event log) can be used by this process. The returned string describes the
+// backend, or the reason why it is not available.
+func SystemLogAvailable() (bool, string) {
+	return systemLogAvailable()
+}
+
+// WithSystemLog enables the system log for any constructor. When required is
+// true and the system log can not be set up, NewFromConfig returns the
+// error. The other constructors, and NewFromConfig when required is false,
+// log the error and use the remaining outputs.
+func WithSystemLog(required bool) LogOption {
+	return func(l *logger) {
+		l.systemLog = true
+		l.sysRequired = required
+	}
+}
+
+// systemLogClosers returns the system log writers to close, writers shared
+// by several levels once.
+func systemLogClosers(sys map[Level]io.Writer) []io.Closer {
+	var closers []io.Closer
+	seen := map[io.Writer]bool{}
+	for lvl := range levelTags {
+		w := sys[lvl]
+		if c, ok := w.(io.Closer); ok && !seen[w] {
+			seen[w] = true
+			closers = append(closers, c)
+		}
+	}
+
+	return closers
+}
@@ -0,0 +1,36 @@
+package log
+
+import "time"
+
+// Timer starts timing the operation name and returns the function ending
+// it, which logs an Info entry with the name as message and the field
+// duration_ms:
+//
+//	done := logger.Timer("load_users", time.Second)
+//	defer done()
+//
+// When warnAfter is given, operations taking longer are logged with the
+// Warning severity, so slow ones stand out without a metrics stack. Call
+// the returned function once.
+func (l *logger) Timer(name string, warnAfter ...time.Duration) (done func()) {
+	start := clock()
+
+	return func() {
+		elapsed := clock().Sub(start)
+		lvl := LevelInfo
+		if len(warnAfter) > 0 && elapsed > warnAfter[0] {
+			lvl = LevelWarning
+		}
+		if !l.wants(lvl) {
+			l.clear()
+			return
+		}
+		l.outputCall(nil, l.keysAndValues([]interface{}{"duration_ms", float64(elapsed) / float64(time.Millisecond)}), lvl, 0, name)
+	}
+}
+
+// Timer starts timing an operation logged by the default logger, see
+// Logger.Timer.
+func Timer(name string, warnAfter ...time.Duration) (done func()) {
+	return defaultLogger.Timer(name, warnAfter...)
+}
@@ -0,0 +1,24 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimer(t *testing.T) {
+	defer Replay(ReplayConfig{Step: 250 * time.Millisecond})()
+
+	var out bytes.Buffer
+	l := New(&out, WithoutStdout(), WithFlags(0))
+
+	l.Timer("load_users")()
+	l.Timer("load_orders", 100*time.Millisecond)()
+	l.With(LogFields{"table": "users"}).Timer("vacuum", time.Second)()
+
+	assert.Equal(t, "INFO : duration_ms=250 load_users\n"+
+		"WARN : duration_ms=250 load_orders\n"+
+		"INFO : duration_ms=250 table=users vacuum\n", out.String())
+}
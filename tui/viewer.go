@@ -0,0 +1,333 @@
+// Package tui shows the entries of a logger in a scrollable, filterable
+// terminal pane for interactive command line tools. It is a separate module,
+// so only programs using it depend on bubbletea.
+//
+//	viewer := tui.New(tui.Config{Title: "sync"})
+//	logger := log.NewColorLogger(viewer.Options(*interactive)...)
+//	go work(logger)
+//	if err := viewer.Run(); err != nil { ... }
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bialas1993/log"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Config configures a Viewer.
+type Config struct {
+	// Title is shown in the header.
+	Title string
+	// MaxEntries kept for scrolling, older entries are discarded. Defaults
+	// to 10000.
+	MaxEntries int
+	// QueueSize bounds the entries waiting for the pane, more entries are
+	// dropped. Defaults to 1024.
+	QueueSize int
+}
+
+// Viewer is a hook showing entries in a full screen pane. Keys: arrows,
+// page up/down, home and end scroll, / edits the message filter, l cycles
+// the minimum level and q quits.
+type Viewer struct {
+	cfg     Config
+	entries chan log.Entry
+	dropped uint64
+	once    sync.Once
+	stop    chan struct{}
+}
+
+// New creates the viewer, it shows entries once Run is called.
+func New(cfg Config) *Viewer {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+
+	return &Viewer{cfg: cfg, entries: make(chan log.Entry, cfg.QueueSize), stop: make(chan struct{})}
+}
+
+// Options returns the logger options sending entries to the viewer instead
+// of the console. When enabled is false or stdout is not a terminal it
+// returns no options, so the logger writes to the console as usual and Run
+// returns immediately.
+func (v *Viewer) Options(enabled bool) []log.LogOption {
+	if !enabled || !isTerminal(os.Stdout) {
+		v.disable()
+		return nil
+	}
+
+	return []log.LogOption{log.WithoutStdout(), log.WithHook(v)}
+}
+
+func (v *Viewer) disable() {
+	v.once.Do(func() {
+		close(v.stop)
+	})
+}
+
+// Fire queues the entry for the pane, it is dropped when the queue is full.
+func (v *Viewer) Fire(e log.Entry) error {
+	select {
+	case <-v.stop:
+	case v.entries <- e:
+	default:
+		atomic.AddUint64(&v.dropped, 1)
+	}
+
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full.
+func (v *Viewer) Dropped() uint64 {
+	return atomic.LoadUint64(&v.dropped)
+}
+
+// Run shows the pane until the user quits. Entries logged afterwards are
+// discarded.
+func (v *Viewer) Run() error {
+	select {
+	case <-v.stop:
+		return nil
+	default:
+	}
+	defer v.disable()
+
+	p := tea.NewProgram(newModel(v.cfg), tea.WithAltScreen())
+	go func() {
+		for {
+			select {
+			case e := <-v.entries:
+				p.Send(entryMsg(e))
+			case <-v.stop:
+				return
+			}
+		}
+	}()
+
+	_, err := p.Run()
+
+	return err
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+type entryMsg log.Entry
+
+var levelStyles = map[log.Level]lipgloss.Style{
+	log.LevelFatal:   lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+	log.LevelPanic:   lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true),
+	log.LevelError:   lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	log.LevelWarning: lipgloss.NewStyle().Foreground(lipgloss.Color("11")),
+	log.LevelInfo:    lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+	log.LevelDebug:   lipgloss.NewStyle().Foreground(lipgloss.Color("7")),
+	log.LevelTrace:   lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+}
+
+var (
+	headerStyle = lipgloss.NewStyle().Reverse(true)
+	dimStyle    = lipgloss.NewStyle().Faint(true)
+)
+
+type model struct {
+	title    string
+	max      int
+	entries  []log.Entry
+	minLevel log.Level
+	filter   string
+	editing  bool
+	input    string
+	// offset is the number of lines scrolled up from the newest entry, the
+	// pane follows new entries at 0.
+	offset        int
+	width, height int
+}
+
+func newModel(cfg Config) *model {
+	return &model{title: cfg.Title, max: cfg.MaxEntries, minLevel: log.LevelTrace, width: 80, height: 24}
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case entryMsg:
+		m.entries = append(m.entries, log.Entry(msg))
+		if len(m.entries) > m.max {
+			m.entries = m.entries[len(m.entries)-m.max:]
+		}
+		if m.offset > 0 && m.matches(log.Entry(msg)) {
+			m.offset++
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if m.editing {
+			return m, m.edit(msg)
+		}
+		return m, m.key(msg.String())
+	}
+
+	return m, nil
+}
+
+func (m *model) edit(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filter, m.editing, m.offset = m.input, false, 0
+	case tea.KeyEsc:
+		m.editing = false
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyRunes, tea.KeySpace:
+		m.input += string(msg.Runes)
+	}
+
+	return nil
+}
+
+func (m *model) key(k string) tea.Cmd {
+	page := m.paneHeight()
+	switch k {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "/":
+		m.editing, m.input = true, m.filter
+	case "esc":
+		m.filter, m.offset = "", 0
+	case "l":
+		if m.minLevel == log.LevelError {
+			m.minLevel = log.LevelTrace
+		} else {
+			m.minLevel--
+		}
+		m.offset = 0
+	case "up", "k":
+		m.scroll(1)
+	case "down", "j":
+		m.scroll(-1)
+	case "pgup":
+		m.scroll(page)
+	case "pgdown":
+		m.scroll(-page)
+	case "home":
+		m.scroll(len(m.entries))
+	case "end":
+		m.offset = 0
+	}
+
+	return nil
+}
+
+func (m *model) scroll(n int) {
+	m.offset += n
+	if max := len(m.visible()) - m.paneHeight(); m.offset > max {
+		m.offset = max
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
+
+func (m *model) paneHeight() int {
+	if h := m.height - 2; h > 0 {
+		return h
+	}
+
+	return 1
+}
+
+func (m *model) matches(e log.Entry) bool {
+	return e.Level <= m.minLevel && (m.filter == "" || strings.Contains(line(e), m.filter))
+}
+
+func (m *model) visible() []log.Entry {
+	var entries []log.Entry
+	for _, e := range m.entries {
+		if m.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+
+	header := fmt.Sprintf(" %s  %s and above", m.title, m.minLevel)
+	if m.filter != "" {
+		header += fmt.Sprintf("  filter=%q", m.filter)
+	}
+	if m.offset > 0 {
+		header += fmt.Sprintf("  scrolled %d", m.offset)
+	}
+	b.WriteString(headerStyle.Render(truncate(header, m.width)) + "\n")
+
+	entries := m.visible()
+	end := len(entries) - m.offset
+	start := end - m.paneHeight()
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < end; i++ {
+		b.WriteString(render(entries[i], m.width) + "\n")
+	}
+	for i := end - start; i < m.paneHeight(); i++ {
+		b.WriteString("\n")
+	}
+
+	if m.editing {
+		b.WriteString("/" + m.input + "█")
+	} else {
+		b.WriteString(dimStyle.Render(truncate("↑/↓ scroll  / filter  esc clear  l level  q quit", m.width)))
+	}
+
+	return b.String()
+}
+
+// line renders the entry without time and colors, as matched by the
+// filter.
+func line(e log.Entry) string {
+	s := e.Message
+	for _, k := range e.Fields.Keys() {
+		s += fmt.Sprintf(" %s=%v", k, e.Fields[k])
+	}
+
+	return s
+}
+
+func render(e log.Entry, width int) string {
+	level := fmt.Sprintf("%-5.5s", strings.ToUpper(e.Level.String()))
+	prefix := e.Time.Format("15:04:05") + " "
+
+	return dimStyle.Render(prefix) + levelStyles[e.Level].Render(level) + " " + truncate(line(e), width-len(prefix)-6)
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if r := []rune(s); len(r) > width {
+		return string(r[:width-1]) + "…"
+	}
+
+	return s
+}
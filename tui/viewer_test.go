@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bialas1993/log"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func send(m *model, msgs ...tea.Msg) {
+	for _, msg := range msgs {
+		m.Update(msg)
+	}
+}
+
+func entry(lvl log.Level, msg string, fields log.LogFields) entryMsg {
+	return entryMsg{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Level: lvl, Message: msg, Fields: fields}
+}
+
+func TestModel(t *testing.T) {
+	m := newModel(Config{Title: "sync", MaxEntries: 3})
+	send(m,
+		tea.WindowSizeMsg{Width: 60, Height: 5},
+		entry(log.LevelDebug, "dropped by MaxEntries", log.LogFields{}),
+		entry(log.LevelInfo, "copying", log.LogFields{"file": "a.txt"}),
+		entry(log.LevelDebug, "checksum ok", log.LogFields{}),
+		entry(log.LevelError, "copy failed", log.LogFields{"file": "b.txt"}),
+	)
+
+	view := m.View()
+	if strings.Contains(view, "dropped by MaxEntries") {
+		t.Error("entry beyond MaxEntries is shown")
+	}
+	for _, want := range []string{"sync", "copying file=a.txt", "checksum ok", "copy failed file=b.txt"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// the filter matches message and fields
+	send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b.txt")},
+		tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.visible(); len(got) != 1 || got[0].Message != "copy failed" {
+		t.Errorf("filtered entries %v", got)
+	}
+
+	send(m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if m.minLevel != log.LevelInfo || len(m.visible()) != 2 {
+		t.Errorf("level %s shows %d entries", m.minLevel, len(m.visible()))
+	}
+
+	// scrolling stops at the oldest entry and stays put on new entries
+	send(m, tea.KeyMsg{Type: tea.KeyHome})
+	if m.offset != 0 {
+		t.Errorf("offset %d, all entries fit the pane", m.offset)
+	}
+}
+
+func TestOptionsDisabled(t *testing.T) {
+	v := New(Config{})
+	if opts := v.Options(false); opts != nil {
+		t.Errorf("got %d options", len(opts))
+	}
+	if err := v.Run(); err != nil {
+		t.Error(err)
+	}
+	v.Fire(log.Entry{})
+}
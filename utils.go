@@ -1,5 +1,39 @@
 package log
 
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// pkgDir is the directory of this package, used to skip its own frames.
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// caller returns file and line of the first frame outside of this package.
+// Formatters are called at different depths depending on the logger outputs,
+// so the depth can not be fixed as with log.Logger.
+func caller() (string, int) {
+	if file, line, ok := replayCaller(); ok {
+		return file, line
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != pkgDir || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return "???", 0
+		}
+	}
+}
+
 // Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid zero-padding.
 func itoa(i int, wid int) []byte {
 	// Assemble decimal in reverse order.
@@ -0,0 +1,63 @@
+package log
+
+import (
+	v1 "github.com/bialas1993/log"
+)
+
+// FromV1 returns a logger writing through l, so new code can use this API
+// while the outputs are still configured with the first version. The level
+// of the returned logger is LevelTrace, l filters the entries. Panic and
+// Fatal entries are written with the Error severity of l, the returned
+// logger panics or exits itself.
+func FromV1(l v1.Logger) *Logger {
+	return &Logger{core: &core{level: int32(LevelTrace), hooks: []Hook{v1Sink{l}}}}
+}
+
+// v1Sink passes entries to a logger of the first version.
+type v1Sink struct {
+	l v1.Logger
+}
+
+func (s v1Sink) Fire(e Entry) error {
+	l := s.l.With(e.Fields)
+	switch e.Level {
+	case LevelTrace:
+		l.Trace(e.Message)
+	case LevelDebug:
+		l.Debug(e.Message)
+	case LevelInfo:
+		l.Info(e.Message)
+	case LevelWarn:
+		l.Warning(e.Message)
+	default:
+		l.Error(e.Message)
+	}
+
+	return nil
+}
+
+// Hook returns a hook logging the entries it receives with l, so a logger of
+// the first version writes through l:
+//
+//	old := v1.New(nil, v1.WithoutStdout(), v1.WithLevel(v1.LevelTrace), v1.WithHook(logger.Hook()))
+func (l *Logger) Hook() Hook {
+	return v2Hook{l}
+}
+
+type v2Hook struct {
+	l *Logger
+}
+
+func (h v2Hook) Fire(e Entry) error {
+	if h.l.Enabled(e.Level) {
+		fields := h.l.fields.Add(e.Fields)
+		all := make(Fields, len(fields))
+		for k, v := range fields {
+			all[k] = v
+		}
+		e.Fields = all
+		h.l.core.write(e)
+	}
+
+	return nil
+}
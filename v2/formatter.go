@@ -0,0 +1,96 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatter renders an entry, appending it to b. The result has to end with
+// a newline.
+type Formatter interface {
+	Format(b []byte, e Entry) []byte
+}
+
+// TextFormatter renders entries as
+//
+//	2024-05-01T10:00:00.000Z INFO  logged in user=42
+type TextFormatter struct {
+	// TimeFormat defaults to RFC 3339 with milliseconds, "-" omits the time.
+	TimeFormat string
+}
+
+func (f TextFormatter) Format(b []byte, e Entry) []byte {
+	switch f.TimeFormat {
+	case "":
+		b = e.Time.AppendFormat(b, "2006-01-02T15:04:05.000Z07:00")
+		b = append(b, ' ')
+	case "-":
+	default:
+		b = e.Time.AppendFormat(b, f.TimeFormat)
+		b = append(b, ' ')
+	}
+
+	level := strings.ToUpper(e.Level.String())
+	if len(level) > 5 {
+		level = level[:4]
+	}
+	b = append(b, level...)
+	b = append(b, strings.Repeat(" ", 6-len(level))...)
+	b = append(b, e.Message...)
+
+	for _, k := range e.Fields.Keys() {
+		b = append(b, ' ')
+		b = append(b, k...)
+		b = append(b, '=')
+		v := fmt.Sprint(e.Fields[k])
+		if strings.ContainsAny(v, " \t\n\"=") || v == "" {
+			b = strconv.AppendQuote(b, v)
+		} else {
+			b = append(b, v...)
+		}
+	}
+
+	return append(b, '\n')
+}
+
+// JSONFormatter renders entries as one JSON object per line with the keys
+// time, level and msg followed by the fields in the order of Fields.Keys.
+// Fields named like the fixed keys are dropped.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(b []byte, e Entry) []byte {
+	b = append(b, `{"time":`...)
+	b = strconv.AppendQuote(b, e.Time.Format(time.RFC3339Nano))
+	b = append(b, `,"level":`...)
+	b = strconv.AppendQuote(b, e.Level.String())
+	b = append(b, `,"msg":`...)
+	b = appendJSON(b, e.Message)
+
+	for _, k := range e.Fields.Keys() {
+		if k == "time" || k == "level" || k == "msg" {
+			continue
+		}
+		b = append(b, ',')
+		b = appendJSON(b, k)
+		b = append(b, ':')
+		v := e.Fields[k]
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		b = appendJSON(b, v)
+	}
+
+	return append(b, "}\n"...)
+}
+
+func appendJSON(b []byte, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return appendJSON(b, fmt.Sprintf("!ERROR: %v", err))
+	}
+
+	return append(b, data...)
+}
@@ -0,0 +1,11 @@
+module github.com/bialas1993/log/v2
+
+go 1.21
+
+require github.com/bialas1993/log v0.0.0-20261016150051-f247064be79f
+
+require golang.org/x/sys v0.0.0-20210426230700-d19ff857e887 // indirect
+
+// builds against the repository root, the module is used from a checkout
+// until it is released with a tagged root version, see Modules in README.md
+replace github.com/bialas1993/log => ../
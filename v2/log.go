@@ -0,0 +1,284 @@
+// Package log is the second major version of the logger. Every logging
+// method takes a context.Context first, constructors and Close return
+// errors, loggers derived with With are immutable and formatters render the
+// whole Entry:
+//
+//	logger, err := log.New(log.WithLevel(log.LevelDebug), log.WithOutput(os.Stdout, log.JSONFormatter{}))
+//	if err != nil { ... }
+//	defer logger.Close()
+//	logger.With(log.Fields{"user": id}).Info(ctx, "logged in")
+//
+// Levels, fields, entries and hooks are the types of the first version, so
+// its sinks, e.g. NewHTTPSink or NewLokiSink, are used with WithHook. Code
+// can move over one call site at a time: FromV1 logs through an existing v1
+// logger and Logger.Hook lets a v1 logger write through a v2 logger.
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/bialas1993/log"
+)
+
+// Level is the severity of an entry, LevelFatal is the most severe.
+type Level = v1.Level
+
+// Levels from the most to the least severe.
+const (
+	LevelFatal = v1.LevelFatal
+	LevelPanic = v1.LevelPanic
+	LevelError = v1.LevelError
+	LevelWarn  = v1.LevelWarning
+	LevelInfo  = v1.LevelInfo
+	LevelDebug = v1.LevelDebug
+	LevelTrace = v1.LevelTrace
+
+	// LevelOff disables all output as the logger level, Fatal and Panic
+	// still exit and panic.
+	LevelOff = v1.LevelOff
+)
+
+// ParseLevel returns the level of a name as printed by Level.String.
+func ParseLevel(s string) (Level, error) {
+	return v1.ParseLevel(s)
+}
+
+// Fields are the context information of an entry.
+type Fields = v1.LogFields
+
+// Entry is a single log record.
+type Entry = v1.Entry
+
+// Hook receives every entry passing the logger level.
+type Hook = v1.Hook
+
+// ContextWithFields returns a context carrying fields added to every entry
+// logged with it, on top of those already in ctx. The context is shared with
+// the first version, its *Ctx methods add the fields as well.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	return v1.ContextWithFields(ctx, fields)
+}
+
+// FieldsFromContext returns the fields added with ContextWithFields of
+// either version.
+func FieldsFromContext(ctx context.Context) Fields {
+	return v1.FieldsFromContext(ctx)
+}
+
+// Option configures a Logger.
+type Option func(*core) error
+
+// WithLevel sets the initial level, LevelInfo by default.
+func WithLevel(lvl Level) Option {
+	return func(c *core) error {
+		if lvl > LevelTrace && lvl != LevelOff {
+			return fmt.Errorf("log: invalid level %d", lvl)
+		}
+		c.level = levelValue(lvl)
+		return nil
+	}
+}
+
+// WithOutput writes entries rendered by f to w. Without outputs and hooks
+// entries are written as text to stderr. If w is an io.Closer it is closed
+// by Logger.Close.
+func WithOutput(w io.Writer, f Formatter) Option {
+	return func(c *core) error {
+		if w == nil || f == nil {
+			return errors.New("log: output needs a writer and a formatter")
+		}
+		c.outputs = append(c.outputs, output{w: w, f: f})
+		return nil
+	}
+}
+
+// WithHook passes entries to h. If h is an io.Closer it is closed by
+// Logger.Close.
+func WithHook(h Hook) Option {
+	return func(c *core) error {
+		if h == nil {
+			return errors.New("log: nil hook")
+		}
+		c.hooks = append(c.hooks, h)
+		return nil
+	}
+}
+
+type output struct {
+	w io.Writer
+	f Formatter
+}
+
+// core is shared by a logger and the loggers derived from it.
+type core struct {
+	level int32
+
+	mu      sync.Mutex
+	outputs []output
+	hooks   []Hook
+	buf     []byte
+	closed  bool
+}
+
+// Logger logs entries with its fields. It is safe for concurrent use.
+type Logger struct {
+	core   *core
+	fields Fields
+}
+
+// New creates a logger, it fails when an option is invalid.
+func New(opts ...Option) (*Logger, error) {
+	c := &core{level: int32(LevelInfo)}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.outputs) == 0 && len(c.hooks) == 0 {
+		c.outputs = append(c.outputs, output{w: os.Stderr, f: TextFormatter{}})
+	}
+
+	return &Logger{core: c}, nil
+}
+
+// With returns a logger adding fields to its entries, l is unchanged.
+func (l *Logger) With(fields Fields) *Logger {
+	return &Logger{core: l.core, fields: l.fields.Add(fields)}
+}
+
+// SetLevel changes the level of l and of the loggers sharing its outputs.
+func (l *Logger) SetLevel(lvl Level) {
+	atomic.StoreInt32(&l.core.level, levelValue(lvl))
+}
+
+// levelValue returns the stored level, LevelOff is below every level.
+func levelValue(lvl Level) int32 {
+	if lvl == LevelOff {
+		return -1
+	}
+
+	return int32(lvl)
+}
+
+// Enabled reports whether entries of the level are logged.
+func (l *Logger) Enabled(lvl Level) bool {
+	return int32(lvl) <= atomic.LoadInt32(&l.core.level)
+}
+
+// Log writes an entry of the level with the fields of ctx, of the logger
+// and fields, later ones winning.
+func (l *Logger) Log(ctx context.Context, lvl Level, msg string, fields ...Fields) {
+	if !l.Enabled(lvl) {
+		return
+	}
+
+	merged := FieldsFromContext(ctx).Add(l.fields)
+	for _, f := range fields {
+		merged = merged.Add(f)
+	}
+	// Add may return one of its arguments, hooks get their own copy
+	all := make(Fields, len(merged))
+	for k, v := range merged {
+		all[k] = v
+	}
+	l.core.write(Entry{Time: now(), Level: lvl, Message: msg, Fields: all})
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+// Trace logs with the Trace severity, below Debug.
+func (l *Logger) Trace(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelTrace, msg, fields...)
+}
+
+// Debug logs with the Debug severity.
+func (l *Logger) Debug(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelDebug, msg, fields...)
+}
+
+// Info logs with the Info severity.
+func (l *Logger) Info(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelInfo, msg, fields...)
+}
+
+// Warn logs with the Warning severity.
+func (l *Logger) Warn(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelWarn, msg, fields...)
+}
+
+// Error logs with the Error severity.
+func (l *Logger) Error(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelError, msg, fields...)
+}
+
+// Panic logs with the Panic severity, closes the logger and panics with
+// msg.
+func (l *Logger) Panic(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelPanic, msg, fields...)
+	l.Close()
+	panic(msg)
+}
+
+// Fatal logs with the Fatal severity, closes the logger and exits with
+// status 1.
+func (l *Logger) Fatal(ctx context.Context, msg string, fields ...Fields) {
+	l.Log(ctx, LevelFatal, msg, fields...)
+	l.Close()
+	os.Exit(1)
+}
+
+// Close closes the writers and hooks which are io.Closers, entries logged
+// afterwards are dropped. It returns the errors of all closers.
+func (l *Logger) Close() error {
+	c := l.core
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	var errs []error
+	for _, o := range c.outputs {
+		if cl, ok := o.w.(io.Closer); ok && o.w != os.Stdout && o.w != os.Stderr {
+			errs = append(errs, cl.Close())
+		}
+	}
+	for _, h := range c.hooks {
+		if cl, ok := h.(io.Closer); ok {
+			errs = append(errs, cl.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *core) write(e Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	for _, o := range c.outputs {
+		c.buf = o.f.Format(c.buf[:0], e)
+		if _, err := o.w.Write(c.buf); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write log entry: %v\n", err)
+		}
+	}
+	for _, h := range c.hooks {
+		if err := h.Fire(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fire hook %T: %v\n", h, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/bialas1993/log"
+)
+
+func init() {
+	now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+}
+
+type closer struct {
+	bytes.Buffer
+	err error
+}
+
+func (c *closer) Close() error {
+	return c.err
+}
+
+func TestLogger(t *testing.T) {
+	var text, js bytes.Buffer
+	l, err := New(WithLevel(LevelDebug), WithOutput(&text, TextFormatter{}), WithOutput(&js, JSONFormatter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ContextWithFields(context.Background(), Fields{"request": "r1", "user": "ann"})
+	db := l.With(Fields{"component": "db"})
+	db.Debug(ctx, "query done", Fields{"user": "bob", "rows": 3})
+	l.Trace(ctx, "hidden")
+	l.Warn(context.Background(), "slow")
+
+	want := "2024-05-01T10:00:00.000Z DEBUG query done component=db request=r1 rows=3 user=bob\n" +
+		"2024-05-01T10:00:00.000Z WARN  slow\n"
+	if text.String() != want {
+		t.Errorf("got text\n%s", text.String())
+	}
+	if !strings.HasPrefix(js.String(), `{"time":"2024-05-01T10:00:00Z","level":"debug","msg":"query done","component":"db",`) {
+		t.Errorf("got json\n%s", js.String())
+	}
+	if !l.Enabled(LevelDebug) || l.Enabled(LevelTrace) {
+		t.Error("Enabled does not follow the level")
+	}
+
+	l.SetLevel(LevelOff)
+	l.Error(ctx, "quiet")
+	if l.Enabled(LevelFatal) || strings.Contains(text.String(), "quiet") {
+		t.Error("LevelOff does not disable output")
+	}
+}
+
+func TestNewAndClose(t *testing.T) {
+	if _, err := New(WithLevel(Level(42))); err == nil {
+		t.Error("invalid level accepted")
+	}
+	if _, err := New(WithOutput(nil, TextFormatter{})); err == nil {
+		t.Error("nil writer accepted")
+	}
+
+	w := &closer{err: errors.New("disk full")}
+	l, _ := New(WithOutput(w, TextFormatter{TimeFormat: "-"}))
+	l.Info(context.Background(), "before")
+	if err := l.Close(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Close returned %v", err)
+	}
+	l.Info(context.Background(), "after")
+	if w.String() != "INFO  before\n" {
+		t.Errorf("got %q", w.String())
+	}
+}
+
+func TestCompat(t *testing.T) {
+	var old bytes.Buffer
+	l := FromV1(v1.New(&old, v1.WithoutStdout(), v1.WithFlags(v1.Ldisable)))
+	l.With(Fields{"a": 1}).Info(context.Background(), "from v2")
+	if old.String() != "INFO : a=1 from v2\n" {
+		t.Errorf("got %q", old.String())
+	}
+
+	var out bytes.Buffer
+	nl, _ := New(WithOutput(&out, TextFormatter{TimeFormat: "-"}))
+	v1.New(nil, v1.WithoutStdout(), v1.WithHook(nl.Hook())).With(v1.LogFields{"b": 2}).Warning("from v1")
+	if out.String() != "WARN  from v1 b=2\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestContextFieldsShared(t *testing.T) {
+	var old bytes.Buffer
+	l1 := v1.New(&old, v1.WithoutStdout(), v1.WithFlags(v1.Ldisable))
+	l1.InfoCtx(ContextWithFields(context.Background(), Fields{"a": 1}), "from v2 context")
+	if old.String() != "INFO : a=1 from v2 context\n" {
+		t.Errorf("got %q", old.String())
+	}
+
+	var out bytes.Buffer
+	l2, _ := New(WithOutput(&out, TextFormatter{TimeFormat: "-"}))
+	l2.Info(v1.ContextWithFields(context.Background(), v1.LogFields{"b": 2}), "from v1 context")
+	if out.String() != "INFO  from v1 context b=2\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+var verbosity int32
+
+// Verbose is returned by V, it is true when the verbosity is high enough:
+//
+//	if log.V(2) {
+//		log.V(2).Infof("cache: %v", dump())
+//	}
+type Verbose bool
+
+// V reports whether the verbosity set with SetVerbosity is at least n, in
+// the manner of glog. The Info methods of the result log with the default
+// logger only then.
+func V(n int) Verbose {
+	return Verbose(int(atomic.LoadInt32(&verbosity)) >= n)
+}
+
+// SetVerbosity sets the verbosity checked by V, 0 by default.
+func SetVerbosity(n int) {
+	atomic.StoreInt32(&verbosity, int32(n))
+}
+
+// Verbosity returns the verbosity checked by V.
+func Verbosity() int {
+	return int(atomic.LoadInt32(&verbosity))
+}
+
+// Info logs with the Info severity when v is true.
+// Arguments are handled in the manner of fmt.Print.
+func (v Verbose) Info(args ...interface{}) {
+	if v {
+		defaultLogger.output(LevelInfo, 0, fmt.Sprint(args...))
+	}
+}
+
+// Infof logs with the Info severity when v is true.
+// Arguments are handled in the manner of fmt.Printf.
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v {
+		defaultLogger.output(LevelInfo, 0, fmt.Sprintf(format, args...))
+	}
+}
+
+type verbosityFlag struct{}
+
+func (verbosityFlag) String() string {
+	return strconv.Itoa(Verbosity())
+}
+
+func (verbosityFlag) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	SetVerbosity(n)
+
+	return nil
+}
+
+// RegisterVerbosityFlag defines the -v flag of glog on fs, setting the
+// verbosity, e.g. RegisterVerbosityFlag(flag.CommandLine).
+func RegisterVerbosityFlag(fs *flag.FlagSet) {
+	fs.Var(verbosityFlag{}, "v", "log level for V logs")
+}
@@ -0,0 +1,30 @@
+package log
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerbose(t *testing.T) {
+	initialize()
+	defer initialize()
+	defer SetVerbosity(0)
+	var out bytes.Buffer
+	New(&out, WithoutStdout(), WithFlags(Ldisable))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterVerbosityFlag(fs)
+	assert.NoError(t, fs.Parse([]string{"-v=2"}))
+	assert.Equal(t, 2, Verbosity())
+
+	if V(2) {
+		V(2).Infof("level %d", 2)
+	}
+	V(3).Info("hidden")
+	assert.False(t, bool(V(3)))
+
+	assert.Equal(t, "INFO : level 2\n", out.String())
+}
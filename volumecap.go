@@ -0,0 +1,161 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// volumeCap counts the bytes sent to a sink during the current day.
+type volumeCap struct {
+	sink  interface{}
+	limit int64
+	now   func() time.Time
+
+	mu       sync.Mutex
+	day      int
+	bytes    int64
+	exceeded bool
+	// noticed is set once the notice about the exceeded cap was logged,
+	// noticing while it is written, so the capped sink receives it.
+	noticed  bool
+	noticing bool
+}
+
+// allow counts n bytes of an entry of the level and reports whether the
+// sink receives them. Once the cap is exceeded only errors and more severe
+// entries pass, until the next day.
+func (c *volumeCap) allow(lvl Level, n int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if day := now.Year()*1000 + now.YearDay(); day != c.day {
+		c.day, c.bytes, c.exceeded, c.noticed = day, 0, false, false
+	}
+	if c.exceeded && lvl.Severity() > LevelError && !c.noticing {
+		return false
+	}
+
+	c.bytes += int64(n)
+	if c.bytes > c.limit {
+		c.exceeded = true
+	}
+
+	return true
+}
+
+// capWriter counts the writes of the level to the capped writer.
+type capWriter struct {
+	w   io.Writer
+	cap *volumeCap
+	lvl Level
+}
+
+func (w *capWriter) Write(p []byte) (int, error) {
+	if !w.cap.allow(w.lvl, len(p)) {
+		return len(p), nil
+	}
+
+	return w.w.Write(p)
+}
+
+// sinkOf returns the writer wrapped by the logger, e.g. to name it.
+func sinkOf(w io.Writer) io.Writer {
+	if c, ok := w.(*capWriter); ok {
+		return c.w
+	}
+
+	return w
+}
+
+// WithDailyVolumeCap limits the bytes sent to a sink per day, protecting
+// from surprise ingestion bills of hosted log platforms. sink is an output
+// writer, see WithLevelForOutput, or a comparable Hook, e.g. a pointer,
+// whose entries are counted in their JSON size. Once the cap is exceeded the sink only receives errors
+// and more severe entries until midnight, and a warning with the fields
+// sink and cap is logged to all outputs.
+func WithDailyVolumeCap(sink interface{}, bytes int64) LogOption {
+	return func(l *logger) {
+		switch sink.(type) {
+		case io.Writer, Hook:
+		default:
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: volume cap: %T is neither an io.Writer nor a Hook", sink))
+			return
+		}
+		if !reflect.TypeOf(sink).Comparable() {
+			l.setupErrs = append(l.setupErrs, fmt.Errorf("log: volume cap: %T can not be told apart from other sinks, pass a pointer", sink))
+			return
+		}
+		l.caps = append(l.caps, &volumeCap{sink: sink, limit: bytes, now: clock})
+	}
+}
+
+// capFor returns the cap of the sink or nil.
+func (l *logger) capFor(sink interface{}) *volumeCap {
+	// comparing interfaces holding the same uncomparable type panics
+	if sink == nil || len(l.caps) == 0 || !reflect.TypeOf(sink).Comparable() {
+		return nil
+	}
+
+	for _, c := range l.caps {
+		if c.sink == sink {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// capped returns w counting writes of the level when the sink key is
+// capped, or w.
+func (l *logger) capped(key, w io.Writer, lvl Level) io.Writer {
+	if c := l.capFor(key); c != nil {
+		return &capWriter{w: w, cap: c, lvl: lvl}
+	}
+
+	return w
+}
+
+// hookAllowed counts the entry for a capped hook and reports whether the
+// hook receives it.
+func (l *logger) hookAllowed(h Hook, e Entry) bool {
+	c := l.capFor(h)
+	if c == nil {
+		return true
+	}
+
+	b, _ := appendEntryJSON(nil, e)
+	return c.allow(e.Level, len(b)+1)
+}
+
+// noticeCaps logs a warning for every cap exceeded since the last call. It
+// is called with logLock held after an entry was written.
+func (l *logger) noticeCaps() {
+	for _, c := range l.caps {
+		c.mu.Lock()
+		notice := c.exceeded && !c.noticed
+		c.noticed = c.noticed || notice
+		c.noticing = notice
+		c.mu.Unlock()
+		if !notice {
+			continue
+		}
+
+		name := fmt.Sprintf("%T", c.sink)
+		if w, ok := c.sink.(io.Writer); ok {
+			name = writerName(w)
+		}
+		msg := "log sink reached its daily volume cap, only errors are sent until midnight"
+		fields := LogFields{"sink": name, "cap": c.limit}
+		for _, o := range l.outputs {
+			o.write(LevelWarning, 0, l.top().flags, fields, msg)
+		}
+
+		c.mu.Lock()
+		c.noticing = false
+		c.mu.Unlock()
+	}
+}
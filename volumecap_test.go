@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyVolumeCap(t *testing.T) {
+	var hosted, local bytes.Buffer
+	hook := &closingHook{}
+	l := New(&local, WithoutStdout(), WithFlags(Ldisable), WithOutput(&hosted),
+		WithDailyVolumeCap(&hosted, 20), WithHook(hook), WithDailyVolumeCap(hook, 1))
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	for _, c := range l.(*logger).caps {
+		c.now = func() time.Time { return now }
+	}
+
+	l.Info("first entry")
+	l.Info("second entry")
+	l.Error("failed")
+	l.Info("dropped")
+	now = now.Add(14 * time.Hour)
+	l.Info("next day")
+
+	// the notice about the hook exceeds the cap of hosted
+	assert.Equal(t, "INFO : first entry\n"+
+		"WARN : cap=1 sink=*log.closingHook log sink reached its daily volume cap, only errors are sent until midnight\n"+
+		"WARN : cap=20 sink=*bytes.Buffer log sink reached its daily volume cap, only errors are sent until midnight\n"+
+		"ERROR: failed\nINFO : next day\n", hosted.String()[:strings.LastIndex(hosted.String(), "WARN")])
+	assert.Contains(t, local.String(), "ERROR: failed\nINFO : dropped\nINFO : next day\n")
+	var messages []string
+	for _, e := range hook.entries {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"first entry", "failed", "next day"}, messages)
+
+	var out bytes.Buffer
+	New(&out, WithoutStdout(), WithFlags(Ldisable), WithDailyVolumeCap(HookFunc(nil), 1))
+	assert.Contains(t, out.String(), "pass a pointer")
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WatchdogConfig configures WatchGoroutines.
+type WatchdogConfig struct {
+	// Threshold is the goroutine count above which a warning is logged.
+	Threshold int
+	// Interval between checks, defaults to one minute.
+	Interval time.Duration
+	// TopStacks is the number of stack buckets reported, defaults to 5.
+	TopStacks int
+}
+
+// GoroutineBucket groups goroutines with an identical stack.
+type GoroutineBucket struct {
+	Count int      `json:"count"`
+	Stack []string `json:"stack"`
+}
+
+func (b GoroutineBucket) String() string {
+	top := "?"
+	if len(b.Stack) > 0 {
+		top = b.Stack[0]
+	}
+
+	return fmt.Sprintf("%dx %s", b.Count, top)
+}
+
+// WatchGoroutines periodically checks the number of goroutines and logs a
+// warning with the count and the largest stack buckets when it exceeds the
+// threshold. Call the returned function to stop watching.
+func WatchGoroutines(l Logger, cfg WatchdogConfig) (stop func()) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.TopStacks <= 0 {
+		cfg.TopStacks = 5
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(cfg.Interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				checkGoroutines(l, cfg)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+func checkGoroutines(l Logger, cfg WatchdogConfig) {
+	n := runtime.NumGoroutine()
+	if n <= cfg.Threshold {
+		return
+	}
+
+	buckets := goroutineBuckets()
+	if len(buckets) > cfg.TopStacks {
+		buckets = buckets[:cfg.TopStacks]
+	}
+
+	l.With(LogFields{
+		"goroutines": n,
+		"threshold":  cfg.Threshold,
+		"top_stacks": buckets,
+	}).Warningf("goroutine count %d exceeds threshold %d", n, cfg.Threshold)
+}
+
+// goroutineBuckets groups all goroutines by stack, largest buckets first.
+func goroutineBuckets() []GoroutineBucket {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	index := map[string]int{}
+	var buckets []GoroutineBucket
+	for _, g := range bytes.Split(buf, []byte("\n\n")) {
+		lines := strings.Split(strings.TrimSpace(string(g)), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+
+		// Skip the "goroutine N [state]:" header and file lines, keep
+		// function names so equal stacks match.
+		var stack []string
+		for i := 1; i < len(lines); i += 2 {
+			stack = append(stack, stackFunc(lines[i]))
+		}
+
+		key := strings.Join(stack, "\n")
+		if i, ok := index[key]; ok {
+			buckets[i].Count++
+			continue
+		}
+		index[key] = len(buckets)
+		buckets = append(buckets, GoroutineBucket{Count: 1, Stack: stack})
+	}
+
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].Count > buckets[j].Count
+	})
+
+	return buckets
+}
+
+// stackFunc returns the function of a line of a goroutine stack without
+// its arguments, and the creator of a "created by" line without the parent
+// goroutine, so the same workers of different parents share a bucket.
+func stackFunc(line string) string {
+	if strings.HasPrefix(line, "created by ") {
+		if i := strings.Index(line, " in goroutine "); i > 0 {
+			return line[:i]
+		}
+		return line
+	}
+	if strings.HasSuffix(line, ")") {
+		if p := strings.LastIndexByte(line, '('); p > 0 {
+			return line[:p]
+		}
+	}
+
+	return line
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchGoroutines(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	for i := 0; i < 10; i++ {
+		go func() { <-block }()
+	}
+
+	var mu sync.Mutex
+	var entries []Entry
+	l := New(&bytes.Buffer{}, WithHook(HookFunc(func(e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, e)
+		return nil
+	})))
+
+	stop := WatchGoroutines(l, WatchdogConfig{Threshold: 5, Interval: time.Millisecond, TopStacks: 1})
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(entries) > 0
+	}, time.Second, time.Millisecond)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	e := entries[0]
+	assert.Equal(t, LevelWarning, e.Level)
+	assert.GreaterOrEqual(t, e.Fields["goroutines"], 10)
+	buckets := e.Fields["top_stacks"].([]GoroutineBucket)
+	if assert.Len(t, buckets, 1) {
+		assert.Equal(t, 10, buckets[0].Count)
+		assert.Contains(t, buckets[0].String(), "10x github.com/bialas1993/log.TestWatchGoroutines.func")
+	}
+}
+
+func TestGoroutineBucketsParents(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	worker := func() { <-block }
+	var started sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		started.Add(1)
+		go func() {
+			defer started.Done()
+			for j := 0; j < 3; j++ {
+				go worker()
+			}
+		}()
+	}
+	started.Wait()
+
+	for _, b := range goroutineBuckets() {
+		if strings.Contains(b.String(), "TestGoroutineBucketsParents.func1") {
+			assert.Equal(t, 6, b.Count)
+			assert.NotContains(t, strings.Join(b.Stack, "\n"), "in goroutine")
+			return
+		}
+	}
+	t.Fatal("no bucket of the workers")
+}